@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/th1enq/server_management_system/internal/agent"
+	"go.uber.org/zap"
+)
+
+var (
+	configPath = flag.String("config", "/etc/sms-agent/agent.yaml", "path to the agent configuration file")
+	foreground = flag.Bool("foreground", false, "log to stderr for systemd or a terminal instead of the log file")
+	once       = flag.Bool("once", false, "collect and send a single sample, then exit")
+)
+
+func main() {
+	flag.Parse()
+
+	config, err := agent.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	logger, err := agent.NewLogger(config.Log, *foreground)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	a, err := agent.New(config, logger)
+	if err != nil {
+		logger.Fatal("Failed to create agent", zap.Error(err))
+	}
+
+	if *once {
+		err = a.RunOnce(ctx)
+	} else {
+		err = a.Run(ctx)
+	}
+	if err != nil {
+		logger.Fatal("Agent failed", zap.Error(err))
+	}
+}
@@ -9,6 +9,7 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
 	"github.com/th1enq/server_management_system/internal/configs"
+	_ "github.com/th1enq/server_management_system/migrations"
 )
 
 const (
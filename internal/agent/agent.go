@@ -0,0 +1,363 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxBatchSize matches the limit of the batch endpoint
+	maxBatchSize = 1000
+)
+
+type Agent struct {
+	config      Config
+	client      *Client
+	collector   Collector
+	spool       *Spool
+	logger      *zap.Logger
+	credentials *Credentials
+
+	// remote is the config pushed by the server, nil until the first heartbeat
+	remote *dto.AgentConfig
+
+	failures    int
+	nextAttempt time.Time
+}
+
+func New(config Config, logger *zap.Logger) (*Agent, error) {
+	collector, err := NewCollector(config)
+	if err != nil {
+		return nil, err
+	}
+	spool, err := NewSpool(config.SpoolFile, config.SpoolMaxSamples)
+	if err != nil {
+		return nil, err
+	}
+	return &Agent{
+		config:    config,
+		client:    NewClient(config.ServerURL, config.RequestTimeout),
+		collector: collector,
+		spool:     spool,
+		logger:    logger,
+	}, nil
+}
+
+// Run registers the server if needed, then collects a sample every interval
+// until ctx is cancelled. Samples are spooled on disk and flushed in batches,
+// with a jittered exponential backoff while the API is unreachable.
+func (a *Agent) Run(ctx context.Context) error {
+	if err := a.ensureRegistered(ctx); err != nil {
+		return err
+	}
+
+	interval := a.interval()
+	a.logger.Info("Agent started",
+		zap.String("server_id", a.config.ServerID),
+		zap.Duration("interval", interval),
+		zap.Int("spooled_samples", a.spool.Len()),
+	)
+
+	// A random first delay spreads agents restarted at the same time
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(interval))))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			a.logger.Info("Agent stopped", zap.Int("spooled_samples", a.spool.Len()))
+			return nil
+		case <-timer.C:
+		}
+		a.tick(ctx)
+		if next := a.interval(); next != interval {
+			a.logger.Info("Reporting interval changed",
+				zap.Duration("from", interval),
+				zap.Duration("to", next),
+			)
+			interval = next
+		}
+		timer.Reset(interval)
+	}
+}
+
+// RunOnce collects a single sample and flushes the spool
+func (a *Agent) RunOnce(ctx context.Context) error {
+	if err := a.ensureRegistered(ctx); err != nil {
+		return err
+	}
+	if _, err := a.collect(); err != nil {
+		return err
+	}
+	return a.flush(ctx)
+}
+
+func (a *Agent) tick(ctx context.Context) {
+	urgent, err := a.collect()
+	if err != nil {
+		a.logger.Error("Failed to collect metrics", zap.Error(err))
+	}
+
+	// A threshold breach is sent right away, even while backing off
+	now := time.Now()
+	if now.Before(a.nextAttempt) && !urgent {
+		return
+	}
+	if err := a.flush(ctx); err != nil {
+		a.failures++
+		backoff := a.backoff()
+		a.nextAttempt = now.Add(backoff)
+		a.logger.Warn("Failed to send metrics, retrying later",
+			zap.Error(err),
+			zap.Int("failures", a.failures),
+			zap.Duration("retry_in", backoff),
+			zap.Int("spooled_samples", a.spool.Len()),
+		)
+		return
+	}
+	a.failures = 0
+	a.nextAttempt = time.Time{}
+}
+
+// collect spools a sample and reports whether it crosses a threshold
+func (a *Agent) collect() (bool, error) {
+	usage, err := a.collector.Collect(a.collectors())
+	if err != nil {
+		return false, err
+	}
+	sample := dto.MetricsRequest{
+		SchemaVersion: dto.MetricsSchemaV2,
+		ServerID:      a.config.ServerID,
+		CPU:           usage.CPU,
+		RAM:           usage.RAM,
+		Disk:          usage.Disk,
+		Timestamp:     time.Now().UTC(),
+		Load:          usage.Load,
+		CPUCores:      usage.CPUCores,
+		Mounts:        usage.Mounts,
+		Network:       usage.Network,
+		Processes:     usage.Processes,
+		UptimeSeconds: usage.UptimeSeconds,
+	}
+	if err := a.spool.Append(sample); err != nil {
+		return false, fmt.Errorf("failed to spool sample: %w", err)
+	}
+	return a.checkThresholds(usage), nil
+}
+
+func (a *Agent) checkThresholds(usage Usage) bool {
+	if a.remote == nil {
+		return false
+	}
+	thresholds := a.remote.Thresholds
+	breached := false
+	for _, check := range []struct {
+		resource  string
+		value     int
+		threshold int
+	}{
+		{entity.CollectorCPU, usage.CPU, thresholds.CPU},
+		{entity.CollectorRAM, usage.RAM, thresholds.RAM},
+		{entity.CollectorDisk, usage.Disk, thresholds.Disk},
+	} {
+		if check.threshold > 0 && check.value >= check.threshold {
+			a.logger.Warn("Usage above threshold",
+				zap.String("resource", check.resource),
+				zap.Int("usage", check.value),
+				zap.Int("threshold", check.threshold),
+			)
+			breached = true
+		}
+	}
+	return breached
+}
+
+// flush sends the spool oldest first and drops what the server handled.
+// Samples rejected by a server fault stay in the spool for the next flush.
+func (a *Agent) flush(ctx context.Context) error {
+	for a.spool.Len() > 0 {
+		samples, lines, err := a.spool.Peek(maxBatchSize)
+		if err != nil {
+			return err
+		}
+		var retry []dto.MetricsRequest
+		if len(samples) > 0 {
+			response, err := a.send(ctx, samples)
+			if err != nil {
+				return err
+			}
+			for _, result := range response.Results {
+				if result.Status != dto.MetricsBatchItemRejected {
+					continue
+				}
+				// Nothing was accepted, keep the spool until an admin approves the server
+				if result.Error == domainerrors.ErrServerPendingApproval.Error() {
+					return domainerrors.ErrServerPendingApproval
+				}
+				if result.Retryable && result.Index >= 0 && result.Index < len(samples) {
+					retry = append(retry, samples[result.Index])
+					continue
+				}
+				a.logger.Warn("Sample rejected by the server, dropping it",
+					zap.Int("index", result.Index),
+					zap.String("error", result.Error),
+				)
+			}
+			a.logger.Debug("Metrics sent",
+				zap.Int("accepted", response.Accepted),
+				zap.Int("rejected", response.Rejected),
+				zap.Int("kept", len(retry)),
+			)
+			a.applyConfig(response.Config)
+		}
+		if err := a.spool.Replace(lines, retry); err != nil {
+			return err
+		}
+		if len(retry) > 0 {
+			return fmt.Errorf("%d samples rejected by a server error, keeping them in the spool", len(retry))
+		}
+	}
+	return nil
+}
+
+func (a *Agent) send(ctx context.Context, samples []dto.MetricsRequest) (*dto.MetricsBatchResponse, error) {
+	response, err := a.client.SendBatch(ctx, a.credentials.AccessToken, samples)
+	if !errors.Is(err, ErrUnauthorized) {
+		return response, err
+	}
+
+	a.logger.Info("Access token rejected, refreshing credentials")
+	if err := a.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return a.client.SendBatch(ctx, a.credentials.AccessToken, samples)
+}
+
+func (a *Agent) refresh(ctx context.Context) error {
+	auth, err := a.client.RefreshToken(ctx, a.credentials.RefreshToken)
+	if err != nil {
+		if errors.Is(err, ErrUnauthorized) {
+			a.logger.Error("Refresh token rejected, the server has to be enrolled again")
+		}
+		return fmt.Errorf("failed to refresh credentials: %w", err)
+	}
+
+	a.credentials.AccessToken = auth.AccessToken
+	a.credentials.RefreshToken = auth.RefreshToken
+	if auth.HeartbeatKey != "" {
+		a.credentials.HeartbeatKey = auth.HeartbeatKey
+	}
+	// The old refresh token is already consumed, keep going with the new one
+	// in memory even if it cannot be saved
+	if err := SaveCredentials(a.config.CredentialsFile, a.credentials); err != nil {
+		a.logger.Error("Failed to save refreshed credentials", zap.Error(err))
+	}
+	return nil
+}
+
+// ensureRegistered loads the saved credentials or registers the server with
+// the enrollment token, retrying until the API answers
+func (a *Agent) ensureRegistered(ctx context.Context) error {
+	credentials, err := LoadCredentials(a.config.CredentialsFile)
+	if err != nil {
+		return err
+	}
+	if credentials != nil && credentials.ServerID == a.config.ServerID {
+		a.credentials = credentials
+		return nil
+	}
+	if a.config.EnrollmentToken == "" {
+		return fmt.Errorf("no credentials found in %s and no enrollment_token configured", a.config.CredentialsFile)
+	}
+
+	req := dto.RegisterMetricsRequest{
+		EnrollmentToken: a.config.EnrollmentToken,
+		ServerID:        a.config.ServerID,
+		ServerName:      a.config.ServerName,
+		Description:     a.config.Description,
+		Location:        a.config.Location,
+		OS:              a.config.OS,
+		Tags:            a.config.Tags,
+		IntervalTime:    a.config.IntervalTime,
+	}
+	for {
+		auth, err := a.client.Register(ctx, req)
+		if err == nil {
+			a.credentials = &Credentials{
+				ServerID:     a.config.ServerID,
+				AccessToken:  auth.AccessToken,
+				RefreshToken: auth.RefreshToken,
+				HeartbeatKey: auth.HeartbeatKey,
+			}
+			if err := SaveCredentials(a.config.CredentialsFile, a.credentials); err != nil {
+				return err
+			}
+			a.logger.Info("Server registered", zap.String("server_id", a.config.ServerID))
+			return nil
+		}
+		if IsClientError(err) {
+			return fmt.Errorf("failed to register server: %w", err)
+		}
+
+		a.failures++
+		backoff := a.backoff()
+		a.logger.Warn("Failed to register server, retrying later",
+			zap.Error(err),
+			zap.Duration("retry_in", backoff),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// applyConfig switches to a config pushed by the server when it is newer than
+// the one in use
+func (a *Agent) applyConfig(config *dto.AgentConfig) {
+	if config == nil || (a.remote != nil && config.Version <= a.remote.Version) {
+		return
+	}
+	a.remote = config
+	a.logger.Info("Applied agent config",
+		zap.Int64("version", config.Version),
+		zap.Int64("interval_time", config.IntervalTime),
+		zap.Strings("collectors", config.Collectors),
+	)
+}
+
+func (a *Agent) interval() time.Duration {
+	if a.remote != nil && a.remote.IntervalTime > 0 {
+		return time.Duration(a.remote.IntervalTime) * time.Second
+	}
+	return time.Duration(a.config.IntervalTime) * time.Second
+}
+
+func (a *Agent) collectors() []string {
+	if a.remote != nil && len(a.remote.Collectors) > 0 {
+		return a.remote.Collectors
+	}
+	return entity.AllCollectors
+}
+
+// backoff doubles the interval for every consecutive failure up to the
+// configured maximum, then picks a random delay in its upper half
+func (a *Agent) backoff() time.Duration {
+	backoff := a.interval()
+	for i := 1; i < a.failures && backoff < a.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > a.config.MaxBackoff {
+		backoff = a.config.MaxBackoff
+	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain"
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// APIError is a non 2xx response of the API
+type APIError struct {
+	StatusCode int
+	Path       string
+	Message    string
+	Details    interface{}
+}
+
+func (e *APIError) Error() string {
+	if e.Details != nil {
+		return fmt.Sprintf("request to %s failed with status %d: %s: %v", e.Path, e.StatusCode, e.Message, e.Details)
+	}
+	return fmt.Sprintf("request to %s failed with status %d: %s", e.Path, e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	return target == ErrUnauthorized && e.StatusCode == http.StatusUnauthorized
+}
+
+// IsClientError reports whether err is a 4xx response, retrying it will not help
+func IsClientError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
+
+// Client talks to the server management API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type apiResponse struct {
+	Success bool              `json:"success"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Data    json.RawMessage   `json:"data,omitempty"`
+	Error   *domain.ErrorInfo `json:"error,omitempty"`
+}
+
+func (c *Client) Register(ctx context.Context, req dto.RegisterMetricsRequest) (*dto.AuthResponse, error) {
+	var response dto.AuthResponse
+	if err := c.do(ctx, "/api/v1/servers/register", "", req, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*dto.AuthResponse, error) {
+	var response dto.AuthResponse
+	req := dto.RefreshTokenRequest{RefreshToken: refreshToken}
+	if err := c.do(ctx, "/api/v1/servers/token/refresh", "", req, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) SendBatch(ctx context.Context, accessToken string, samples []dto.MetricsRequest) (*dto.MetricsBatchResponse, error) {
+	var response dto.MetricsBatchResponse
+	if err := c.do(ctx, "/api/v1/servers/monitoring/batch", accessToken, samples, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) do(ctx context.Context, path string, accessToken string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	var response apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode response from %s (status %d): %w", path, resp.StatusCode, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Path:       path,
+			Message:    response.Message,
+		}
+		if response.Error != nil {
+			apiErr.Details = response.Error.Details
+		}
+		return apiErr
+	}
+
+	if out != nil && len(response.Data) > 0 {
+		if err := json.Unmarshal(response.Data, out); err != nil {
+			return fmt.Errorf("failed to decode response data: %w", err)
+		}
+	}
+	return nil
+}
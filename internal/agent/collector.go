@@ -0,0 +1,23 @@
+package agent
+
+import "github.com/th1enq/server_management_system/internal/dto"
+
+// Usage is one resource usage reading, percentages plus the extended readings
+// of metrics schema version 2. Extended readings are nil when unavailable.
+type Usage struct {
+	CPU  int
+	RAM  int
+	Disk int
+
+	Load          *dto.LoadAverage
+	CPUCores      []float64
+	Mounts        []dto.MountUsage
+	Network       *dto.NetworkUsage
+	Processes     *int
+	UptimeSeconds *int64
+}
+
+// Collector reads the enabled resources, the others are left at zero
+type Collector interface {
+	Collect(collectors []string) (Usage, error)
+}
@@ -0,0 +1,305 @@
+//go:build linux
+
+package agent
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+type cpuTimes struct {
+	idle  uint64
+	total uint64
+}
+
+type procCollector struct {
+	procPath string
+	diskPath string
+	mounts   []string
+
+	prevCPU   cpuTimes
+	prevCores map[string]cpuTimes
+
+	prevRx      uint64
+	prevTx      uint64
+	prevNetTime time.Time
+}
+
+// NewCollector returns a collector reading CPU, memory, load and network from
+// /proc and disk usage of the configured mounts
+func NewCollector(config Config) (Collector, error) {
+	return &procCollector{
+		procPath:  "/proc",
+		diskPath:  config.DiskPath,
+		mounts:    config.Mounts,
+		prevCores: make(map[string]cpuTimes),
+	}, nil
+}
+
+func (p *procCollector) Collect(collectors []string) (Usage, error) {
+	var usage Usage
+	for _, collector := range collectors {
+		var err error
+		switch collector {
+		case entity.CollectorCPU:
+			usage.CPU, usage.CPUCores, err = p.cpuUsage()
+		case entity.CollectorRAM:
+			usage.RAM, err = p.ramUsage()
+		case entity.CollectorDisk:
+			usage.Disk, err = p.diskUsage()
+			usage.Mounts = p.mountUsage()
+		}
+		if err != nil {
+			return Usage{}, err
+		}
+	}
+
+	// The extended readings are best effort, a missing one is left out of the sample
+	usage.Load = p.loadAverage()
+	usage.Network = p.networkUsage()
+	usage.Processes = p.processCount()
+	usage.UptimeSeconds = p.uptime()
+	return usage, nil
+}
+
+// cpuUsage is the busy time since the previous call, overall and per core.
+// The first call measures since boot.
+func (p *procCollector) cpuUsage() (int, []float64, error) {
+	file, err := os.Open(p.procPath + "/stat")
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read cpu stats: %w", err)
+	}
+	defer file.Close()
+
+	overall := -1
+	var cores []float64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		times, err := parseCPUTimes(fields[1:])
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if fields[0] == "cpu" {
+			overall = int(math.Round(busyPercent(p.prevCPU, times)))
+			p.prevCPU = times
+			continue
+		}
+		cores = append(cores, math.Round(busyPercent(p.prevCores[fields[0]], times)*10)/10)
+		p.prevCores[fields[0]] = times
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, nil, fmt.Errorf("failed to read cpu stats: %w", err)
+	}
+	if overall < 0 {
+		return 0, nil, fmt.Errorf("failed to parse cpu stats: no cpu line in %s/stat", p.procPath)
+	}
+	return overall, cores, nil
+}
+
+// parseCPUTimes sums user nice system idle iowait irq softirq steal, guest
+// time is already in user
+func parseCPUTimes(fields []string) (cpuTimes, error) {
+	var times cpuTimes
+	for i, field := range fields {
+		if i >= 8 {
+			break
+		}
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return cpuTimes{}, fmt.Errorf("failed to parse cpu stats: %w", err)
+		}
+		times.total += value
+		if i == 3 || i == 4 {
+			times.idle += value
+		}
+	}
+	return times, nil
+}
+
+func busyPercent(prev, cur cpuTimes) float64 {
+	deltaIdle := cur.idle - prev.idle
+	deltaTotal := cur.total - prev.total
+	if deltaTotal == 0 {
+		return 0
+	}
+	return float64(deltaTotal-deltaIdle) / float64(deltaTotal) * 100
+}
+
+func (p *procCollector) ramUsage() (int, error) {
+	file, err := os.Open(p.procPath + "/meminfo")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read memory stats: %w", err)
+	}
+	defer file.Close()
+
+	var total, available uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total, _ = strconv.ParseUint(fields[1], 10, 64)
+		case "MemAvailable:":
+			available, _ = strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read memory stats: %w", err)
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("failed to parse memory stats: MemTotal not found")
+	}
+	return percent(float64(total-available), float64(total)), nil
+}
+
+// diskUsage matches the Use% column of df
+func (p *procCollector) diskUsage() (int, error) {
+	used, available, err := statfs(p.diskPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read disk stats: %w", err)
+	}
+	if used+available == 0 {
+		return 0, nil
+	}
+	return percent(float64(used), float64(used+available)), nil
+}
+
+func (p *procCollector) mountUsage() []dto.MountUsage {
+	mounts := make([]dto.MountUsage, 0, len(p.mounts))
+	for _, path := range p.mounts {
+		used, available, err := statfs(path)
+		if err != nil || used+available == 0 {
+			continue
+		}
+		mounts = append(mounts, dto.MountUsage{
+			Path:       path,
+			UsedBytes:  int64(used),
+			TotalBytes: int64(used + available),
+			Percent:    math.Round(float64(used)/float64(used+available)*1000) / 10,
+		})
+	}
+	return mounts
+}
+
+func statfs(path string) (uint64, uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	used := (stat.Blocks - stat.Bfree) * uint64(stat.Bsize)
+	available := stat.Bavail * uint64(stat.Bsize)
+	return used, available, nil
+}
+
+func (p *procCollector) loadAverage() *dto.LoadAverage {
+	data, err := os.ReadFile(p.procPath + "/loadavg")
+	if err != nil {
+		return nil
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return nil
+	}
+	var load [3]float64
+	for i := range load {
+		if load[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return nil
+		}
+	}
+	return &dto.LoadAverage{Load1: load[0], Load5: load[1], Load15: load[2]}
+}
+
+// networkUsage is the throughput of all interfaces but loopback since the
+// previous call, nil on the first call
+func (p *procCollector) networkUsage() *dto.NetworkUsage {
+	file, err := os.Open(p.procPath + "/net/dev")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var rx, tx uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, counters, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(name) == "lo" {
+			continue
+		}
+		fields := strings.Fields(counters)
+		if len(fields) < 9 {
+			continue
+		}
+		received, _ := strconv.ParseUint(fields[0], 10, 64)
+		transmitted, _ := strconv.ParseUint(fields[8], 10, 64)
+		rx += received
+		tx += transmitted
+	}
+	if scanner.Err() != nil {
+		return nil
+	}
+
+	now := time.Now()
+	prevRx, prevTx, prevTime := p.prevRx, p.prevTx, p.prevNetTime
+	p.prevRx, p.prevTx, p.prevNetTime = rx, tx, now
+	elapsed := now.Sub(prevTime).Seconds()
+	// Counters reset when an interface goes away
+	if prevTime.IsZero() || elapsed <= 0 || rx < prevRx || tx < prevTx {
+		return nil
+	}
+	return &dto.NetworkUsage{
+		RxBytesPerSec: math.Round(float64(rx-prevRx) / elapsed),
+		TxBytesPerSec: math.Round(float64(tx-prevTx) / elapsed),
+	}
+}
+
+func (p *procCollector) processCount() *int {
+	entries, err := os.ReadDir(p.procPath)
+	if err != nil {
+		return nil
+	}
+	count := 0
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+			count++
+		}
+	}
+	return &count
+}
+
+func (p *procCollector) uptime() *int64 {
+	data, err := os.ReadFile(p.procPath + "/uptime")
+	if err != nil {
+		return nil
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return nil
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil
+	}
+	uptime := int64(seconds)
+	return &uptime
+}
+
+func percent(part, total float64) int {
+	return int(math.Round(part / total * 100))
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package agent
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func NewCollector(config Config) (Collector, error) {
+	return nil, fmt.Errorf("metrics collection is not supported on %s", runtime.GOOS)
+}
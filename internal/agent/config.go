@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Config struct {
+	// ServerURL is the base URL of the management API, e.g. http://10.0.0.1:8080
+	ServerURL       string   `yaml:"server_url"`
+	EnrollmentToken string   `yaml:"enrollment_token"`
+	ServerID        string   `yaml:"server_id"`
+	ServerName      string   `yaml:"server_name"`
+	Description     string   `yaml:"description"`
+	Location        string   `yaml:"location"`
+	OS              string   `yaml:"os"`
+	Tags            []string `yaml:"tags"`
+	IntervalTime    int64    `yaml:"interval_time"` // in seconds
+
+	CredentialsFile string        `yaml:"credentials_file"`
+	SpoolFile       string        `yaml:"spool_file"`
+	SpoolMaxSamples int           `yaml:"spool_max_samples"`
+	DiskPath        string        `yaml:"disk_path"`
+	Mounts          []string      `yaml:"mounts"` // reported per mount, defaults to disk_path
+	RequestTimeout  time.Duration `yaml:"request_timeout"`
+	MaxBackoff      time.Duration `yaml:"max_backoff"`
+
+	Log Log `yaml:"log"`
+}
+
+type Log struct {
+	Level    string `yaml:"level"`
+	FilePath string `yaml:"file_path"`
+}
+
+// LoadConfig reads the agent configuration and fills in defaults
+func LoadConfig(path string) (Config, error) {
+	configBytes, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read YAML file: %w", err)
+	}
+
+	config := Config{
+		IntervalTime:    10,
+		CredentialsFile: "/var/lib/sms-agent/credentials.json",
+		SpoolFile:       "/var/lib/sms-agent/spool.ndjson",
+		SpoolMaxSamples: 100000,
+		DiskPath:        "/",
+		RequestTimeout:  10 * time.Second,
+		MaxBackoff:      5 * time.Minute,
+		Log: Log{
+			Level:    "info",
+			FilePath: "/var/log/sms-agent/agent.log",
+		},
+	}
+	if err := yaml.Unmarshal(configBytes, &config); err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+
+	if config.ServerURL == "" {
+		return Config{}, fmt.Errorf("server_url is required")
+	}
+	if config.ServerID == "" {
+		return Config{}, fmt.Errorf("server_id is required")
+	}
+	if config.ServerName == "" {
+		config.ServerName = config.ServerID
+	}
+	if config.IntervalTime < 1 {
+		return Config{}, fmt.Errorf("interval_time must be at least 1 second")
+	}
+	if len(config.Mounts) == 0 {
+		config.Mounts = []string{config.DiskPath}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// NewLogger logs to the configured file. In foreground mode it logs to stderr
+// instead, without timestamps since journald adds its own.
+func NewLogger(cfg Log, foreground bool) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        "timestamp",
+		LevelKey:       "level",
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+
+	var core zapcore.Core
+	if foreground {
+		encoderConfig.TimeKey = zapcore.OmitKey
+		core = zapcore.NewCore(
+			zapcore.NewConsoleEncoder(encoderConfig),
+			zapcore.AddSync(os.Stderr),
+			level,
+		)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(cfg.FilePath), 0755); err != nil {
+			return nil, err
+		}
+		core = zapcore.NewCore(
+			zapcore.NewJSONEncoder(encoderConfig),
+			zapcore.AddSync(&lumberjack.Logger{
+				Filename:   cfg.FilePath,
+				MaxSize:    10,
+				MaxBackups: 3,
+				Compress:   true,
+			}),
+			level,
+		)
+	}
+
+	return zap.New(core), nil
+}
@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+// Spool buffers samples on disk as NDJSON until the server accepts them
+type Spool struct {
+	path       string
+	maxSamples int
+	count      int
+}
+
+func NewSpool(path string, maxSamples int) (*Spool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	s := &Spool{
+		path:       path,
+		maxSamples: maxSamples,
+	}
+	lines, err := s.readLines()
+	if err != nil {
+		return nil, err
+	}
+	s.count = len(lines)
+	return s, nil
+}
+
+func (s *Spool) Len() int {
+	return s.count
+}
+
+// Append adds a sample at the end of the spool, dropping the oldest samples
+// once the spool is full
+func (s *Spool) Append(sample dto.MetricsRequest) error {
+	if s.maxSamples > 0 && s.count >= s.maxSamples {
+		if err := s.Drop(s.count - s.maxSamples + 1); err != nil {
+			return err
+		}
+	}
+
+	line, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("failed to encode sample: %w", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open spool: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write spool: %w", err)
+	}
+	s.count++
+	return nil
+}
+
+// Peek returns up to n of the oldest samples and the number of spool lines
+// they span. Lines that cannot be decoded are skipped but still counted.
+func (s *Spool) Peek(n int) ([]dto.MetricsRequest, int, error) {
+	lines, err := s.readLines()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+
+	samples := make([]dto.MetricsRequest, 0, len(lines))
+	for _, line := range lines {
+		var sample dto.MetricsRequest
+		if err := json.Unmarshal(line, &sample); err != nil {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples, len(lines), nil
+}
+
+// Drop removes the n oldest lines of the spool
+func (s *Spool) Drop(n int) error {
+	return s.Replace(n, nil)
+}
+
+// Replace removes the n oldest lines of the spool and puts samples in their
+// place, ahead of the rest
+func (s *Spool) Replace(n int, samples []dto.MetricsRequest) error {
+	lines, err := s.readLines()
+	if err != nil {
+		return err
+	}
+	if n > len(lines) {
+		n = len(lines)
+	}
+	rest := lines[n:]
+	if len(samples) > 0 {
+		kept := make([][]byte, 0, len(samples)+len(rest))
+		for _, sample := range samples {
+			line, err := json.Marshal(sample)
+			if err != nil {
+				return fmt.Errorf("failed to encode sample: %w", err)
+			}
+			kept = append(kept, line)
+		}
+		rest = append(kept, rest...)
+	}
+
+	var buf bytes.Buffer
+	for _, line := range rest {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write spool: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write spool: %w", err)
+	}
+	s.count = len(rest)
+	return nil
+}
+
+func (s *Spool) readLines() ([][]byte, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool: %w", err)
+	}
+	defer file.Close()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		lines = append(lines, append([]byte(nil), line...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read spool: %w", err)
+	}
+	return lines, nil
+}
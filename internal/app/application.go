@@ -2,9 +2,12 @@ package app
 
 import (
 	"context"
+	"sync"
 	"syscall"
 
+	"github.com/th1enq/server_management_system/internal/delivery/consumer"
 	"github.com/th1enq/server_management_system/internal/delivery/http"
+	"github.com/th1enq/server_management_system/internal/delivery/udp"
 	"github.com/th1enq/server_management_system/internal/infrastructure/outbox"
 	"github.com/th1enq/server_management_system/internal/jobs/scheduler"
 	"github.com/th1enq/server_management_system/internal/utils"
@@ -12,28 +15,43 @@ import (
 )
 
 type Application struct {
-	httpServer http.IServer
-	jobManager scheduler.JobManager
-	dispatcher outbox.Dispatcher
-	logger     *zap.Logger
+	httpServer     http.IServer
+	udpServer      udp.IServer
+	consumerServer consumer.IServer
+	jobManager     scheduler.JobManager
+	dispatcher     outbox.Dispatcher
+	logger         *zap.Logger
 }
 
 func NewApplication(
 	httpServer http.IServer,
+	udpServer udp.IServer,
+	consumerServer consumer.IServer,
 	jobManager scheduler.JobManager,
 	logger *zap.Logger,
 	dispatcher outbox.Dispatcher,
 ) *Application {
 	return &Application{
-		httpServer: httpServer,
-		jobManager: jobManager,
-		dispatcher: dispatcher,
-		logger:     logger,
+		httpServer:     httpServer,
+		udpServer:      udpServer,
+		consumerServer: consumerServer,
+		jobManager:     jobManager,
+		dispatcher:     dispatcher,
+		logger:         logger,
 	}
 }
 
 func (app *Application) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	app.logger.Info("Starting application...")
+	// Bound before anything else runs so a taken port fails the start
+	if err := app.udpServer.Start(ctx); err != nil {
+		app.logger.Error("UDP heartbeat listener failed to start", zap.Error(err))
+		return err
+	}
+
 	app.logger.Info("Starting background job manager...")
 	if err := app.jobManager.Start(ctx); err != nil {
 		app.logger.Error("Failed to start job manager", zap.Error(err))
@@ -50,24 +68,40 @@ func (app *Application) Start(ctx context.Context) error {
 	}()
 	app.logger.Info("HTTP server started successfully")
 
+	var consumers sync.WaitGroup
+	consumers.Add(1)
+	go func() {
+		defer consumers.Done()
+		if err := app.consumerServer.Start(ctx); err != nil {
+			app.logger.Error("Kafka consumer failed", zap.Error(err))
+		}
+	}()
+
 	app.logger.Info("Starting outbox dispatcher...")
 	errChan := make(chan error)
 	doneChan := make(chan struct{})
 	app.dispatcher.Run(errChan, doneChan)
-	defer func() {
-		doneChan <- struct{}{}
+	// The dispatcher keeps running after an error, they are only logged. It
+	// closes errChan once stopped.
+	dispatcherStopped := make(chan struct{})
+	go func() {
+		defer close(dispatcherStopped)
+		for err := range errChan {
+			app.logger.Error("Outbox dispatcher encountered an error", zap.Error(err))
+		}
 	}()
-	err := <-errChan
-	if err != nil {
-		app.logger.Error("Outbox dispatcher encountered an error", zap.Error(err))
-	}
 
 	app.logger.Info("Application started successfully")
 	utils.BlockUntilSignal(syscall.SIGINT, syscall.SIGTERM)
-	return app.shutdown()
+	return app.shutdown(cancel, doneChan, dispatcherStopped, &consumers)
 }
 
-func (app *Application) shutdown() error {
+func (app *Application) shutdown(
+	cancel context.CancelFunc,
+	dispatcherDone chan<- struct{},
+	dispatcherStopped <-chan struct{},
+	consumers *sync.WaitGroup,
+) error {
 	app.logger.Info("Shutting down application...")
 
 	app.logger.Info("Stopping background job manager...")
@@ -77,6 +111,16 @@ func (app *Application) shutdown() error {
 		app.logger.Info("Background job manager stopped successfully")
 	}
 
+	app.logger.Info("Stopping outbox dispatcher...")
+	close(dispatcherDone)
+	<-dispatcherStopped
+
+	// The message being handled is committed or left for the next start
+	// before the consumer returns
+	app.logger.Info("Stopping Kafka consumer...")
+	cancel()
+	consumers.Wait()
+
 	app.logger.Info("Application shutdown completed")
 	return nil
 }
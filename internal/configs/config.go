@@ -13,6 +13,7 @@ type ConfigFilePath string
 type Config struct {
 	Server        Server        `yaml:"server"`
 	Database      Database      `yaml:"database"`
+	TSDB          TSDB          `yaml:"tsdb"`
 	Cache         Cache         `yaml:"cache"`
 	Log           Log           `yaml:"log"`
 	JWT           JWT           `yaml:"jwt"`
@@ -21,6 +22,15 @@ type Config struct {
 	Email         Email         `yaml:"email"`
 	Broker        Broker        `yaml:"broker"`
 	Dispatcher    Dispatcher    `yaml:"dispatcher"`
+	Consumer      Consumer      `yaml:"consumer"`
+	RemoteWrite   RemoteWrite   `yaml:"remote_write"`
+	UDPHeartbeat  UDPHeartbeat  `yaml:"udp_heartbeat"`
+	Scrape        Scrape        `yaml:"scrape"`
+	Probe         Probe         `yaml:"probe"`
+	TargetPolicy  TargetPolicy  `yaml:"target_policy"`
+	StatusPolicy  StatusPolicy  `yaml:"status_policy"`
+
+	LeaderElection LeaderElection `yaml:"leader_election"`
 }
 
 func NewConfig(filePath ConfigFilePath) (Config, error) {
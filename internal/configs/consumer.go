@@ -0,0 +1,20 @@
+package configs
+
+import "time"
+
+type Consumer struct {
+	Enabled bool   `yaml:"enabled"`
+	GroupID string `yaml:"group_id"`
+	// MaxRetries is how many times a failed message is retried before it is
+	// sent to the dead-letter topic
+	MaxRetries int `yaml:"max_retries"`
+	// RetryBackoff doubles after each attempt, up to MaxRetryBackoff
+	RetryBackoff    time.Duration `yaml:"retry_backoff"`
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff"`
+	// DeadLetterSuffix is appended to the topic of a message to name its
+	// dead-letter topic
+	DeadLetterSuffix string `yaml:"dead_letter_suffix"`
+	// CommitInterval is how often the offsets of the handled messages are
+	// committed, the rest is committed when a session ends
+	CommitInterval time.Duration `yaml:"commit_interval"`
+}
@@ -10,7 +10,26 @@ type UpdateStatus struct {
 	Schedule string `yaml:"schedule"`
 }
 
+type ScrapeMetrics struct {
+	Name     string `yaml:"name"`
+	Schedule string `yaml:"schedule"`
+}
+
+type ProbeServers struct {
+	Name     string `yaml:"name"`
+	Schedule string `yaml:"schedule"`
+}
+
+type ApplyMaintenanceWindows struct {
+	Name     string `yaml:"name"`
+	Schedule string `yaml:"schedule"`
+}
+
 type Cron struct {
-	DailyReport  DailyReport  `yaml:"daily_report"`
-	UpdateStatus UpdateStatus `yaml:"update_status"`
+	DailyReport   DailyReport   `yaml:"daily_report"`
+	UpdateStatus  UpdateStatus  `yaml:"update_status"`
+	ScrapeMetrics ScrapeMetrics `yaml:"scrape_metrics"`
+	ProbeServers  ProbeServers  `yaml:"probe_servers"`
+
+	ApplyMaintenanceWindows ApplyMaintenanceWindows `yaml:"apply_maintenance_windows"`
 }
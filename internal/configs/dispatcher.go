@@ -5,6 +5,9 @@ import "time"
 type RetrialPolicy struct {
 	MaxSendAttemptsEnabled bool `yaml:"max_send_attempts_enabled"`
 	MaxSendAttempts        int  `yaml:"max_send_attempts"`
+	// InitialBackoff doubles after each failed attempt, up to MaxBackoff
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
 }
 
 type Dispatcher struct {
@@ -15,4 +18,8 @@ type Dispatcher struct {
 	RetrialPolicy             RetrialPolicy `yaml:"retrial_policy"`
 	MessagesRetentionDuration time.Duration `yaml:"messages_retention_duration"`
 	MachineID                 string        `yaml:"machine_id"`
+	// BatchSize bounds the records a dispatcher locks per tick
+	BatchSize int `yaml:"batch_size"`
+	// TopicRetrialPolicies replace RetrialPolicy for the records of a topic
+	TopicRetrialPolicies map[string]RetrialPolicy `yaml:"topic_retrial_policies"`
 }
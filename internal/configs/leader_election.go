@@ -0,0 +1,14 @@
+package configs
+
+import "time"
+
+// LeaderElection configures the Redis lease deciding which instance runs the
+// scheduled tasks
+type LeaderElection struct {
+	Key           string        `yaml:"key"`
+	LeaseDuration time.Duration `yaml:"lease_duration"`
+	RenewInterval time.Duration `yaml:"renew_interval"`
+	// InstanceID identifies the instance holding the lease, defaults to the
+	// hostname and the process ID
+	InstanceID string `yaml:"instance_id"`
+}
@@ -0,0 +1,6 @@
+package configs
+
+type Probe struct {
+	// Workers bound the servers probed at the same time
+	Workers int `yaml:"workers"`
+}
@@ -0,0 +1,13 @@
+package configs
+
+type RemoteWrite struct {
+	// ServerLabel is the series label holding the server ID, e.g. instance or server_id
+	ServerLabel string `yaml:"server_label"`
+	// StripPort drops the :port suffix of the label value, for instance="host:9100"
+	StripPort bool `yaml:"strip_port"`
+	// BearerTokens are accepted for any registered server. Server access tokens
+	// are always accepted for their own server.
+	BearerTokens []string `yaml:"bearer_tokens"`
+	// MaxDecodedBytes bounds the size of a request once decompressed
+	MaxDecodedBytes int `yaml:"max_decoded_bytes"`
+}
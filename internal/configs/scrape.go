@@ -0,0 +1,10 @@
+package configs
+
+import "time"
+
+type Scrape struct {
+	// Workers bound the scrapes running at the same time
+	Workers      int           `yaml:"workers"`
+	Timeout      time.Duration `yaml:"timeout"`
+	MaxBodyBytes int64         `yaml:"max_body_bytes"`
+}
@@ -0,0 +1,18 @@
+package configs
+
+import "time"
+
+// StatusPolicy is the default policy of every server, see entity.StatusPolicy.
+// Servers can override each field.
+type StatusPolicy struct {
+	MissedIntervals    int     `yaml:"missed_intervals"`
+	GraceMultiplier    float64 `yaml:"grace_multiplier"`
+	RecoveryHeartbeats int     `yaml:"recovery_heartbeats"`
+	// OnlineThreshold is the uptime percentage counting a server as online
+	// in reports
+	OnlineThreshold float64 `yaml:"online_threshold"`
+	// FlapThreshold changes within FlapWindow make a server FLAPPING, zero
+	// disables flap detection
+	FlapThreshold int           `yaml:"flap_threshold"`
+	FlapWindow    time.Duration `yaml:"flap_window"`
+}
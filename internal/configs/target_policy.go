@@ -0,0 +1,11 @@
+package configs
+
+// TargetPolicy restricts the addresses scrapes and probes connect to.
+// Loopback, link-local and private addresses are refused unless they are in
+// AllowedNetworks.
+type TargetPolicy struct {
+	// AllowedNetworks are CIDRs reachable even if internal, e.g. 10.0.0.0/8
+	AllowedNetworks []string `yaml:"allowed_networks"`
+	// DeniedNetworks are CIDRs never reachable, they win over AllowedNetworks
+	DeniedNetworks []string `yaml:"denied_networks"`
+}
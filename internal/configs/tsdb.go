@@ -0,0 +1,10 @@
+package configs
+
+type TSDB struct {
+	Driver   string `yaml:"driver"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	DB       string `yaml:"db"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+}
@@ -0,0 +1,12 @@
+package configs
+
+import "time"
+
+type UDPHeartbeat struct {
+	Enabled bool `yaml:"enabled"`
+	Port    int  `yaml:"port"`
+	// ReplayWindow is how far the datagram timestamp may be from the server clock
+	ReplayWindow time.Duration `yaml:"replay_window"`
+	// Workers bound the datagrams processed concurrently, the rest are dropped
+	Workers int `yaml:"workers"`
+}
@@ -6,6 +6,7 @@ var WireSet = wire.NewSet(
 	NewConfig,
 	wire.FieldsOf(new(Config), "Server"),
 	wire.FieldsOf(new(Config), "Database"),
+	wire.FieldsOf(new(Config), "TSDB"),
 	wire.FieldsOf(new(Config), "Cache"),
 	wire.FieldsOf(new(Config), "Log"),
 	wire.FieldsOf(new(Config), "Cron"),
@@ -14,4 +15,12 @@ var WireSet = wire.NewSet(
 	wire.FieldsOf(new(Config), "Email"),
 	wire.FieldsOf(new(Config), "Broker"),
 	wire.FieldsOf(new(Config), "Dispatcher"),
+	wire.FieldsOf(new(Config), "Consumer"),
+	wire.FieldsOf(new(Config), "RemoteWrite"),
+	wire.FieldsOf(new(Config), "UDPHeartbeat"),
+	wire.FieldsOf(new(Config), "Scrape"),
+	wire.FieldsOf(new(Config), "Probe"),
+	wire.FieldsOf(new(Config), "TargetPolicy"),
+	wire.FieldsOf(new(Config), "StatusPolicy"),
+	wire.FieldsOf(new(Config), "LeaderElection"),
 )
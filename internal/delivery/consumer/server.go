@@ -0,0 +1,67 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+	"github.com/th1enq/server_management_system/internal/infrastructure/mq"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+const serverStatusTopic = "server_status_updates"
+
+type IServer interface {
+	Start(ctx context.Context) error
+}
+
+type server struct {
+	consumerGroup      mq.ConsumerGroup
+	healthCheckUseCase usecases.HealthCheckUseCase
+	logger             *zap.Logger
+}
+
+func NewServer(
+	consumerGroup mq.ConsumerGroup,
+	healthCheckUseCase usecases.HealthCheckUseCase,
+	logger *zap.Logger,
+) IServer {
+	return &server{
+		consumerGroup:      consumerGroup,
+		healthCheckUseCase: healthCheckUseCase,
+		logger:             logger,
+	}
+}
+
+// Start consumes the topics until ctx is cancelled
+func (s *server) Start(ctx context.Context) error {
+	if s.consumerGroup == nil {
+		s.logger.Info("Kafka consumer disabled")
+		return nil
+	}
+
+	return s.consumerGroup.Consume(ctx, map[string]mq.Handler{
+		serverStatusTopic: s.handleServerStatus,
+	})
+}
+
+// handleServerStatus indexes a status change for the uptime reports
+func (s *server) handleServerStatus(ctx context.Context, message models.Message) error {
+	// Messages published before the events were versioned carry no version
+	if version := message.Headers[models.HeaderSchemaVersion]; version != "" && version != models.EventSchemaVersion {
+		return fmt.Errorf("%w: unsupported schema version %s", mq.ErrMalformedMessage, version)
+	}
+
+	var msg dto.ServerStatusMessage
+	if err := json.Unmarshal(message.Body, &msg); err != nil {
+		return fmt.Errorf("%w: %v", mq.ErrMalformedMessage, err)
+	}
+	if msg.ServerID == "" || msg.Timestamp.IsZero() {
+		return fmt.Errorf("%w: missing server_id or timestamp", mq.ErrMalformedMessage)
+	}
+
+	return s.healthCheckUseCase.InsertUptime(ctx, msg)
+}
@@ -0,0 +1,88 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+	"github.com/th1enq/server_management_system/internal/infrastructure/mq"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+type uptimeIndex struct {
+	usecases.HealthCheckUseCase
+	indexed []dto.ServerStatusMessage
+}
+
+func (u *uptimeIndex) InsertUptime(ctx context.Context, msg dto.ServerStatusMessage) error {
+	u.indexed = append(u.indexed, msg)
+	return nil
+}
+
+func TestHandleServerStatus(t *testing.T) {
+	body := []byte(`{"server_id":"server-01","status":"ON","timestamp":"2024-05-01T10:00:00Z"}`)
+
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		body        []byte
+		wantIndexed bool
+	}{
+		{
+			name:        "current schema version",
+			headers:     map[string]string{models.HeaderSchemaVersion: models.EventSchemaVersion},
+			body:        body,
+			wantIndexed: true,
+		},
+		{
+			name:        "published before the events were versioned",
+			body:        body,
+			wantIndexed: true,
+		},
+		{
+			name:    "unknown schema version",
+			headers: map[string]string{models.HeaderSchemaVersion: "2"},
+			body:    body,
+		},
+		{
+			name: "invalid json",
+			body: []byte(`{"server_id":`),
+		},
+		{
+			name: "missing timestamp",
+			body: []byte(`{"server_id":"server-01","status":"ON"}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := &uptimeIndex{}
+			s := &server{healthCheckUseCase: index, logger: zap.NewNop()}
+
+			err := s.handleServerStatus(context.Background(), models.Message{
+				Topic:   serverStatusTopic,
+				Headers: tt.headers,
+				Body:    tt.body,
+			})
+			if !tt.wantIndexed {
+				// Malformed messages go to the dead-letter topic without retries
+				assert.True(t, errors.Is(err, mq.ErrMalformedMessage), "got error %v", err)
+				assert.Empty(t, index.indexed)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []dto.ServerStatusMessage{{
+				ServerID:  "server-01",
+				Status:    entity.ServerStatusOn,
+				Timestamp: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			}}, index.indexed)
+		})
+	}
+}
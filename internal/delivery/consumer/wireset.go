@@ -0,0 +1,7 @@
+package consumer
+
+import "github.com/google/wire"
+
+var WireSet = wire.NewSet(
+	NewServer,
+)
@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/delivery/http/presenters"
+	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+type AlertRuleController struct {
+	alertRuleUseCase   usecases.AlertRuleUseCase
+	alertRulePresenter presenters.AlertRulePresenter
+	logger             *zap.Logger
+}
+
+func NewAlertRuleController(
+	alertRuleUseCase usecases.AlertRuleUseCase,
+	alertRulePresenter presenters.AlertRulePresenter,
+	logger *zap.Logger,
+) *AlertRuleController {
+	return &AlertRuleController{
+		alertRuleUseCase:   alertRuleUseCase,
+		alertRulePresenter: alertRulePresenter,
+		logger:             logger,
+	}
+}
+
+// CreateRule godoc
+// @Summary Create alert rule
+// @Description Create a threshold rule evaluated on incoming metrics, e.g. cpu > 90 for 5m on servers in a location. Custom gauges are selected with custom.<name>.
+// @Tags alert-rules
+// @Accept json
+// @Produce json
+// @Param rule body dto.CreateAlertRuleRequest true "Alert rule"
+// @Success 201 {object} domain.APIResponse{data=dto.AlertRuleResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/alert-rules [post]
+func (h *AlertRuleController) CreateRule(c *gin.Context) {
+	var req dto.CreateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid create alert rule request", zap.Error(err))
+		h.alertRulePresenter.InvalidRequest(c, "Invalid request data", err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+
+	rule, err := h.alertRuleUseCase.CreateRule(c.Request.Context(), req, userID)
+	if err != nil {
+		h.logger.Error("Failed to create alert rule",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrInvalidInput) {
+			h.alertRulePresenter.InvalidRequest(c, "Invalid request data", err)
+			return
+		}
+		h.alertRulePresenter.InternalServerError(c, "Failed to create alert rule", err)
+		return
+	}
+
+	h.alertRulePresenter.RuleCreated(c, dto.FromEntityToAlertRuleResponse(rule))
+}
+
+// ListRules godoc
+// @Summary List alert rules
+// @Description List every alert rule
+// @Tags alert-rules
+// @Produce json
+// @Success 200 {object} domain.APIResponse{data=[]dto.AlertRuleResponse}
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/alert-rules [get]
+func (h *AlertRuleController) ListRules(c *gin.Context) {
+	rules, err := h.alertRuleUseCase.ListRules(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list alert rules",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		h.alertRulePresenter.InternalServerError(c, "Failed to list alert rules", err)
+		return
+	}
+
+	response := make([]dto.AlertRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, dto.FromEntityToAlertRuleResponse(rule))
+	}
+
+	h.alertRulePresenter.RulesRetrieved(c, response)
+}
+
+// GetRule godoc
+// @Summary Get alert rule
+// @Description Get an alert rule by ID
+// @Tags alert-rules
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Success 200 {object} domain.APIResponse{data=dto.AlertRuleResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/alert-rules/{id} [get]
+func (h *AlertRuleController) GetRule(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.alertRuleUseCase.GetRule(c.Request.Context(), id)
+	if err != nil {
+		h.alertRulePresenter.RuleNotFound(c, "Alert rule not found")
+		return
+	}
+
+	h.alertRulePresenter.RuleRetrieved(c, dto.FromEntityToAlertRuleResponse(rule))
+}
+
+// UpdateRule godoc
+// @Summary Update alert rule
+// @Description Update an alert rule. Changing the condition or disabling the rule resolves its firing alerts.
+// @Tags alert-rules
+// @Accept json
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Param rule body dto.UpdateAlertRuleRequest true "Alert rule changes"
+// @Success 200 {object} domain.APIResponse{data=dto.AlertRuleResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/alert-rules/{id} [put]
+func (h *AlertRuleController) UpdateRule(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid update alert rule request", zap.Error(err))
+		h.alertRulePresenter.InvalidRequest(c, "Invalid request data", err)
+		return
+	}
+
+	rule, err := h.alertRuleUseCase.UpdateRule(c.Request.Context(), id, req)
+	if err != nil {
+		h.logger.Error("Failed to update alert rule",
+			zap.Error(err),
+			zap.Uint("id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		switch {
+		case errors.Is(err, domainerrors.ErrAlertRuleNotFound):
+			h.alertRulePresenter.RuleNotFound(c, "Alert rule not found")
+		case errors.Is(err, domainerrors.ErrInvalidInput):
+			h.alertRulePresenter.InvalidRequest(c, "Invalid request data", err)
+		default:
+			h.alertRulePresenter.InternalServerError(c, "Failed to update alert rule", err)
+		}
+		return
+	}
+
+	h.alertRulePresenter.RuleUpdated(c, dto.FromEntityToAlertRuleResponse(rule))
+}
+
+// DeleteRule godoc
+// @Summary Delete alert rule
+// @Description Delete an alert rule, its firing alerts are resolved first
+// @Tags alert-rules
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Success 200 {object} domain.APIResponse
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/alert-rules/{id} [delete]
+func (h *AlertRuleController) DeleteRule(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.alertRuleUseCase.DeleteRule(c.Request.Context(), id); err != nil {
+		h.logger.Error("Failed to delete alert rule",
+			zap.Error(err),
+			zap.Uint("id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrAlertRuleNotFound) {
+			h.alertRulePresenter.RuleNotFound(c, "Alert rule not found")
+			return
+		}
+		h.alertRulePresenter.InternalServerError(c, "Failed to delete alert rule", err)
+		return
+	}
+
+	h.alertRulePresenter.RuleDeleted(c)
+}
+
+// ListStates godoc
+// @Summary List alert states
+// @Description List the pending, firing and resolved state of an alert rule per server
+// @Tags alert-rules
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Success 200 {object} domain.APIResponse{data=[]dto.AlertStateResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/alert-rules/{id}/states [get]
+func (h *AlertRuleController) ListStates(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	states, err := h.alertRuleUseCase.ListStates(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to list alert states",
+			zap.Error(err),
+			zap.Uint("id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrAlertRuleNotFound) {
+			h.alertRulePresenter.RuleNotFound(c, "Alert rule not found")
+			return
+		}
+		h.alertRulePresenter.InternalServerError(c, "Failed to list alert states", err)
+		return
+	}
+
+	response := make([]dto.AlertStateResponse, 0, len(states))
+	for _, state := range states {
+		response = append(response, dto.FromEntityToAlertStateResponse(state))
+	}
+
+	h.alertRulePresenter.StatesRetrieved(c, response)
+}
+
+func (h *AlertRuleController) parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse alert rule ID",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.alertRulePresenter.InvalidRequest(c, "Invalid alert rule ID", err)
+		return 0, false
+	}
+	return uint(id), true
+}
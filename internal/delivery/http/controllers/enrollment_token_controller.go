@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/delivery/http/presenters"
+	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+type EnrollmentTokenController struct {
+	enrollmentTokenUseCase   usecases.EnrollmentTokenUseCase
+	enrollmentTokenPresenter presenters.EnrollmentTokenPresenter
+	logger                   *zap.Logger
+}
+
+func NewEnrollmentTokenController(
+	enrollmentTokenUseCase usecases.EnrollmentTokenUseCase,
+	enrollmentTokenPresenter presenters.EnrollmentTokenPresenter,
+	logger *zap.Logger,
+) *EnrollmentTokenController {
+	return &EnrollmentTokenController{
+		enrollmentTokenUseCase:   enrollmentTokenUseCase,
+		enrollmentTokenPresenter: enrollmentTokenPresenter,
+		logger:                   logger,
+	}
+}
+
+// CreateToken godoc
+// @Summary Create enrollment token
+// @Description Create a token that servers use to register themselves. The plain token is only returned in this response.
+// @Tags enrollment-tokens
+// @Accept json
+// @Produce json
+// @Param token body dto.CreateEnrollmentTokenRequest true "Enrollment token"
+// @Success 201 {object} domain.APIResponse{data=dto.CreateEnrollmentTokenResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/enrollment-tokens [post]
+func (h *EnrollmentTokenController) CreateToken(c *gin.Context) {
+	var req dto.CreateEnrollmentTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid create enrollment token request", zap.Error(err))
+		h.enrollmentTokenPresenter.InvalidRequest(c, "Invalid request data", err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+
+	response, err := h.enrollmentTokenUseCase.CreateToken(c.Request.Context(), req, userID)
+	if err != nil {
+		h.logger.Error("Failed to create enrollment token",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrInvalidInput) {
+			h.enrollmentTokenPresenter.InvalidRequest(c, "Invalid request data", err)
+			return
+		}
+		h.enrollmentTokenPresenter.InternalServerError(c, "Failed to create enrollment token", err)
+		return
+	}
+
+	h.enrollmentTokenPresenter.TokenCreated(c, response)
+}
+
+// ListTokens godoc
+// @Summary List enrollment tokens
+// @Description List enrollment tokens with their usage
+// @Tags enrollment-tokens
+// @Produce json
+// @Success 200 {object} domain.APIResponse{data=[]dto.EnrollmentTokenResponse}
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/enrollment-tokens [get]
+func (h *EnrollmentTokenController) ListTokens(c *gin.Context) {
+	tokens, err := h.enrollmentTokenUseCase.ListTokens(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list enrollment tokens",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		h.enrollmentTokenPresenter.InternalServerError(c, "Failed to list enrollment tokens", err)
+		return
+	}
+
+	response := make([]dto.EnrollmentTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		response = append(response, dto.FromEntityToEnrollmentTokenResponse(token))
+	}
+
+	h.enrollmentTokenPresenter.TokensRetrieved(c, response)
+}
+
+// RevokeToken godoc
+// @Summary Revoke enrollment token
+// @Description Revoke an enrollment token so it can no longer be used to register servers
+// @Tags enrollment-tokens
+// @Produce json
+// @Param id path int true "Enrollment token ID"
+// @Success 200 {object} domain.APIResponse
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/enrollment-tokens/{id} [delete]
+func (h *EnrollmentTokenController) RevokeToken(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse enrollment token ID",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.enrollmentTokenPresenter.InvalidRequest(c, "Invalid enrollment token ID", err)
+		return
+	}
+
+	if err := h.enrollmentTokenUseCase.RevokeToken(c.Request.Context(), uint(id)); err != nil {
+		h.logger.Error("Failed to revoke enrollment token",
+			zap.Error(err),
+			zap.Uint64("id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrEnrollmentTokenNotFound) {
+			h.enrollmentTokenPresenter.TokenNotFound(c, "Enrollment token not found")
+			return
+		}
+		h.enrollmentTokenPresenter.InternalServerError(c, "Failed to revoke enrollment token", err)
+		return
+	}
+
+	h.enrollmentTokenPresenter.TokenRevoked(c)
+}
@@ -42,7 +42,7 @@ func (jc *JobsController) GetJobs(c *gin.Context) {
 
 // GetJobStatus godoc
 // @Summary Get job scheduler status (monitoring only)
-// @Description Get the current status of the background job scheduler
+// @Description Get the current status of the background job scheduler and of the leader election. Tasks only run on the leader, the other instances report them as standby.
 // @Tags jobs
 // @Security BearerAuth
 // @Produce json
@@ -54,8 +54,11 @@ func (jc *JobsController) GetJobStatus(c *gin.Context) {
 	isRunning := jc.jobManager.GetScheduler().IsRunning()
 	tasks := jc.jobManager.GetScheduler().GetTasks()
 
+	leader := jc.jobManager.GetLeaderElector().Status(c.Request.Context())
+
 	status := map[string]interface{}{
 		"scheduler_running": isRunning,
+		"leader_election":   leader,
 		"total_tasks":       len(tasks),
 		"tasks":             tasks,
 	}
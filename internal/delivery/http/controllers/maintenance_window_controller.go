@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/delivery/http/presenters"
+	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+type MaintenanceWindowController struct {
+	maintenanceWindowUseCase   usecases.MaintenanceWindowUseCase
+	maintenanceWindowPresenter presenters.MaintenanceWindowPresenter
+	logger                     *zap.Logger
+}
+
+func NewMaintenanceWindowController(
+	maintenanceWindowUseCase usecases.MaintenanceWindowUseCase,
+	maintenanceWindowPresenter presenters.MaintenanceWindowPresenter,
+	logger *zap.Logger,
+) *MaintenanceWindowController {
+	return &MaintenanceWindowController{
+		maintenanceWindowUseCase:   maintenanceWindowUseCase,
+		maintenanceWindowPresenter: maintenanceWindowPresenter,
+		logger:                     logger,
+	}
+}
+
+// CreateWindow godoc
+// @Summary Create maintenance window
+// @Description Create a one-off (starts_at, ends_at) or recurring (cron schedule, duration) maintenance window for a server, a location and/or tags. Servers in scope go into MAINTENANCE while it is active.
+// @Tags maintenance-windows
+// @Accept json
+// @Produce json
+// @Param window body dto.CreateMaintenanceWindowRequest true "Maintenance window"
+// @Success 201 {object} domain.APIResponse{data=dto.MaintenanceWindowResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/maintenance-windows [post]
+func (h *MaintenanceWindowController) CreateWindow(c *gin.Context) {
+	var req dto.CreateMaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid create maintenance window request", zap.Error(err))
+		h.maintenanceWindowPresenter.InvalidRequest(c, "Invalid request data", err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+
+	window, err := h.maintenanceWindowUseCase.CreateWindow(c.Request.Context(), req, userID)
+	if err != nil {
+		h.logger.Error("Failed to create maintenance window",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrInvalidInput) {
+			h.maintenanceWindowPresenter.InvalidRequest(c, "Invalid request data", err)
+			return
+		}
+		h.maintenanceWindowPresenter.InternalServerError(c, "Failed to create maintenance window", err)
+		return
+	}
+
+	h.maintenanceWindowPresenter.WindowCreated(c, h.toResponse(window))
+}
+
+// ListWindows godoc
+// @Summary List maintenance windows
+// @Description List every maintenance window and whether it is active
+// @Tags maintenance-windows
+// @Produce json
+// @Success 200 {object} domain.APIResponse{data=[]dto.MaintenanceWindowResponse}
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/maintenance-windows [get]
+func (h *MaintenanceWindowController) ListWindows(c *gin.Context) {
+	windows, err := h.maintenanceWindowUseCase.ListWindows(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list maintenance windows",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		h.maintenanceWindowPresenter.InternalServerError(c, "Failed to list maintenance windows", err)
+		return
+	}
+
+	response := make([]dto.MaintenanceWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		response = append(response, h.toResponse(window))
+	}
+
+	h.maintenanceWindowPresenter.WindowsRetrieved(c, response)
+}
+
+// GetWindow godoc
+// @Summary Get maintenance window
+// @Description Get a maintenance window by ID
+// @Tags maintenance-windows
+// @Produce json
+// @Param id path int true "Maintenance window ID"
+// @Success 200 {object} domain.APIResponse{data=dto.MaintenanceWindowResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/maintenance-windows/{id} [get]
+func (h *MaintenanceWindowController) GetWindow(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	window, err := h.maintenanceWindowUseCase.GetWindow(c.Request.Context(), id)
+	if err != nil {
+		h.maintenanceWindowPresenter.WindowNotFound(c, "Maintenance window not found")
+		return
+	}
+
+	h.maintenanceWindowPresenter.WindowRetrieved(c, h.toResponse(window))
+}
+
+// UpdateWindow godoc
+// @Summary Update maintenance window
+// @Description Update a maintenance window. Servers it no longer covers leave maintenance on the next apply tick.
+// @Tags maintenance-windows
+// @Accept json
+// @Produce json
+// @Param id path int true "Maintenance window ID"
+// @Param window body dto.UpdateMaintenanceWindowRequest true "Maintenance window changes"
+// @Success 200 {object} domain.APIResponse{data=dto.MaintenanceWindowResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/maintenance-windows/{id} [put]
+func (h *MaintenanceWindowController) UpdateWindow(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateMaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid update maintenance window request", zap.Error(err))
+		h.maintenanceWindowPresenter.InvalidRequest(c, "Invalid request data", err)
+		return
+	}
+
+	window, err := h.maintenanceWindowUseCase.UpdateWindow(c.Request.Context(), id, req)
+	if err != nil {
+		h.logger.Error("Failed to update maintenance window",
+			zap.Error(err),
+			zap.Uint("id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		switch {
+		case errors.Is(err, domainerrors.ErrMaintenanceWindowNotFound):
+			h.maintenanceWindowPresenter.WindowNotFound(c, "Maintenance window not found")
+		case errors.Is(err, domainerrors.ErrInvalidInput):
+			h.maintenanceWindowPresenter.InvalidRequest(c, "Invalid request data", err)
+		default:
+			h.maintenanceWindowPresenter.InternalServerError(c, "Failed to update maintenance window", err)
+		}
+		return
+	}
+
+	h.maintenanceWindowPresenter.WindowUpdated(c, h.toResponse(window))
+}
+
+// DeleteWindow godoc
+// @Summary Delete maintenance window
+// @Description Delete a maintenance window. Servers it holds leave maintenance on the next apply tick.
+// @Tags maintenance-windows
+// @Produce json
+// @Param id path int true "Maintenance window ID"
+// @Success 200 {object} domain.APIResponse
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/maintenance-windows/{id} [delete]
+func (h *MaintenanceWindowController) DeleteWindow(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.maintenanceWindowUseCase.DeleteWindow(c.Request.Context(), id); err != nil {
+		h.logger.Error("Failed to delete maintenance window",
+			zap.Error(err),
+			zap.Uint("id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrMaintenanceWindowNotFound) {
+			h.maintenanceWindowPresenter.WindowNotFound(c, "Maintenance window not found")
+			return
+		}
+		h.maintenanceWindowPresenter.InternalServerError(c, "Failed to delete maintenance window", err)
+		return
+	}
+
+	h.maintenanceWindowPresenter.WindowDeleted(c)
+}
+
+func (h *MaintenanceWindowController) toResponse(window *entity.MaintenanceWindow) dto.MaintenanceWindowResponse {
+	return dto.FromEntityToMaintenanceWindowResponse(window, h.maintenanceWindowUseCase.IsActive(window, time.Now()))
+}
+
+func (h *MaintenanceWindowController) parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse maintenance window ID",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.maintenanceWindowPresenter.InvalidRequest(c, "Invalid maintenance window ID", err)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/delivery/http/presenters"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+type ProbeController struct {
+	probeUseCase   usecases.ProbeUseCase
+	probePresenter presenters.ProbePresenter
+	logger         *zap.Logger
+}
+
+func NewProbeController(
+	probeUseCase usecases.ProbeUseCase,
+	probePresenter presenters.ProbePresenter,
+	logger *zap.Logger,
+) *ProbeController {
+	return &ProbeController{
+		probeUseCase:   probeUseCase,
+		probePresenter: probePresenter,
+		logger:         logger,
+	}
+}
+
+// ListProbes godoc
+// @Summary List server probes
+// @Description List the probes of a server with the result of their last run
+// @Tags probes
+// @Produce json
+// @Param id path int true "Server ID"
+// @Success 200 {object} domain.APIResponse{data=[]dto.ProbeResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/probes [get]
+func (h *ProbeController) ListProbes(c *gin.Context) {
+	serverID, ok := h.parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	probes, err := h.probeUseCase.ListProbes(c.Request.Context(), serverID)
+	if err != nil {
+		h.logger.Error("Failed to list probes",
+			zap.Error(err),
+			zap.Uint("server_id", serverID),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrServerNotFound) {
+			h.probePresenter.NotFound(c, "Server not found")
+			return
+		}
+		h.probePresenter.InternalServerError(c, "Failed to list probes", err)
+		return
+	}
+
+	h.probePresenter.ProbesRetrieved(c, dto.FromEntitiesToProbeResponses(probes))
+}
+
+// CreateProbe godoc
+// @Summary Create server probe
+// @Description Probe a server without an agent: tcp connects to the port on the server IPv4, http sends a GET to the url and checks expected_status (200 by default) and the body_match regexp. Probes run every interval_time of the server; the server is ON while all of them succeed.
+// @Tags probes
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Param probe body dto.CreateProbeRequest true "Probe"
+// @Success 201 {object} domain.APIResponse{data=dto.ProbeResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/probes [post]
+func (h *ProbeController) CreateProbe(c *gin.Context) {
+	serverID, ok := h.parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateProbeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid create probe request", zap.Error(err))
+		h.probePresenter.InvalidRequest(c, "Invalid request data", err)
+		return
+	}
+
+	probe, err := h.probeUseCase.CreateProbe(c.Request.Context(), serverID, req)
+	if err != nil {
+		h.logger.Error("Failed to create probe",
+			zap.Error(err),
+			zap.Uint("server_id", serverID),
+			zap.String("request_id", c.GetString("request_id")))
+		switch {
+		case errors.Is(err, domainerrors.ErrServerNotFound):
+			h.probePresenter.NotFound(c, "Server not found")
+		case errors.Is(err, domainerrors.ErrInvalidInput):
+			h.probePresenter.InvalidRequest(c, "Invalid request data", err)
+		default:
+			h.probePresenter.InternalServerError(c, "Failed to create probe", err)
+		}
+		return
+	}
+
+	h.probePresenter.ProbeCreated(c, dto.FromEntityToProbeResponse(probe))
+}
+
+// UpdateProbe godoc
+// @Summary Update server probe
+// @Description Update a probe of a server, its type cannot change
+// @Tags probes
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Param probe_id path int true "Probe ID"
+// @Param probe body dto.UpdateProbeRequest true "Probe changes"
+// @Success 200 {object} domain.APIResponse{data=dto.ProbeResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/probes/{probe_id} [put]
+func (h *ProbeController) UpdateProbe(c *gin.Context) {
+	serverID, ok := h.parseID(c, "id")
+	if !ok {
+		return
+	}
+	probeID, ok := h.parseID(c, "probe_id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateProbeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid update probe request", zap.Error(err))
+		h.probePresenter.InvalidRequest(c, "Invalid request data", err)
+		return
+	}
+
+	probe, err := h.probeUseCase.UpdateProbe(c.Request.Context(), serverID, probeID, req)
+	if err != nil {
+		h.logger.Error("Failed to update probe",
+			zap.Error(err),
+			zap.Uint("probe_id", probeID),
+			zap.String("request_id", c.GetString("request_id")))
+		switch {
+		case errors.Is(err, domainerrors.ErrProbeNotFound):
+			h.probePresenter.NotFound(c, "Probe not found")
+		case errors.Is(err, domainerrors.ErrInvalidInput):
+			h.probePresenter.InvalidRequest(c, "Invalid request data", err)
+		default:
+			h.probePresenter.InternalServerError(c, "Failed to update probe", err)
+		}
+		return
+	}
+
+	h.probePresenter.ProbeUpdated(c, dto.FromEntityToProbeResponse(probe))
+}
+
+// DeleteProbe godoc
+// @Summary Delete server probe
+// @Description Delete a probe of a server
+// @Tags probes
+// @Produce json
+// @Param id path int true "Server ID"
+// @Param probe_id path int true "Probe ID"
+// @Success 200 {object} domain.APIResponse
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/probes/{probe_id} [delete]
+func (h *ProbeController) DeleteProbe(c *gin.Context) {
+	serverID, ok := h.parseID(c, "id")
+	if !ok {
+		return
+	}
+	probeID, ok := h.parseID(c, "probe_id")
+	if !ok {
+		return
+	}
+
+	if err := h.probeUseCase.DeleteProbe(c.Request.Context(), serverID, probeID); err != nil {
+		h.logger.Error("Failed to delete probe",
+			zap.Error(err),
+			zap.Uint("probe_id", probeID),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrProbeNotFound) {
+			h.probePresenter.NotFound(c, "Probe not found")
+			return
+		}
+		h.probePresenter.InternalServerError(c, "Failed to delete probe", err)
+		return
+	}
+
+	h.probePresenter.ProbeDeleted(c)
+}
+
+func (h *ProbeController) parseID(c *gin.Context, param string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse ID",
+			zap.Error(err),
+			zap.String(param, c.Param(param)),
+			zap.String("request_id", c.GetString("request_id")))
+		h.probePresenter.InvalidRequest(c, "Invalid ID", err)
+		return 0, false
+	}
+	return uint(id), true
+}
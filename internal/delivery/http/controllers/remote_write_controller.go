@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/delivery/http/presenters"
+	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+const (
+	// Compressed size of a remote write request
+	maxRemoteWriteBodyBytes = 8 << 20
+	// Decompressed size of a remote write request, unless configured
+	defaultMaxRemoteWriteDecodedBytes = 32 << 20
+)
+
+type RemoteWriteController struct {
+	remoteWriteUseCase   usecases.RemoteWriteUseCase
+	remoteWritePresenter presenters.RemoteWritePresenter
+	maxDecodedBytes      int
+	logger               *zap.Logger
+}
+
+func NewRemoteWriteController(
+	remoteWriteUseCase usecases.RemoteWriteUseCase,
+	remoteWritePresenter presenters.RemoteWritePresenter,
+	config configs.RemoteWrite,
+	logger *zap.Logger,
+) *RemoteWriteController {
+	maxDecodedBytes := config.MaxDecodedBytes
+	if maxDecodedBytes <= 0 {
+		maxDecodedBytes = defaultMaxRemoteWriteDecodedBytes
+	}
+	return &RemoteWriteController{
+		remoteWriteUseCase:   remoteWriteUseCase,
+		remoteWritePresenter: remoteWritePresenter,
+		maxDecodedBytes:      maxDecodedBytes,
+		logger:               logger,
+	}
+}
+
+// Write godoc
+// @Summary Prometheus remote write
+// @Description Receive node_exporter series from a Prometheus or Grafana Agent remote_write (snappy compressed protobuf). Series are mapped to servers by the configured server label (instance by default) and ingested like agent metrics. A server access token may only write its own series; a shared remote write token may write any server.
+// @Tags metrics
+// @Accept application/x-protobuf
+// @Produce json
+// @Success 200 {object} domain.APIResponse{data=dto.RemoteWriteResult}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 401 {object} domain.APIResponse
+// @Failure 413 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/metrics/remote-write [post]
+func (h *RemoteWriteController) Write(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRemoteWriteBodyBytes))
+	if err != nil {
+		h.logger.Warn("Failed to read remote write request", zap.Error(err))
+		h.remoteWritePresenter.InvalidRequest(c, "Failed to read request body", err)
+		return
+	}
+
+	series, err := decodeRemoteWrite(body, h.maxDecodedBytes)
+	if err != nil {
+		h.logger.Warn("Invalid remote write request", zap.Error(err))
+		if errors.Is(err, errWriteRequestTooLarge) {
+			h.remoteWritePresenter.RequestTooLarge(c, "Remote write request too large", err)
+			return
+		}
+		h.remoteWritePresenter.InvalidRequest(c, "Invalid remote write request", err)
+		return
+	}
+
+	result, err := h.remoteWriteUseCase.Ingest(c.Request.Context(), series, middleware.GetRemoteWriteServerID(c))
+	if err != nil {
+		h.logger.Error("Failed to ingest remote write request",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		h.remoteWritePresenter.InternalServerError(c, "Failed to ingest remote write request", err)
+		return
+	}
+	if len(result.UnknownServers) > 0 {
+		h.logger.Warn("Remote write series for unknown servers",
+			zap.Strings("server_ids", result.UnknownServers))
+	}
+
+	h.remoteWritePresenter.RemoteWriteAccepted(c, result)
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/golang/snappy"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the prometheus.WriteRequest protobuf messages
+const (
+	writeRequestTimeseriesField = 1
+	timeSeriesLabelsField       = 1
+	timeSeriesSamplesField      = 2
+	labelNameField              = 1
+	labelValueField             = 2
+	sampleValueField            = 1
+	sampleTimestampField        = 2
+)
+
+var (
+	errMalformedWriteRequest = errors.New("malformed remote write request")
+	errWriteRequestTooLarge  = errors.New("remote write request too large")
+)
+
+// decodeRemoteWrite decodes a snappy compressed prometheus.WriteRequest.
+// Metadata, exemplars and histograms are skipped. The decompressed size is
+// read from the snappy header and checked against maxDecodedBytes before
+// anything is allocated.
+func decodeRemoteWrite(body []byte, maxDecodedBytes int) ([]dto.PrometheusTimeSeries, error) {
+	decodedLen, err := snappy.DecodedLen(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress remote write request: %w", err)
+	}
+	if decodedLen > maxDecodedBytes {
+		return nil, fmt.Errorf("%w: %d bytes decompressed", errWriteRequestTooLarge, decodedLen)
+	}
+
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress remote write request: %w", err)
+	}
+
+	var series []dto.PrometheusTimeSeries
+	err = walkFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != writeRequestTimeseriesField || typ != protowire.BytesType {
+			return nil
+		}
+		ts, err := decodeTimeSeries(value)
+		if err != nil {
+			return err
+		}
+		series = append(series, ts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func decodeTimeSeries(data []byte) (dto.PrometheusTimeSeries, error) {
+	ts := dto.PrometheusTimeSeries{Labels: make(map[string]string)}
+	err := walkFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case timeSeriesLabelsField:
+			var name, labelValue string
+			err := walkFields(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+				if typ != protowire.BytesType {
+					return nil
+				}
+				switch num {
+				case labelNameField:
+					name = string(value)
+				case labelValueField:
+					labelValue = string(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Labels[name] = labelValue
+		case timeSeriesSamplesField:
+			var sample dto.PrometheusSample
+			err := walkFields(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+				switch {
+				case num == sampleValueField && typ == protowire.Fixed64Type:
+					bits, _ := protowire.ConsumeFixed64(value)
+					sample.Value = math.Float64frombits(bits)
+				case num == sampleTimestampField && typ == protowire.VarintType:
+					timestamp, _ := protowire.ConsumeVarint(value)
+					sample.Timestamp = int64(timestamp)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, sample)
+		}
+		return nil
+	})
+	return ts, err
+}
+
+// walkFields calls fn for each field of a protobuf message. For BytesType the
+// value is the payload, for the other types the raw encoded value.
+func walkFields(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return errMalformedWriteRequest
+		}
+		data = data[n:]
+
+		var value []byte
+		if typ == protowire.BytesType {
+			payload, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				return errMalformedWriteRequest
+			}
+			value, n = payload, m
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return errMalformedWriteRequest
+			}
+			value = data[:n]
+		}
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
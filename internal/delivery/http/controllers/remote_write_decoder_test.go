@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The payloads are built field by field with the layout of
+// prometheus.WriteRequest, the way a Prometheus server encodes them.
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func encodeLabel(name, value string) []byte {
+	var b []byte
+	b = appendMessage(b, labelNameField, []byte(name))
+	return appendMessage(b, labelValueField, []byte(value))
+}
+
+func encodeSample(value float64, timestamp int64) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, sampleValueField, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(value))
+	b = protowire.AppendTag(b, sampleTimestampField, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(timestamp))
+}
+
+func encodeTimeSeries(ts dto.PrometheusTimeSeries, names ...string) []byte {
+	var b []byte
+	for _, name := range names {
+		b = appendMessage(b, timeSeriesLabelsField, encodeLabel(name, ts.Labels[name]))
+	}
+	for _, sample := range ts.Samples {
+		b = appendMessage(b, timeSeriesSamplesField, encodeSample(sample.Value, sample.Timestamp))
+	}
+	return b
+}
+
+func TestDecodeRemoteWrite(t *testing.T) {
+	load := dto.PrometheusTimeSeries{
+		Labels: map[string]string{"__name__": "node_load1", "instance": "server-01:9100"},
+		Samples: []dto.PrometheusSample{
+			{Value: 0.25, Timestamp: 1700000000000},
+			{Value: 0.5, Timestamp: 1700000015000},
+		},
+	}
+	boot := dto.PrometheusTimeSeries{
+		Labels:  map[string]string{"__name__": "node_boot_time_seconds", "instance": "server-02:9100"},
+		Samples: []dto.PrometheusSample{{Value: 1699990000, Timestamp: 1700000000000}},
+	}
+
+	var valid []byte
+	valid = appendMessage(valid, writeRequestTimeseriesField, encodeTimeSeries(load, "__name__", "instance"))
+	valid = appendMessage(valid, writeRequestTimeseriesField, encodeTimeSeries(boot, "__name__", "instance"))
+
+	// Metadata (field 3) and unknown scalar fields are skipped
+	var withMetadata []byte
+	withMetadata = appendMessage(withMetadata, writeRequestTimeseriesField, encodeTimeSeries(boot, "__name__", "instance"))
+	withMetadata = appendMessage(withMetadata, 3, encodeLabel("metric_family_name", "node_boot_time_seconds"))
+	withMetadata = protowire.AppendTag(withMetadata, 15, protowire.VarintType)
+	withMetadata = protowire.AppendVarint(withMetadata, 1)
+
+	// A varint whose continuation bit is set on the last byte
+	truncatedVarint := protowire.AppendTag(nil, writeRequestTimeseriesField, protowire.BytesType)
+	truncatedVarint = append(truncatedVarint, 0x80, 0x80)
+
+	// A length prefix pointing past the end of the message
+	oversizedLength := protowire.AppendTag(nil, writeRequestTimeseriesField, protowire.BytesType)
+	oversizedLength = protowire.AppendVarint(oversizedLength, 1<<20)
+	oversizedLength = append(oversizedLength, encodeTimeSeries(boot, "__name__")...)
+
+	// The same inside a nested sample
+	nestedOversized := appendMessage(nil, timeSeriesSamplesField, protowire.AppendVarint(
+		protowire.AppendTag(nil, sampleValueField, protowire.BytesType), 64))
+	nestedOversized = appendMessage(nil, writeRequestTimeseriesField, nestedOversized)
+
+	tests := []struct {
+		name    string
+		body    []byte
+		max     int
+		want    []dto.PrometheusTimeSeries
+		wantErr error
+		invalid bool
+	}{
+		{
+			name: "valid request",
+			body: snappy.Encode(nil, valid),
+			max:  1 << 20,
+			want: []dto.PrometheusTimeSeries{load, boot},
+		},
+		{
+			name: "empty request",
+			body: snappy.Encode(nil, nil),
+			max:  1 << 20,
+		},
+		{
+			name: "metadata and unknown fields",
+			body: snappy.Encode(nil, withMetadata),
+			max:  1 << 20,
+			want: []dto.PrometheusTimeSeries{boot},
+		},
+		{
+			name:    "truncated varint",
+			body:    snappy.Encode(nil, truncatedVarint),
+			max:     1 << 20,
+			wantErr: errMalformedWriteRequest,
+		},
+		{
+			name:    "oversized length",
+			body:    snappy.Encode(nil, oversizedLength),
+			max:     1 << 20,
+			wantErr: errMalformedWriteRequest,
+		},
+		{
+			name:    "oversized nested length",
+			body:    snappy.Encode(nil, nestedOversized),
+			max:     1 << 20,
+			wantErr: errMalformedWriteRequest,
+		},
+		{
+			name:    "decoded size over the limit",
+			body:    snappy.Encode(nil, valid),
+			max:     len(valid) - 1,
+			wantErr: errWriteRequestTooLarge,
+		},
+		{
+			// The header claims 4 GiB but the body is a few bytes
+			name:    "decompression bomb header",
+			body:    protowire.AppendVarint(nil, 1<<32-1),
+			max:     32 << 20,
+			wantErr: errWriteRequestTooLarge,
+		},
+		{
+			name:    "not snappy",
+			body:    []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			max:     1 << 20,
+			invalid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeRemoteWrite(tt.body, tt.max)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+				return
+			}
+			if tt.invalid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,20 +1,32 @@
 package controllers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"github.com/th1enq/server_management_system/internal/delivery/http/presenters"
+	"github.com/th1enq/server_management_system/internal/delivery/middleware"
 	"github.com/th1enq/server_management_system/internal/domain"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
 	"github.com/th1enq/server_management_system/internal/dto"
 	"github.com/th1enq/server_management_system/internal/usecases"
 	"go.uber.org/zap"
 )
 
+const (
+	maxMetricsBatchSize  = 1000
+	maxMetricsBatchBytes = 8 << 20
+)
+
 type ServerController struct {
 	serverUseCase   usecases.ServerUseCase
 	serverPresenter presenters.ServerPresenter
@@ -35,13 +47,15 @@ func NewServerController(
 
 // Monitoring godoc
 // @Summary Send server monitoring data
-// @Description Send server monitoring data to the system
+// @Description Send server monitoring data to the system. Samples without schema_version are version 1 (cpu/ram/disk only), version 2 adds load, per-core CPU, mounts, network, processes, uptime and custom gauges.
 // @Tags servers
 // @Accept json
 // @Produce json
 // @Param monitoring body dto.MetricsRequest true "Monitoring data"
-// @Success 200 {object} domain.APIResponse
+// @Success 200 {object} domain.APIResponse{data=dto.MonitoringResponse}
 // @Failure 400 {object} domain.APIResponse
+// @Failure 401 {object} domain.APIResponse
+// @Failure 403 {object} domain.APIResponse
 // @Failure 500 {object} domain.APIResponse
 // @Security BearerAuth
 // @Router /api/v1/servers/monitoring [post]
@@ -53,6 +67,21 @@ func (h *ServerController) Monitoring(c *gin.Context) {
 		return
 	}
 
+	serverID, ok := middleware.GetAgentServerID(c)
+	if !ok {
+		h.serverPresenter.Unauthorized(c, "Authentication required")
+		return
+	}
+	if req.ServerID != serverID {
+		h.logger.Warn("Monitoring data does not match token subject",
+			zap.String("server_id", req.ServerID),
+			zap.String("token_server_id", serverID),
+			zap.String("request_id", c.GetString("request_id")),
+		)
+		h.serverPresenter.Forbidden(c, "Token is not valid for this server")
+		return
+	}
+
 	if err := h.serverUseCase.ProcessMetrics(c.Request.Context(), req); err != nil {
 		h.logger.Error("Failed to process server metrics through API Gateway",
 			zap.Error(err),
@@ -62,22 +91,175 @@ func (h *ServerController) Monitoring(c *gin.Context) {
 			zap.Int("disk", req.Disk),
 			zap.String("request_id", c.GetString("request_id")),
 		)
+		if errors.Is(err, domainerrors.ErrServerPendingApproval) {
+			h.serverPresenter.Forbidden(c, "Server is pending approval")
+			return
+		}
+		if errors.Is(err, domainerrors.ErrInvalidMetrics) || errors.Is(err, domainerrors.ErrFutureTimestamp) {
+			h.serverPresenter.ValidationError(c, "Failed to process server metrics", err)
+			return
+		}
 		h.serverPresenter.InternalServerError(c, "Failed to process server metrics", err)
 		return
 	}
 
-	h.serverPresenter.MonitoringSuccess(c, "Server metrics processed successfully")
+	h.serverPresenter.MonitoringSuccess(c, "Server metrics processed successfully", &dto.MonitoringResponse{
+		Config: h.agentConfig(c, req.ServerID),
+	})
+}
+
+// agentConfig returns the config piggybacked on heartbeat responses. A lookup
+// failure must not fail the heartbeat, the agent keeps its current config.
+func (h *ServerController) agentConfig(c *gin.Context, serverID string) *dto.AgentConfig {
+	config, err := h.serverUseCase.GetAgentConfig(c.Request.Context(), serverID)
+	if err != nil {
+		h.logger.Warn("Failed to get agent config",
+			zap.Error(err),
+			zap.String("server_id", serverID),
+			zap.String("request_id", c.GetString("request_id")))
+		return nil
+	}
+	return config
+}
+
+// MonitoringBatch godoc
+// @Summary Send buffered server monitoring data
+// @Description Send a batch of monitoring samples as a JSON array or as NDJSON (one sample per line). Each sample is validated and processed on its own. Samples older than the heartbeat window are added to the history without changing the current status.
+// @Tags servers
+// @Accept json
+// @Accept x-ndjson
+// @Produce json
+// @Param monitoring body []dto.MetricsRequest true "Monitoring samples"
+// @Success 200 {object} domain.APIResponse{data=dto.MetricsBatchResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 401 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/monitoring/batch [post]
+func (h *ServerController) MonitoringBatch(c *gin.Context) {
+	serverID, ok := middleware.GetAgentServerID(c)
+	if !ok {
+		h.serverPresenter.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxMetricsBatchBytes))
+	if err != nil {
+		h.logger.Warn("Failed to read monitoring batch", zap.Error(err))
+		h.serverPresenter.InvalidRequest(c, "Invalid request data", err)
+		return
+	}
+
+	items, err := decodeMetricsBatch(body)
+	if err != nil {
+		h.logger.Warn("Invalid monitoring batch", zap.Error(err))
+		h.serverPresenter.InvalidRequest(c, "Invalid request data", err)
+		return
+	}
+	if len(items) == 0 || len(items) > maxMetricsBatchSize {
+		h.serverPresenter.InvalidRequest(c, "Invalid request data",
+			fmt.Errorf("batch must contain between 1 and %d samples", maxMetricsBatchSize))
+		return
+	}
+
+	results := make([]dto.MetricsBatchItemResult, len(items))
+	samples := make([]dto.MetricsRequest, 0, len(items))
+	positions := make([]int, 0, len(items))
+	for i, item := range items {
+		results[i].Index = i
+		var sample dto.MetricsRequest
+		if err := json.Unmarshal(item, &sample); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].ServerID = sample.ServerID
+		if err := binding.Validator.ValidateStruct(&sample); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if sample.ServerID != serverID {
+			results[i].Error = "token is not valid for this server"
+			continue
+		}
+		samples = append(samples, sample)
+		positions = append(positions, i)
+	}
+
+	for j, err := range h.serverUseCase.ProcessMetricsBatch(c.Request.Context(), samples) {
+		if err != nil {
+			results[positions[j]].Error = err.Error()
+			results[positions[j]].Retryable = retryableMetricsError(err)
+		}
+	}
+
+	response := &dto.MetricsBatchResponse{Results: results, Config: h.agentConfig(c, serverID)}
+	for i := range results {
+		if results[i].Error != "" {
+			results[i].Status = dto.MetricsBatchItemRejected
+			response.Rejected++
+		} else {
+			results[i].Status = dto.MetricsBatchItemAccepted
+			response.Accepted++
+		}
+	}
+
+	h.logger.Info("Processed monitoring batch",
+		zap.String("server_id", serverID),
+		zap.Int("accepted", response.Accepted),
+		zap.Int("rejected", response.Rejected),
+		zap.String("request_id", c.GetString("request_id")),
+	)
+
+	h.serverPresenter.MetricsBatchProcessed(c, response)
+}
+
+// retryableMetricsError reports whether a sample was rejected by a server
+// fault, such as the database being unreachable, rather than for its content
+func retryableMetricsError(err error) bool {
+	for _, permanent := range []error{
+		domainerrors.ErrFutureTimestamp,
+		domainerrors.ErrInvalidMetrics,
+		domainerrors.ErrServerNotFound,
+		domainerrors.ErrServerPendingApproval,
+	} {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeMetricsBatch splits a JSON array or an NDJSON body into raw samples
+func decodeMetricsBatch(body []byte) ([]json.RawMessage, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+
+	items := make([]json.RawMessage, 0)
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, json.RawMessage(line))
+	}
+	return items, nil
 }
 
 // Register godoc
 // @Summary Register server metrics
-// @Description Register server metrics with the system
+// @Description Register a server with an enrollment token. Servers enrolled with a token that requires approval stay PENDING_APPROVAL until an admin approves them.
 // @Tags servers
 // @Accept json
 // @Produce json
 // @Param register body dto.RegisterMetricsRequest true "Register metrics request"
-// @Success 201 {object} domain.APIResponse{data=dto.ServerResponse}
+// @Success 201 {object} domain.APIResponse{data=dto.AuthResponse}
 // @Failure 400 {object} domain.APIResponse
+// @Failure 401 {object} domain.APIResponse
 // @Failure 409 {object} domain.APIResponse
 // @Failure 500 {object} domain.APIResponse
 // @Security BearerAuth
@@ -99,12 +281,11 @@ func (h *ServerController) Register(c *gin.Context) {
 		Description:  req.Description,
 		Location:     req.Location,
 		OS:           req.OS,
+		Tags:         req.Tags,
 		IntervalTime: req.IntervalTime,
 	}
 
-	fmt.Println(reqCreate)
-
-	response, err := h.serverUseCase.Register(c.Request.Context(), reqCreate)
+	response, err := h.serverUseCase.Register(c.Request.Context(), req.EnrollmentToken, reqCreate)
 	if err != nil {
 		h.logger.Error("Failed to create server",
 			zap.Error(err),
@@ -112,7 +293,9 @@ func (h *ServerController) Register(c *gin.Context) {
 			zap.String("server_name", req.ServerName),
 			zap.String("request_id", c.GetString("request_id")))
 
-		if err.Error() == "server_id and server_name are required" {
+		if errors.Is(err, domainerrors.ErrInvalidEnrollmentToken) {
+			h.serverPresenter.Unauthorized(c, "Invalid enrollment token")
+		} else if err.Error() == "server_id and server_name are required" {
 			h.serverPresenter.ValidationError(c, "Failed to create server", err)
 		} else if err.Error() == "server is already exists" {
 			h.serverPresenter.ConflictError(c, "Failed to create server", err)
@@ -174,6 +357,8 @@ func (h *ServerController) CreateServer(c *gin.Context) {
 			h.serverPresenter.ValidationError(c, "Failed to create server", err)
 		} else if err.Error() == "server is already exists" {
 			h.serverPresenter.ConflictError(c, "Failed to create server", err)
+		} else if errors.Is(err, domainerrors.ErrInvalidInput) {
+			h.serverPresenter.InvalidRequest(c, "Failed to create server", err)
 		} else {
 			h.serverPresenter.InternalServerError(c, "Failed to create server", err)
 		}
@@ -200,6 +385,10 @@ func (h *ServerController) CreateServer(c *gin.Context) {
 // @Param ipv4 query string false "Filter by IPv4"
 // @Param location query string false "Filter by location"
 // @Param os query string false "Filter by OS"
+// @Param status_changed_after query string false "Status changed at or after (RFC3339)"
+// @Param status_changed_before query string false "Status changed before (RFC3339)"
+// @Param last_seen_after query string false "Last heartbeat at or after (RFC3339)"
+// @Param last_seen_before query string false "Last heartbeat before (RFC3339), includes servers never seen"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(10)
 // @Param sort query string false "Sort field" default(created_time)
@@ -311,21 +500,15 @@ func (h *ServerController) UpdateServer(c *gin.Context) {
 			h.serverPresenter.ServerNotFound(c, "Failed to update server")
 		} else if err.Error() == "server with name is already exists" {
 			h.serverPresenter.ConflictError(c, "Failed to update server", err)
+		} else if errors.Is(err, domainerrors.ErrInvalidInput) {
+			h.serverPresenter.InvalidRequest(c, "Failed to update server", err)
 		} else {
 			h.serverPresenter.InternalServerError(c, "Failed to update server", err)
 		}
 		return
 	}
 
-	response := dto.ServerResponse{
-		ServerID:    server.ServerID,
-		ServerName:  server.ServerName,
-		Status:      server.Status,
-		IPv4:        server.IPv4,
-		Location:    server.Location,
-		OS:          server.OS,
-		Description: server.Description,
-	}
+	response := *dto.FromEntityToServerResponse(server)
 
 	h.logger.Info("Server updated successfully",
 		zap.Uint64("server_id", id),
@@ -400,6 +583,254 @@ func (h *ServerController) DeleteServer(c *gin.Context) {
 	))
 }
 
+// RefreshToken godoc
+// @Summary Refresh server token
+// @Description Rotate server credentials using the server refresh token. A refresh token can only be used once; reusing it revokes every credential of the server.
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "Server refresh token"
+// @Success 200 {object} domain.APIResponse{data=dto.AuthResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 401 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Router /api/v1/servers/token/refresh [post]
+func (h *ServerController) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid server refresh token request", zap.Error(err))
+		h.serverPresenter.InvalidRequest(c, "Invalid request data", err)
+		return
+	}
+
+	response, err := h.serverUseCase.RefreshToken(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Warn("Failed to refresh server token",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		switch {
+		case errors.Is(err, domainerrors.ErrRefreshTokenReused):
+			h.serverPresenter.Unauthorized(c, "Refresh token reused, server credentials revoked")
+		case errors.Is(err, domainerrors.ErrInvalidRefreshToken), errors.Is(err, domainerrors.ErrServerNotFound):
+			h.serverPresenter.Unauthorized(c, "Invalid refresh token")
+		default:
+			h.serverPresenter.InternalServerError(c, "Failed to refresh server token", err)
+		}
+		return
+	}
+
+	h.logger.Info("Server token refreshed successfully",
+		zap.String("request_id", c.GetString("request_id")))
+	h.serverPresenter.TokenRefreshed(c, response)
+}
+
+// ApproveServer godoc
+// @Summary Approve server
+// @Description Approve a server waiting in the enrollment approval queue
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Success 200 {object} domain.APIResponse{data=dto.ServerResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 409 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/approve [post]
+func (h *ServerController) ApproveServer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse server ID for approval",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid server ID", err)
+		return
+	}
+
+	server, err := h.serverUseCase.ApproveServer(c.Request.Context(), uint(id))
+	if err != nil {
+		h.logger.Error("Failed to approve server",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		switch {
+		case errors.Is(err, domainerrors.ErrServerNotFound):
+			h.serverPresenter.ServerNotFound(c, "Server not found")
+		case errors.Is(err, domainerrors.ErrServerNotPendingApproval):
+			h.serverPresenter.ConflictError(c, "Failed to approve server", err)
+		default:
+			h.serverPresenter.InternalServerError(c, "Failed to approve server", err)
+		}
+		return
+	}
+
+	h.logger.Info("Server approved successfully",
+		zap.Uint64("server_id", id),
+		zap.String("user_id", c.GetString("user_id")),
+		zap.String("request_id", c.GetString("request_id")))
+
+	h.serverPresenter.ServerUpdated(c, *dto.FromEntityToServerResponse(server))
+}
+
+// UpdateServerStatus godoc
+// @Summary Set server maintenance
+// @Description Put a server in MAINTENANCE, where heartbeats and their absence leave its status untouched, or take it out with OFF. The next heartbeat of a server taken out of maintenance turns it ON or DEGRADED.
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Param status body dto.UpdateServerStatusRequest true "Status"
+// @Success 200 {object} domain.APIResponse{data=dto.ServerResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 409 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/status [put]
+func (h *ServerController) UpdateServerStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse server ID for status update",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid server ID", err)
+		return
+	}
+
+	var req dto.UpdateServerStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Failed to bind status update request",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	server, err := h.serverUseCase.SetStatus(c.Request.Context(), uint(id), req.Status)
+	if err != nil {
+		h.logger.Error("Failed to update server status",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.String("status", string(req.Status)),
+			zap.String("request_id", c.GetString("request_id")))
+		switch {
+		case errors.Is(err, domainerrors.ErrServerNotFound):
+			h.serverPresenter.ServerNotFound(c, "Server not found")
+		case errors.Is(err, domainerrors.ErrInvalidStatusTransition):
+			h.serverPresenter.ConflictError(c, "Failed to update server status", err)
+		default:
+			h.serverPresenter.InternalServerError(c, "Failed to update server status", err)
+		}
+		return
+	}
+
+	h.logger.Info("Server status updated successfully",
+		zap.Uint64("server_id", id),
+		zap.String("status", string(server.Status)),
+		zap.String("user_id", c.GetString("user_id")),
+		zap.String("request_id", c.GetString("request_id")))
+
+	h.serverPresenter.ServerUpdated(c, *dto.FromEntityToServerResponse(server))
+}
+
+// RejectServer godoc
+// @Summary Reject server
+// @Description Reject a server waiting in the enrollment approval queue. The server is removed and its credentials are revoked.
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Success 200 {object} domain.APIResponse
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 409 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/reject [post]
+func (h *ServerController) RejectServer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse server ID for rejection",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid server ID", err)
+		return
+	}
+
+	if err := h.serverUseCase.RejectServer(c.Request.Context(), uint(id)); err != nil {
+		h.logger.Error("Failed to reject server",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		switch {
+		case errors.Is(err, domainerrors.ErrServerNotFound):
+			h.serverPresenter.ServerNotFound(c, "Server not found")
+		case errors.Is(err, domainerrors.ErrServerNotPendingApproval):
+			h.serverPresenter.ConflictError(c, "Failed to reject server", err)
+		default:
+			h.serverPresenter.InternalServerError(c, "Failed to reject server", err)
+		}
+		return
+	}
+
+	h.logger.Info("Server rejected successfully",
+		zap.Uint64("server_id", id),
+		zap.String("user_id", c.GetString("user_id")),
+		zap.String("request_id", c.GetString("request_id")))
+
+	h.serverPresenter.ServerDeleted(c)
+}
+
+// RevokeCredentials godoc
+// @Summary Revoke server credentials
+// @Description Revoke every access and refresh token issued to a server
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Success 200 {object} domain.APIResponse
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/revoke [post]
+func (h *ServerController) RevokeCredentials(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse server ID for credential revocation",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid server ID", err)
+		return
+	}
+
+	if err := h.serverUseCase.RevokeCredentials(c.Request.Context(), uint(id)); err != nil {
+		h.logger.Error("Failed to revoke server credentials",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrServerNotFound) {
+			h.serverPresenter.ServerNotFound(c, "Server not found")
+			return
+		}
+		h.serverPresenter.InternalServerError(c, "Failed to revoke server credentials", err)
+		return
+	}
+
+	h.logger.Info("Server credentials revoked successfully",
+		zap.Uint64("server_id", id),
+		zap.String("user_id", c.GetString("user_id")),
+		zap.String("request_id", c.GetString("request_id")))
+
+	h.serverPresenter.CredentialsRevoked(c)
+}
+
 // ImportServers godoc
 // @Summary Import servers from Excel file
 // @Description Import multiple servers from an Excel file
@@ -571,3 +1002,329 @@ func (h *ServerController) ExportServers(c *gin.Context) {
 	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
 	c.File(filePath)
 }
+
+// GetServerMetrics godoc
+// @Summary Get server metrics history
+// @Description Get CPU/RAM/Disk history of a server aggregated (avg/min/max) per step. Extended metrics are aggregated over the schema version 2 samples of each step.
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Param from query string false "Range start (RFC3339), defaults to one hour before to"
+// @Param to query string false "Range end (RFC3339), defaults to now"
+// @Param step query string false "Bucket size as a Go duration" default(1m)
+// @Param gauges query []string false "Custom gauges to aggregate" collectionFormat(multi)
+// @Success 200 {object} domain.APIResponse{data=dto.MetricsSeriesResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/metrics [get]
+func (h *ServerController) GetServerMetrics(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse server ID",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid server ID", err)
+		return
+	}
+
+	var metricsQuery dto.MetricsQuery
+	if err := c.ShouldBindQuery(&metricsQuery); err != nil {
+		h.logger.Warn("Invalid metrics query",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.serverUseCase.GetServerMetrics(c.Request.Context(), uint(id), metricsQuery)
+	if err != nil {
+		h.logger.Error("Failed to get server metrics",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.Any("query", metricsQuery),
+			zap.String("request_id", c.GetString("request_id")))
+
+		switch {
+		case errors.Is(err, domainerrors.ErrServerNotFound):
+			h.serverPresenter.ServerNotFound(c, "Failed to get server metrics")
+		case errors.Is(err, domainerrors.ErrInvalidTimeRange), errors.Is(err, domainerrors.ErrInvalidStep):
+			h.serverPresenter.ValidationError(c, "Failed to get server metrics", err)
+		default:
+			h.serverPresenter.InternalServerError(c, "Failed to get server metrics", err)
+		}
+		return
+	}
+
+	h.serverPresenter.MetricsRetrieved(c, response)
+}
+
+// GetStatusHistory godoc
+// @Summary Get server status history
+// @Description Get the status changes of a server, newest first. Each status lasts until the next change.
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Param from query string false "Range start (RFC3339), defaults to one day before to"
+// @Param to query string false "Range end (RFC3339), defaults to now"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(50)
+// @Success 200 {object} domain.APIResponse{data=dto.StatusHistoryResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/status-history [get]
+func (h *ServerController) GetStatusHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse server ID",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid server ID", err)
+		return
+	}
+
+	var historyQuery dto.StatusHistoryQuery
+	if err := c.ShouldBindQuery(&historyQuery); err != nil {
+		h.logger.Warn("Invalid status history query",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := h.serverUseCase.GetStatusHistory(c.Request.Context(), uint(id), historyQuery)
+	if err != nil {
+		h.logger.Error("Failed to get server status history",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.Any("query", historyQuery),
+			zap.String("request_id", c.GetString("request_id")))
+
+		switch {
+		case errors.Is(err, domainerrors.ErrServerNotFound):
+			h.serverPresenter.ServerNotFound(c, "Failed to get server status history")
+		case errors.Is(err, domainerrors.ErrInvalidTimeRange):
+			h.serverPresenter.ValidationError(c, "Failed to get server status history", err)
+		default:
+			h.serverPresenter.InternalServerError(c, "Failed to get server status history", err)
+		}
+		return
+	}
+
+	h.serverPresenter.StatusHistoryRetrieved(c, response)
+}
+
+// GetDependencies godoc
+// @Summary Get server dependencies
+// @Description Get the servers a server depends on and the servers depending on it
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Success 200 {object} domain.APIResponse{data=dto.ServerDependenciesResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/dependencies [get]
+func (h *ServerController) GetDependencies(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse server ID",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid server ID", err)
+		return
+	}
+
+	response, err := h.serverUseCase.GetDependencies(c.Request.Context(), uint(id))
+	if err != nil {
+		h.logger.Error("Failed to get server dependencies",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrServerNotFound) {
+			h.serverPresenter.ServerNotFound(c, "Failed to get server dependencies")
+			return
+		}
+		h.serverPresenter.InternalServerError(c, "Failed to get server dependencies", err)
+		return
+	}
+
+	h.serverPresenter.DependenciesRetrieved(c, response)
+}
+
+// UpdateDependencies godoc
+// @Summary Update server dependencies
+// @Description Replace the servers a server depends on. While one of them is down, the server is UNREACHABLE instead of OFF. Dependencies cannot form a cycle.
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Param dependencies body dto.UpdateDependenciesRequest true "Parent server IDs"
+// @Success 200 {object} domain.APIResponse{data=dto.ServerDependenciesResponse}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 409 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/dependencies [put]
+func (h *ServerController) UpdateDependencies(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse server ID",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid server ID", err)
+		return
+	}
+
+	var req dto.UpdateDependenciesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid update dependencies request",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	response, err := h.serverUseCase.SetDependencies(c.Request.Context(), uint(id), req)
+	if err != nil {
+		h.logger.Error("Failed to update server dependencies",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.Any("parent_ids", req.ParentIDs),
+			zap.String("request_id", c.GetString("request_id")))
+
+		switch {
+		case errors.Is(err, domainerrors.ErrServerNotFound):
+			h.serverPresenter.ServerNotFound(c, "Failed to update server dependencies")
+		case errors.Is(err, domainerrors.ErrDependencyCycle):
+			h.serverPresenter.ConflictError(c, "Failed to update server dependencies", err)
+		default:
+			h.serverPresenter.InternalServerError(c, "Failed to update server dependencies", err)
+		}
+		return
+	}
+
+	h.serverPresenter.DependenciesUpdated(c, response)
+}
+
+// GetTopology godoc
+// @Summary Get server topology
+// @Description Get the dependency graph of the servers: every server having a parent or a child and the dependencies between them
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.APIResponse{data=dto.TopologyResponse}
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/topology [get]
+func (h *ServerController) GetTopology(c *gin.Context) {
+	response, err := h.serverUseCase.GetTopology(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get server topology",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InternalServerError(c, "Failed to get server topology", err)
+		return
+	}
+
+	h.serverPresenter.TopologyRetrieved(c, response)
+}
+
+// GetAgentConfig godoc
+// @Summary Get agent config
+// @Description Get the configuration delivered to the server agent on heartbeat
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Success 200 {object} domain.APIResponse{data=dto.AgentConfig}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/agent-config [get]
+func (h *ServerController) GetAgentConfig(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse server ID",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid server ID", err)
+		return
+	}
+
+	config, err := h.serverUseCase.GetAgentConfigByID(c.Request.Context(), uint(id))
+	if err != nil {
+		h.logger.Error("Failed to get agent config",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.ServerNotFound(c, "Failed to get agent config")
+		return
+	}
+
+	h.serverPresenter.AgentConfigRetrieved(c, config)
+}
+
+// UpdateAgentConfig godoc
+// @Summary Update agent config
+// @Description Update the reporting interval, enabled collectors and alert thresholds of a server agent. The config version is bumped and the agent applies it on its next heartbeat.
+// @Tags servers
+// @Accept json
+// @Produce json
+// @Param id path int true "Server ID"
+// @Param config body dto.UpdateAgentConfigRequest true "Agent config"
+// @Success 200 {object} domain.APIResponse{data=dto.AgentConfig}
+// @Failure 400 {object} domain.APIResponse
+// @Failure 404 {object} domain.APIResponse
+// @Failure 500 {object} domain.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/servers/{id}/agent-config [put]
+func (h *ServerController) UpdateAgentConfig(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Failed to parse server ID",
+			zap.Error(err),
+			zap.String("id_param", c.Param("id")),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid server ID", err)
+		return
+	}
+
+	var req dto.UpdateAgentConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid agent config request",
+			zap.Error(err),
+			zap.String("request_id", c.GetString("request_id")))
+		h.serverPresenter.InvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	config, err := h.serverUseCase.UpdateAgentConfig(c.Request.Context(), uint(id), req)
+	if err != nil {
+		h.logger.Error("Failed to update agent config",
+			zap.Error(err),
+			zap.Uint64("server_id", id),
+			zap.String("request_id", c.GetString("request_id")))
+		if errors.Is(err, domainerrors.ErrServerNotFound) {
+			h.serverPresenter.ServerNotFound(c, "Failed to update agent config")
+			return
+		}
+		h.serverPresenter.InternalServerError(c, "Failed to update agent config", err)
+		return
+	}
+
+	h.serverPresenter.AgentConfigUpdated(c, config)
+}
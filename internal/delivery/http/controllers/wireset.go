@@ -8,4 +8,9 @@ var WireSet = wire.NewSet(
 	NewUserController,
 	NewAuthController,
 	NewReportController,
+	NewEnrollmentTokenController,
+	NewAlertRuleController,
+	NewRemoteWriteController,
+	NewProbeController,
+	NewMaintenanceWindowController,
 )
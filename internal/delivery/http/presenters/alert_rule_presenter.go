@@ -0,0 +1,121 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/domain"
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+type AlertRulePresenter interface {
+	// Success responses
+	RuleCreated(c *gin.Context, rule dto.AlertRuleResponse)
+	RuleRetrieved(c *gin.Context, rule dto.AlertRuleResponse)
+	RulesRetrieved(c *gin.Context, rules []dto.AlertRuleResponse)
+	RuleUpdated(c *gin.Context, rule dto.AlertRuleResponse)
+	RuleDeleted(c *gin.Context)
+	StatesRetrieved(c *gin.Context, states []dto.AlertStateResponse)
+
+	// Error responses
+	InvalidRequest(c *gin.Context, message string, err error)
+	RuleNotFound(c *gin.Context, message string)
+	InternalServerError(c *gin.Context, message string, err error)
+}
+
+type alertRulePresenter struct{}
+
+func NewAlertRulePresenter() AlertRulePresenter {
+	return &alertRulePresenter{}
+}
+
+func (p *alertRulePresenter) RuleCreated(c *gin.Context, rule dto.AlertRuleResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeCreated,
+		"Alert rule created successfully",
+		rule,
+	)
+	c.JSON(http.StatusCreated, response)
+}
+
+func (p *alertRulePresenter) RuleRetrieved(c *gin.Context, rule dto.AlertRuleResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Alert rule retrieved successfully",
+		rule,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *alertRulePresenter) RulesRetrieved(c *gin.Context, rules []dto.AlertRuleResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Alert rules retrieved successfully",
+		rules,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *alertRulePresenter) RuleUpdated(c *gin.Context, rule dto.AlertRuleResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeUpdated,
+		"Alert rule updated successfully",
+		rule,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *alertRulePresenter) RuleDeleted(c *gin.Context) {
+	response := domain.NewSuccessResponse(
+		domain.CodeDeleted,
+		"Alert rule deleted successfully",
+		nil,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *alertRulePresenter) StatesRetrieved(c *gin.Context, states []dto.AlertStateResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Alert states retrieved successfully",
+		states,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *alertRulePresenter) InvalidRequest(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeBadRequest,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func (p *alertRulePresenter) RuleNotFound(c *gin.Context, message string) {
+	response := domain.NewErrorResponse(
+		domain.CodeNotFound,
+		message,
+		nil,
+	)
+	c.JSON(http.StatusNotFound, response)
+}
+
+func (p *alertRulePresenter) InternalServerError(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeInternalServerError,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusInternalServerError, response)
+}
@@ -0,0 +1,91 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/domain"
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+type EnrollmentTokenPresenter interface {
+	// Success responses
+	TokenCreated(c *gin.Context, response *dto.CreateEnrollmentTokenResponse)
+	TokensRetrieved(c *gin.Context, tokens []dto.EnrollmentTokenResponse)
+	TokenRevoked(c *gin.Context)
+
+	// Error responses
+	InvalidRequest(c *gin.Context, message string, err error)
+	TokenNotFound(c *gin.Context, message string)
+	InternalServerError(c *gin.Context, message string, err error)
+}
+
+type enrollmentTokenPresenter struct{}
+
+func NewEnrollmentTokenPresenter() EnrollmentTokenPresenter {
+	return &enrollmentTokenPresenter{}
+}
+
+func (p *enrollmentTokenPresenter) TokenCreated(c *gin.Context, response *dto.CreateEnrollmentTokenResponse) {
+	resp := domain.NewSuccessResponse(
+		domain.CodeCreated,
+		"Enrollment token created successfully",
+		response,
+	)
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (p *enrollmentTokenPresenter) TokensRetrieved(c *gin.Context, tokens []dto.EnrollmentTokenResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Enrollment tokens retrieved successfully",
+		tokens,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *enrollmentTokenPresenter) TokenRevoked(c *gin.Context) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Enrollment token revoked successfully",
+		nil,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *enrollmentTokenPresenter) InvalidRequest(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeBadRequest,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func (p *enrollmentTokenPresenter) TokenNotFound(c *gin.Context, message string) {
+	response := domain.NewErrorResponse(
+		domain.CodeNotFound,
+		message,
+		nil,
+	)
+	c.JSON(http.StatusNotFound, response)
+}
+
+func (p *enrollmentTokenPresenter) InternalServerError(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeInternalServerError,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusInternalServerError, response)
+}
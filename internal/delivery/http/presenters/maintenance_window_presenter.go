@@ -0,0 +1,111 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/domain"
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+type MaintenanceWindowPresenter interface {
+	// Success responses
+	WindowCreated(c *gin.Context, window dto.MaintenanceWindowResponse)
+	WindowRetrieved(c *gin.Context, window dto.MaintenanceWindowResponse)
+	WindowsRetrieved(c *gin.Context, windows []dto.MaintenanceWindowResponse)
+	WindowUpdated(c *gin.Context, window dto.MaintenanceWindowResponse)
+	WindowDeleted(c *gin.Context)
+
+	// Error responses
+	InvalidRequest(c *gin.Context, message string, err error)
+	WindowNotFound(c *gin.Context, message string)
+	InternalServerError(c *gin.Context, message string, err error)
+}
+
+type maintenanceWindowPresenter struct{}
+
+func NewMaintenanceWindowPresenter() MaintenanceWindowPresenter {
+	return &maintenanceWindowPresenter{}
+}
+
+func (p *maintenanceWindowPresenter) WindowCreated(c *gin.Context, window dto.MaintenanceWindowResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeCreated,
+		"Maintenance window created successfully",
+		window,
+	)
+	c.JSON(http.StatusCreated, response)
+}
+
+func (p *maintenanceWindowPresenter) WindowRetrieved(c *gin.Context, window dto.MaintenanceWindowResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Maintenance window retrieved successfully",
+		window,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *maintenanceWindowPresenter) WindowsRetrieved(c *gin.Context, windows []dto.MaintenanceWindowResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Maintenance windows retrieved successfully",
+		windows,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *maintenanceWindowPresenter) WindowUpdated(c *gin.Context, window dto.MaintenanceWindowResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeUpdated,
+		"Maintenance window updated successfully",
+		window,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *maintenanceWindowPresenter) WindowDeleted(c *gin.Context) {
+	response := domain.NewSuccessResponse(
+		domain.CodeDeleted,
+		"Maintenance window deleted successfully",
+		nil,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *maintenanceWindowPresenter) InvalidRequest(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeBadRequest,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func (p *maintenanceWindowPresenter) WindowNotFound(c *gin.Context, message string) {
+	response := domain.NewErrorResponse(
+		domain.CodeNotFound,
+		message,
+		nil,
+	)
+	c.JSON(http.StatusNotFound, response)
+}
+
+func (p *maintenanceWindowPresenter) InternalServerError(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeInternalServerError,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusInternalServerError, response)
+}
@@ -0,0 +1,101 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/domain"
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+type ProbePresenter interface {
+	// Success responses
+	ProbeCreated(c *gin.Context, probe dto.ProbeResponse)
+	ProbesRetrieved(c *gin.Context, probes []dto.ProbeResponse)
+	ProbeUpdated(c *gin.Context, probe dto.ProbeResponse)
+	ProbeDeleted(c *gin.Context)
+
+	// Error responses
+	InvalidRequest(c *gin.Context, message string, err error)
+	NotFound(c *gin.Context, message string)
+	InternalServerError(c *gin.Context, message string, err error)
+}
+
+type probePresenter struct{}
+
+func NewProbePresenter() ProbePresenter {
+	return &probePresenter{}
+}
+
+func (p *probePresenter) ProbeCreated(c *gin.Context, probe dto.ProbeResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeCreated,
+		"Probe created successfully",
+		probe,
+	)
+	c.JSON(http.StatusCreated, response)
+}
+
+func (p *probePresenter) ProbesRetrieved(c *gin.Context, probes []dto.ProbeResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Probes retrieved successfully",
+		probes,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *probePresenter) ProbeUpdated(c *gin.Context, probe dto.ProbeResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeUpdated,
+		"Probe updated successfully",
+		probe,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *probePresenter) ProbeDeleted(c *gin.Context) {
+	response := domain.NewSuccessResponse(
+		domain.CodeDeleted,
+		"Probe deleted successfully",
+		nil,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *probePresenter) InvalidRequest(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeBadRequest,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func (p *probePresenter) NotFound(c *gin.Context, message string) {
+	response := domain.NewErrorResponse(
+		domain.CodeNotFound,
+		message,
+		nil,
+	)
+	c.JSON(http.StatusNotFound, response)
+}
+
+func (p *probePresenter) InternalServerError(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeInternalServerError,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusInternalServerError, response)
+}
@@ -0,0 +1,79 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/domain"
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+type RemoteWritePresenter interface {
+	// Success responses
+	RemoteWriteAccepted(c *gin.Context, result *dto.RemoteWriteResult)
+
+	// Error responses
+	InvalidRequest(c *gin.Context, message string, err error)
+	RequestTooLarge(c *gin.Context, message string, err error)
+	InternalServerError(c *gin.Context, message string, err error)
+}
+
+type remoteWritePresenter struct{}
+
+func NewRemoteWritePresenter() RemoteWritePresenter {
+	return &remoteWritePresenter{}
+}
+
+func (p *remoteWritePresenter) RemoteWriteAccepted(c *gin.Context, result *dto.RemoteWriteResult) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Remote write processed successfully",
+		result,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+// InvalidRequest answers with a 4xx status, which Prometheus does not retry
+func (p *remoteWritePresenter) InvalidRequest(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeBadRequest,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusBadRequest, response)
+}
+
+// RequestTooLarge answers with a 413 status, which Prometheus does not retry
+func (p *remoteWritePresenter) RequestTooLarge(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeBadRequest,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusRequestEntityTooLarge, response)
+}
+
+// InternalServerError answers with a 5xx status, which Prometheus retries
+func (p *remoteWritePresenter) InternalServerError(c *gin.Context, message string, err error) {
+	var errorMsg interface{}
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	response := domain.NewErrorResponse(
+		domain.CodeInternalServerError,
+		message,
+		errorMsg,
+	)
+	c.JSON(http.StatusInternalServerError, response)
+}
@@ -16,9 +16,18 @@ type ServerPresenter interface {
 	ServerRetrieved(c *gin.Context, server dto.ServerResponse)
 	ServersRetrieved(c *gin.Context, response []*entity.Server)
 	ServerDeleted(c *gin.Context)
+	CredentialsRevoked(c *gin.Context)
 	ServerStatusUpdated(c *gin.Context, message string)
 	ExportCompleted(c *gin.Context, filePath string)
-	MonitoringSuccess(c *gin.Context, message string)
+	MonitoringSuccess(c *gin.Context, message string, response *dto.MonitoringResponse)
+	AgentConfigRetrieved(c *gin.Context, config *dto.AgentConfig)
+	AgentConfigUpdated(c *gin.Context, config *dto.AgentConfig)
+	MetricsRetrieved(c *gin.Context, response *dto.MetricsSeriesResponse)
+	StatusHistoryRetrieved(c *gin.Context, response *dto.StatusHistoryResponse)
+	DependenciesRetrieved(c *gin.Context, response *dto.ServerDependenciesResponse)
+	DependenciesUpdated(c *gin.Context, response *dto.ServerDependenciesResponse)
+	TopologyRetrieved(c *gin.Context, response *dto.TopologyResponse)
+	MetricsBatchProcessed(c *gin.Context, response *dto.MetricsBatchResponse)
 
 	// Error responses
 	InvalidRequest(c *gin.Context, message string, err error)
@@ -26,8 +35,10 @@ type ServerPresenter interface {
 	ValidationError(c *gin.Context, message string, err error)
 	ConflictError(c *gin.Context, message string, err error)
 	Unauthorized(c *gin.Context, message string)
+	Forbidden(c *gin.Context, message string)
 	InternalServerError(c *gin.Context, message string, err error)
 	ServerRegistered(c *gin.Context, response *dto.AuthResponse)
+	TokenRefreshed(c *gin.Context, response *dto.AuthResponse)
 }
 
 type serverPresenter struct{}
@@ -36,11 +47,83 @@ func NewServerPresenter() ServerPresenter {
 	return &serverPresenter{}
 }
 
-func (p *serverPresenter) MonitoringSuccess(c *gin.Context, message string) {
+func (p *serverPresenter) MonitoringSuccess(c *gin.Context, message string, res *dto.MonitoringResponse) {
 	response := domain.NewSuccessResponse(
 		domain.CodeSuccess,
 		message,
-		nil,
+		res,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *serverPresenter) AgentConfigRetrieved(c *gin.Context, config *dto.AgentConfig) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Agent config retrieved successfully",
+		config,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *serverPresenter) AgentConfigUpdated(c *gin.Context, config *dto.AgentConfig) {
+	response := domain.NewSuccessResponse(
+		domain.CodeUpdated,
+		"Agent config updated successfully",
+		config,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *serverPresenter) MetricsBatchProcessed(c *gin.Context, res *dto.MetricsBatchResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Server metrics batch processed",
+		res,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *serverPresenter) StatusHistoryRetrieved(c *gin.Context, res *dto.StatusHistoryResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Server status history retrieved successfully",
+		res,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *serverPresenter) DependenciesRetrieved(c *gin.Context, res *dto.ServerDependenciesResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Server dependencies retrieved successfully",
+		res,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *serverPresenter) DependenciesUpdated(c *gin.Context, res *dto.ServerDependenciesResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Server dependencies updated successfully",
+		res,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *serverPresenter) TopologyRetrieved(c *gin.Context, res *dto.TopologyResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Server topology retrieved successfully",
+		res,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *serverPresenter) MetricsRetrieved(c *gin.Context, res *dto.MetricsSeriesResponse) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Server metrics retrieved successfully",
+		res,
 	)
 	c.JSON(http.StatusOK, response)
 }
@@ -90,6 +173,15 @@ func (p *serverPresenter) ServerDeleted(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (p *serverPresenter) CredentialsRevoked(c *gin.Context) {
+	response := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Server credentials revoked successfully",
+		nil,
+	)
+	c.JSON(http.StatusOK, response)
+}
+
 func (p *serverPresenter) ServerStatusUpdated(c *gin.Context, message string) {
 	response := domain.NewSuccessResponse(
 		domain.CodeSuccess,
@@ -171,6 +263,15 @@ func (p *serverPresenter) Unauthorized(c *gin.Context, message string) {
 	c.JSON(http.StatusUnauthorized, response)
 }
 
+func (p *serverPresenter) Forbidden(c *gin.Context, message string) {
+	response := domain.NewErrorResponse(
+		domain.CodeForbidden,
+		message,
+		nil,
+	)
+	c.JSON(http.StatusForbidden, response)
+}
+
 func (p *serverPresenter) InternalServerError(c *gin.Context, message string, err error) {
 	var errorMsg interface{}
 	if err != nil {
@@ -193,3 +294,12 @@ func (p *serverPresenter) ServerRegistered(c *gin.Context, response *dto.AuthRes
 	)
 	c.JSON(http.StatusCreated, resp)
 }
+
+func (p *serverPresenter) TokenRefreshed(c *gin.Context, response *dto.AuthResponse) {
+	resp := domain.NewSuccessResponse(
+		domain.CodeSuccess,
+		"Server token refreshed successfully",
+		response,
+	)
+	c.JSON(http.StatusOK, resp)
+}
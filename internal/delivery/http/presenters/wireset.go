@@ -8,4 +8,9 @@ var WireSet = wire.NewSet(
 	NewUserPresenter,
 	NewReportPresenter,
 	NewJobsPresenter,
+	NewEnrollmentTokenPresenter,
+	NewAlertRulePresenter,
+	NewRemoteWritePresenter,
+	NewProbePresenter,
+	NewMaintenanceWindowPresenter,
 )
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/delivery/http/controllers"
+	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+)
+
+type AlertRuleRouter interface {
+	RegisterRoutes(v1 *gin.RouterGroup)
+}
+
+type alertRuleRouter struct {
+	alertRuleController *controllers.AlertRuleController
+	authMiddleware      *middleware.AuthMiddleware
+}
+
+func NewAlertRuleRouter(
+	alertRuleController *controllers.AlertRuleController,
+	authMiddleware *middleware.AuthMiddleware,
+) AlertRuleRouter {
+	return &alertRuleRouter{
+		alertRuleController: alertRuleController,
+		authMiddleware:      authMiddleware,
+	}
+}
+
+func (h *alertRuleRouter) RegisterRoutes(v1 *gin.RouterGroup) {
+	rules := v1.Group("/alert-rules")
+	rules.Use(h.authMiddleware.RequireAuth())
+	{
+		rules.GET("/", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.alertRuleController.ListRules)
+		rules.GET("/:id", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.alertRuleController.GetRule)
+		rules.GET("/:id/states", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.alertRuleController.ListStates)
+		rules.POST("/", h.authMiddleware.RequireAnyScope("admin:all"), h.alertRuleController.CreateRule)
+		rules.PUT("/:id", h.authMiddleware.RequireAnyScope("admin:all"), h.alertRuleController.UpdateRule)
+		rules.DELETE("/:id", h.authMiddleware.RequireAnyScope("admin:all"), h.alertRuleController.DeleteRule)
+	}
+}
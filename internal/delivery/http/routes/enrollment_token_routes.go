@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/delivery/http/controllers"
+	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+)
+
+type EnrollmentTokenRouter interface {
+	RegisterRoutes(v1 *gin.RouterGroup)
+}
+
+type enrollmentTokenRouter struct {
+	enrollmentTokenController *controllers.EnrollmentTokenController
+	authMiddleware            *middleware.AuthMiddleware
+}
+
+func NewEnrollmentTokenRouter(
+	enrollmentTokenController *controllers.EnrollmentTokenController,
+	authMiddleware *middleware.AuthMiddleware,
+) EnrollmentTokenRouter {
+	return &enrollmentTokenRouter{
+		enrollmentTokenController: enrollmentTokenController,
+		authMiddleware:            authMiddleware,
+	}
+}
+
+func (h *enrollmentTokenRouter) RegisterRoutes(v1 *gin.RouterGroup) {
+	tokens := v1.Group("/enrollment-tokens")
+	tokens.Use(h.authMiddleware.RequireAuth())
+	{
+		tokens.GET("/", h.authMiddleware.RequireAnyScope("admin:all"), h.enrollmentTokenController.ListTokens)
+		tokens.POST("/", h.authMiddleware.RequireAnyScope("admin:all"), h.enrollmentTokenController.CreateToken)
+		tokens.DELETE("/:id", h.authMiddleware.RequireAnyScope("admin:all"), h.enrollmentTokenController.RevokeToken)
+	}
+}
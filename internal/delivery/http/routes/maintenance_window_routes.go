@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/delivery/http/controllers"
+	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+)
+
+type MaintenanceWindowRouter interface {
+	RegisterRoutes(v1 *gin.RouterGroup)
+}
+
+type maintenanceWindowRouter struct {
+	maintenanceWindowController *controllers.MaintenanceWindowController
+	authMiddleware              *middleware.AuthMiddleware
+}
+
+func NewMaintenanceWindowRouter(
+	maintenanceWindowController *controllers.MaintenanceWindowController,
+	authMiddleware *middleware.AuthMiddleware,
+) MaintenanceWindowRouter {
+	return &maintenanceWindowRouter{
+		maintenanceWindowController: maintenanceWindowController,
+		authMiddleware:              authMiddleware,
+	}
+}
+
+func (h *maintenanceWindowRouter) RegisterRoutes(v1 *gin.RouterGroup) {
+	windows := v1.Group("/maintenance-windows")
+	windows.Use(h.authMiddleware.RequireAuth())
+	{
+		windows.GET("/", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.maintenanceWindowController.ListWindows)
+		windows.GET("/:id", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.maintenanceWindowController.GetWindow)
+		windows.POST("/", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.maintenanceWindowController.CreateWindow)
+		windows.PUT("/:id", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.maintenanceWindowController.UpdateWindow)
+		windows.DELETE("/:id", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.maintenanceWindowController.DeleteWindow)
+	}
+}
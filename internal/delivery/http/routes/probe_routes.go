@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/delivery/http/controllers"
+	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+)
+
+type ProbeRouter interface {
+	RegisterRoutes(v1 *gin.RouterGroup)
+}
+
+type probeRouter struct {
+	probeController *controllers.ProbeController
+	authMiddleware  *middleware.AuthMiddleware
+}
+
+func NewProbeRouter(
+	probeController *controllers.ProbeController,
+	authMiddleware *middleware.AuthMiddleware,
+) ProbeRouter {
+	return &probeRouter{
+		probeController: probeController,
+		authMiddleware:  authMiddleware,
+	}
+}
+
+func (h *probeRouter) RegisterRoutes(v1 *gin.RouterGroup) {
+	probes := v1.Group("/servers/:id/probes")
+	probes.Use(h.authMiddleware.RequireAuth())
+	{
+		probes.GET("", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.probeController.ListProbes)
+		probes.POST("", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.probeController.CreateProbe)
+		probes.PUT("/:probe_id", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.probeController.UpdateProbe)
+		probes.DELETE("/:probe_id", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.probeController.DeleteProbe)
+	}
+}
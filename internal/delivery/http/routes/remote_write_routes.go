@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/delivery/http/controllers"
+	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+)
+
+type RemoteWriteRouter interface {
+	RegisterRoutes(v1 *gin.RouterGroup)
+}
+
+type remoteWriteRouter struct {
+	remoteWriteController     *controllers.RemoteWriteController
+	remoteWriteAuthMiddleware *middleware.RemoteWriteAuthMiddleware
+}
+
+func NewRemoteWriteRouter(
+	remoteWriteController *controllers.RemoteWriteController,
+	remoteWriteAuthMiddleware *middleware.RemoteWriteAuthMiddleware,
+) RemoteWriteRouter {
+	return &remoteWriteRouter{
+		remoteWriteController:     remoteWriteController,
+		remoteWriteAuthMiddleware: remoteWriteAuthMiddleware,
+	}
+}
+
+func (h *remoteWriteRouter) RegisterRoutes(v1 *gin.RouterGroup) {
+	metrics := v1.Group("/metrics")
+	{
+		metrics.POST("/remote-write", h.remoteWriteAuthMiddleware.RequireRemoteWriteAuth(), h.remoteWriteController.Write)
+	}
+}
@@ -11,11 +11,16 @@ type Handler interface {
 }
 
 type handler struct {
-	authRouter   AuthRouter
-	serverRouter ServerRouter
-	reportRouter ReportRouter
-	userRouter   UserRouter
-	jobsRouter   JobsRouter
+	authRouter            AuthRouter
+	serverRouter          ServerRouter
+	reportRouter          ReportRouter
+	userRouter            UserRouter
+	jobsRouter            JobsRouter
+	enrollmentTokenRouter EnrollmentTokenRouter
+	alertRuleRouter       AlertRuleRouter
+	remoteWriteRouter     RemoteWriteRouter
+	probeRouter           ProbeRouter
+	maintenanceRouter     MaintenanceWindowRouter
 }
 
 func NewHandler(
@@ -24,13 +29,23 @@ func NewHandler(
 	reportRouter ReportRouter,
 	userRouter UserRouter,
 	jobsRouter JobsRouter,
+	enrollmentTokenRouter EnrollmentTokenRouter,
+	alertRuleRouter AlertRuleRouter,
+	remoteWriteRouter RemoteWriteRouter,
+	probeRouter ProbeRouter,
+	maintenanceRouter MaintenanceWindowRouter,
 ) Handler {
 	return &handler{
-		authRouter:   authRouter,
-		serverRouter: serverRouter,
-		reportRouter: reportRouter,
-		userRouter:   userRouter,
-		jobsRouter:   jobsRouter,
+		authRouter:            authRouter,
+		serverRouter:          serverRouter,
+		reportRouter:          reportRouter,
+		userRouter:            userRouter,
+		jobsRouter:            jobsRouter,
+		enrollmentTokenRouter: enrollmentTokenRouter,
+		alertRuleRouter:       alertRuleRouter,
+		remoteWriteRouter:     remoteWriteRouter,
+		probeRouter:           probeRouter,
+		maintenanceRouter:     maintenanceRouter,
 	}
 }
 
@@ -52,6 +67,11 @@ func (h *handler) RegisterRoutes() *gin.Engine {
 	h.reportRouter.RegisterRoutes(v1)
 	h.userRouter.RegisterRoutes(v1)
 	h.jobsRouter.RegisterRoutes(v1)
+	h.enrollmentTokenRouter.RegisterRoutes(v1)
+	h.alertRuleRouter.RegisterRoutes(v1)
+	h.remoteWriteRouter.RegisterRoutes(v1)
+	h.probeRouter.RegisterRoutes(v1)
+	h.maintenanceRouter.RegisterRoutes(v1)
 
 	return router
 }
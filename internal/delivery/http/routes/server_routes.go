@@ -11,17 +11,20 @@ type ServerRouter interface {
 }
 
 type serverRouter struct {
-	serverController *controllers.ServerController
-	authMiddleware   *middleware.AuthMiddleware
+	serverController    *controllers.ServerController
+	authMiddleware      *middleware.AuthMiddleware
+	agentAuthMiddleware *middleware.AgentAuthMiddleware
 }
 
 func NewServerRouter(
 	serverController *controllers.ServerController,
 	authMiddleware *middleware.AuthMiddleware,
+	agentAuthMiddleware *middleware.AgentAuthMiddleware,
 ) ServerRouter {
 	return &serverRouter{
-		serverController: serverController,
-		authMiddleware:   authMiddleware,
+		serverController:    serverController,
+		authMiddleware:      authMiddleware,
+		agentAuthMiddleware: agentAuthMiddleware,
 	}
 }
 
@@ -29,7 +32,15 @@ func (h *serverRouter) RegisterRoutes(v1 *gin.RouterGroup) {
 	servers := v1.Group("/servers")
 	{
 		servers.POST("/register", h.serverController.Register)
-		servers.POST("/monitoring", h.serverController.Monitoring)
+		servers.POST("/token/refresh", h.serverController.RefreshToken)
+	}
+
+	// Agent routes authenticated with the server access token
+	agent := v1.Group("/servers")
+	agent.Use(h.agentAuthMiddleware.RequireAgentAuth())
+	{
+		agent.POST("/monitoring", h.serverController.Monitoring)
+		agent.POST("/monitoring/batch", h.serverController.MonitoringBatch)
 	}
 
 	servers.Use(h.authMiddleware.RequireAuth())
@@ -37,10 +48,21 @@ func (h *serverRouter) RegisterRoutes(v1 *gin.RouterGroup) {
 
 		servers.GET("/", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.serverController.ListServer)
 		servers.GET("/export", h.authMiddleware.RequireAnyScope("admin:all", "server:export"), h.serverController.ExportServers)
+		servers.GET("/topology", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.serverController.GetTopology)
+		servers.GET("/:id/metrics", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.serverController.GetServerMetrics)
+		servers.GET("/:id/status-history", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.serverController.GetStatusHistory)
+		servers.GET("/:id/agent-config", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.serverController.GetAgentConfig)
+		servers.GET("/:id/dependencies", h.authMiddleware.RequireAnyScope("admin:all", "server:read"), h.serverController.GetDependencies)
 
 		servers.POST("/", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.serverController.CreateServer)
 		servers.PUT("/:id", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.serverController.UpdateServer)
+		servers.PUT("/:id/agent-config", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.serverController.UpdateAgentConfig)
+		servers.PUT("/:id/status", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.serverController.UpdateServerStatus)
+		servers.PUT("/:id/dependencies", h.authMiddleware.RequireAnyScope("admin:all", "server:write"), h.serverController.UpdateDependencies)
 		servers.POST("/import", h.authMiddleware.RequireAnyScope("admin:all", "server:import"), h.serverController.ImportServers)
 		servers.DELETE("/:id", h.authMiddleware.RequireAnyScope("admin:all", "server:delete"), h.serverController.DeleteServer)
+		servers.POST("/:id/revoke", h.authMiddleware.RequireAnyScope("admin:all"), h.serverController.RevokeCredentials)
+		servers.POST("/:id/approve", h.authMiddleware.RequireAnyScope("admin:all"), h.serverController.ApproveServer)
+		servers.POST("/:id/reject", h.authMiddleware.RequireAnyScope("admin:all"), h.serverController.RejectServer)
 	}
 }
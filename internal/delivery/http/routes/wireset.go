@@ -8,5 +8,10 @@ var WireSet = wire.NewSet(
 	NewReportRouter,
 	NewUserRouter,
 	NewJobsRouter,
+	NewEnrollmentTokenRouter,
+	NewAlertRuleRouter,
+	NewRemoteWriteRouter,
+	NewProbeRouter,
+	NewMaintenanceWindowRouter,
 	NewHandler,
 )
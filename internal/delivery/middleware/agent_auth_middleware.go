@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/domain"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+const (
+	agentServerIDKey = "agent_server_id"
+)
+
+type AgentAuthMiddleware struct {
+	authUseCase usecases.AuthUseCase
+	logger      *zap.Logger
+}
+
+func NewAgentAuthMiddleware(authUseCase usecases.AuthUseCase, logger *zap.Logger) *AgentAuthMiddleware {
+	return &AgentAuthMiddleware{
+		authUseCase: authUseCase,
+		logger:      logger,
+	}
+}
+
+// RequireAgentAuth is a middleware that requires a server access token issued at registration
+func (m *AgentAuthMiddleware) RequireAgentAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.JSON(http.StatusUnauthorized, domain.NewErrorResponse(
+				domain.CodeUnauthorized,
+				"Authentication required",
+				nil,
+			))
+			c.Abort()
+			return
+		}
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+
+		claims, err := m.authUseCase.ValidateServerToken(c, token)
+		if err != nil {
+			m.logger.Warn("Invalid server token", zap.Error(err))
+			c.JSON(http.StatusUnauthorized, domain.NewErrorResponse(
+				domain.CodeInvalidToken,
+				"Invalid token",
+				nil,
+			))
+			c.Abort()
+			return
+		}
+		if claims.TokenType != "server_access" {
+			m.logger.Warn("Invalid server token type", zap.String("token_type", claims.TokenType))
+			c.JSON(http.StatusUnauthorized, domain.NewErrorResponse(
+				domain.CodeInvalidToken,
+				"Invalid token type",
+				nil,
+			))
+			c.Abort()
+			return
+		}
+		if claims.Subject == "" || claims.Subject != claims.ServerID {
+			m.logger.Warn("Server token subject mismatch",
+				zap.String("subject", claims.Subject),
+				zap.String("server_id", claims.ServerID))
+			c.JSON(http.StatusUnauthorized, domain.NewErrorResponse(
+				domain.CodeInvalidToken,
+				"Invalid token subject",
+				nil,
+			))
+			c.Abort()
+			return
+		}
+
+		// Store server info in context
+		c.Set(agentServerIDKey, claims.Subject)
+		c.Set("server_claims", claims)
+
+		c.Next()
+	}
+}
+
+// GetAgentServerID extracts the authenticated server ID from gin context
+func GetAgentServerID(c *gin.Context) (string, bool) {
+	serverID, exists := c.Get(agentServerIDKey)
+	if !exists {
+		return "", false
+	}
+
+	id, ok := serverID.(string)
+	return id, ok
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/th1enq/server_management_system/internal/configs"
+)
+
+const (
+	remoteWriteSharedKey = "remote_write_shared"
+)
+
+type RemoteWriteAuthMiddleware struct {
+	config              configs.RemoteWrite
+	agentAuthMiddleware *AgentAuthMiddleware
+}
+
+func NewRemoteWriteAuthMiddleware(config configs.RemoteWrite, agentAuthMiddleware *AgentAuthMiddleware) *RemoteWriteAuthMiddleware {
+	return &RemoteWriteAuthMiddleware{
+		config:              config,
+		agentAuthMiddleware: agentAuthMiddleware,
+	}
+}
+
+// RequireRemoteWriteAuth accepts one of the configured shared bearer tokens,
+// which may write series of any server, or a server access token, which may
+// only write its own series
+func (m *RemoteWriteAuthMiddleware) RequireRemoteWriteAuth() gin.HandlerFunc {
+	requireAgentAuth := m.agentAuthMiddleware.RequireAgentAuth()
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		for _, shared := range m.config.BearerTokens {
+			if shared != "" && subtle.ConstantTimeCompare([]byte(token), []byte(shared)) == 1 {
+				c.Set(remoteWriteSharedKey, true)
+				c.Next()
+				return
+			}
+		}
+		requireAgentAuth(c)
+	}
+}
+
+// GetRemoteWriteServerID returns the server a remote write request is restricted to,
+// empty when it was authenticated with a shared token
+func GetRemoteWriteServerID(c *gin.Context) string {
+	if c.GetBool(remoteWriteSharedKey) {
+		return ""
+	}
+	serverID, _ := GetAgentServerID(c)
+	return serverID
+}
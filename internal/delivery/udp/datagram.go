@@ -0,0 +1,70 @@
+package udp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+// A heartbeat datagram is a single line of ASCII text:
+//
+//	SMS1 <server_id> <unix_ms> <cpu> <ram> <disk> <signature>
+//
+// where signature is the hex HMAC-SHA256 of everything before the last space,
+// keyed with the heartbeat key issued at registration.
+const (
+	datagramVersion = "SMS1"
+	datagramFields  = 6
+
+	maxDatagramSize = 512
+)
+
+var errMalformedDatagram = errors.New("malformed heartbeat datagram")
+
+func parseDatagram(data []byte) (dto.SignedHeartbeat, error) {
+	data = bytes.TrimRight(data, "\r\n")
+	sep := bytes.LastIndexByte(data, ' ')
+	if sep < 0 {
+		return dto.SignedHeartbeat{}, errMalformedDatagram
+	}
+	payload, signatureHex := data[:sep], data[sep+1:]
+
+	signature := make([]byte, hex.DecodedLen(len(signatureHex)))
+	if _, err := hex.Decode(signature, signatureHex); err != nil {
+		return dto.SignedHeartbeat{}, errMalformedDatagram
+	}
+
+	fields := strings.Split(string(payload), " ")
+	if len(fields) != datagramFields || fields[0] != datagramVersion || fields[1] == "" {
+		return dto.SignedHeartbeat{}, errMalformedDatagram
+	}
+	timestamp, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return dto.SignedHeartbeat{}, errMalformedDatagram
+	}
+	var usage [3]int
+	for i, field := range fields[3:] {
+		value, err := strconv.Atoi(field)
+		if err != nil || value < 0 || value > 100 {
+			return dto.SignedHeartbeat{}, errMalformedDatagram
+		}
+		usage[i] = value
+	}
+
+	return dto.SignedHeartbeat{
+		Metrics: dto.MetricsRequest{
+			ServerID:  fields[1],
+			CPU:       usage[0],
+			RAM:       usage[1],
+			Disk:      usage[2],
+			Timestamp: time.UnixMilli(timestamp),
+		},
+		Payload:   payload,
+		Signature: signature,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package udp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+func TestParseDatagram(t *testing.T) {
+	signature := "00ff10ab"
+
+	tests := []struct {
+		name    string
+		data    string
+		want    dto.MetricsRequest
+		payload string
+		wantErr bool
+	}{
+		{
+			name:    "valid datagram",
+			data:    "SMS1 server-01 1700000000123 12 48 19 " + signature,
+			want:    dto.MetricsRequest{ServerID: "server-01", CPU: 12, RAM: 48, Disk: 19, Timestamp: time.UnixMilli(1700000000123)},
+			payload: "SMS1 server-01 1700000000123 12 48 19",
+		},
+		{
+			name:    "trailing newline",
+			data:    "SMS1 server-01 1700000000123 0 100 5 " + signature + "\r\n",
+			want:    dto.MetricsRequest{ServerID: "server-01", CPU: 0, RAM: 100, Disk: 5, Timestamp: time.UnixMilli(1700000000123)},
+			payload: "SMS1 server-01 1700000000123 0 100 5",
+		},
+		{name: "empty", data: "", wantErr: true},
+		{name: "no signature", data: "SMS1", wantErr: true},
+		{name: "signature not hex", data: "SMS1 server-01 1700000000123 12 48 19 zz", wantErr: true},
+		{name: "odd length signature", data: "SMS1 server-01 1700000000123 12 48 19 abc", wantErr: true},
+		{name: "unknown version", data: "SMS2 server-01 1700000000123 12 48 19 " + signature, wantErr: true},
+		{name: "missing field", data: "SMS1 server-01 1700000000123 12 48 " + signature, wantErr: true},
+		{name: "extra field", data: "SMS1 server-01 1700000000123 12 48 19 7 " + signature, wantErr: true},
+		{name: "empty server id", data: "SMS1  1700000000123 12 48 19 " + signature, wantErr: true},
+		{name: "timestamp not a number", data: "SMS1 server-01 now 12 48 19 " + signature, wantErr: true},
+		{name: "usage above 100", data: "SMS1 server-01 1700000000123 101 48 19 " + signature, wantErr: true},
+		{name: "negative usage", data: "SMS1 server-01 1700000000123 12 -1 19 " + signature, wantErr: true},
+		{name: "fractional usage", data: "SMS1 server-01 1700000000123 12 48 1.5 " + signature, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDatagram([]byte(tt.data))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errMalformedDatagram)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Metrics)
+			assert.Equal(t, tt.payload, string(got.Payload))
+			assert.Equal(t, []byte{0x00, 0xff, 0x10, 0xab}, got.Signature)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package udp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultWorkers   = 64
+	heartbeatTimeout = 5 * time.Second
+)
+
+type IServer interface {
+	Start(ctx context.Context) error
+}
+
+type server struct {
+	config              configs.UDPHeartbeat
+	udpHeartbeatUseCase usecases.UDPHeartbeatUseCase
+	logger              *zap.Logger
+}
+
+func NewServer(
+	config configs.UDPHeartbeat,
+	udpHeartbeatUseCase usecases.UDPHeartbeatUseCase,
+	logger *zap.Logger,
+) IServer {
+	return &server{
+		config:              config,
+		udpHeartbeatUseCase: udpHeartbeatUseCase,
+		logger:              logger,
+	}
+}
+
+// Start binds the heartbeat port and returns the error if it cannot, then
+// serves datagrams in the background until ctx is cancelled.
+func (s *server) Start(ctx context.Context) error {
+	if !s.config.Enabled {
+		s.logger.Info("UDP heartbeat listener disabled")
+		return nil
+	}
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: s.config.Port})
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	s.logger.Info("UDP heartbeat listener started", zap.Int("port", s.config.Port))
+	go s.serve(ctx, conn)
+	return nil
+}
+
+// serve reads datagrams until the connection is closed. Nothing is sent back,
+// so the listener cannot be used to amplify traffic. Datagrams arriving while
+// every worker is busy are dropped like lost packets.
+func (s *server) serve(ctx context.Context, conn *net.UDPConn) {
+	workers := s.config.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	slots := make(chan struct{}, workers)
+
+	buf := make([]byte, maxDatagramSize+1)
+	for {
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.Error("Failed to read heartbeat datagram", zap.Error(err))
+			continue
+		}
+		if n > maxDatagramSize {
+			s.logger.Debug("Heartbeat datagram too large", zap.Stringer("addr", addr))
+			continue
+		}
+		data := append([]byte(nil), buf[:n]...)
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			s.logger.Warn("Heartbeat workers busy, dropping datagram", zap.Stringer("addr", addr))
+			continue
+		}
+		go func() {
+			defer func() { <-slots }()
+			s.handle(ctx, data, addr)
+		}()
+	}
+}
+
+func (s *server) handle(ctx context.Context, data []byte, addr *net.UDPAddr) {
+	heartbeat, err := parseDatagram(data)
+	if err != nil {
+		s.logger.Debug("Invalid heartbeat datagram", zap.Stringer("addr", addr), zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, heartbeatTimeout)
+	defer cancel()
+
+	err = s.udpHeartbeatUseCase.ProcessHeartbeat(ctx, heartbeat)
+	switch {
+	case err == nil:
+	case errors.Is(err, domainerrors.ErrInvalidHeartbeatSignature),
+		errors.Is(err, domainerrors.ErrHeartbeatReplayed),
+		errors.Is(err, domainerrors.ErrServerNotFound),
+		errors.Is(err, domainerrors.ErrServerPendingApproval),
+		errors.Is(err, domainerrors.ErrInvalidMetrics):
+		s.logger.Debug("Heartbeat datagram rejected",
+			zap.String("server_id", heartbeat.Metrics.ServerID),
+			zap.Stringer("addr", addr),
+			zap.Error(err))
+	default:
+		s.logger.Error("Failed to process heartbeat datagram",
+			zap.String("server_id", heartbeat.Metrics.ServerID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,7 @@
+package udp
+
+import "github.com/google/wire"
+
+var WireSet = wire.NewSet(
+	NewServer,
+)
@@ -2,11 +2,15 @@ package delivery
 
 import (
 	"github.com/google/wire"
+	"github.com/th1enq/server_management_system/internal/delivery/consumer"
 	"github.com/th1enq/server_management_system/internal/delivery/http"
 	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+	"github.com/th1enq/server_management_system/internal/delivery/udp"
 )
 
 var WireSet = wire.NewSet(
 	http.WireSet,
 	middleware.WireSet,
+	udp.WireSet,
+	consumer.WireSet,
 )
@@ -0,0 +1,24 @@
+package entity
+
+const (
+	CollectorCPU  = "cpu"
+	CollectorRAM  = "ram"
+	CollectorDisk = "disk"
+)
+
+// AllCollectors are enabled when a server does not restrict them
+var AllCollectors = []string{CollectorCPU, CollectorRAM, CollectorDisk}
+
+// AgentSettings is the part of the agent configuration stored with the server.
+// The reporting interval is the server IntervalTime.
+type AgentSettings struct {
+	Collectors []string        `json:"collectors,omitempty"`
+	Thresholds AgentThresholds `json:"thresholds"`
+}
+
+// AgentThresholds are usage percentages, zero disables a threshold
+type AgentThresholds struct {
+	CPU  int `json:"cpu"`
+	RAM  int `json:"ram"`
+	Disk int `json:"disk"`
+}
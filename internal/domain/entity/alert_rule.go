@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+type AlertState string
+
+const (
+	AlertStatePending  AlertState = "pending"
+	AlertStateFiring   AlertState = "firing"
+	AlertStateResolved AlertState = "resolved"
+)
+
+type AlertSeverity string
+
+const (
+	AlertSeverityWarning  AlertSeverity = "warning"
+	AlertSeverityCritical AlertSeverity = "critical"
+)
+
+// CustomMetricPrefix selects a custom gauge, e.g. custom.queue_depth
+const CustomMetricPrefix = "custom."
+
+// AlertMetrics are the sample fields a rule can watch besides custom gauges
+var AlertMetrics = []string{
+	"cpu", "ram", "disk",
+	"load1", "load5", "load15",
+	"processes", "net_rx_bytes_per_sec", "net_tx_bytes_per_sec",
+}
+
+// AlertRule fires when Metric compared to Threshold holds for the For duration
+// on a server matching the Location and Tags selectors. Empty selectors match
+// every server.
+type AlertRule struct {
+	ID        uint
+	Name      string
+	Metric    string
+	Operator  string
+	Threshold float64
+	For       time.Duration
+	Location  string
+	Tags      []string
+	Severity  AlertSeverity
+	Enabled   bool
+	CreatedBy uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AlertRuleState is the state of a rule on one server
+type AlertRuleState struct {
+	RuleID       uint
+	ServerID     string
+	State        AlertState
+	Value        float64
+	PendingSince time.Time
+	FiredAt      *time.Time
+	ResolvedAt   *time.Time
+	UpdatedAt    time.Time
+}
+
+func IsAlertMetric(metric string) bool {
+	if name, ok := strings.CutPrefix(metric, CustomMetricPrefix); ok {
+		return name != ""
+	}
+	for _, m := range AlertMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *AlertRule) HasSelector() bool {
+	return r.Location != "" || len(r.Tags) > 0
+}
+
+// Matches reports whether the server is in the scope of the rule
+func (r *AlertRule) Matches(server *Server) bool {
+	if r.Location != "" && r.Location != server.Location {
+		return false
+	}
+	for _, tag := range r.Tags {
+		found := false
+		for _, serverTag := range server.Tags {
+			if serverTag == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Breached reports whether the value meets the rule condition
+func (r *AlertRule) Breached(value float64) bool {
+	switch r.Operator {
+	case ">":
+		return value > r.Threshold
+	case ">=":
+		return value >= r.Threshold
+	case "<":
+		return value < r.Threshold
+	case "<=":
+		return value <= r.Threshold
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// EnrollmentToken allows a server to register itself. The plain token is only
+// known at creation time, the hash is stored.
+type EnrollmentToken struct {
+	ID              uint
+	Name            string
+	TokenHash       string
+	Location        string
+	Tags            []string
+	MaxUses         int
+	UsedCount       int
+	RequireApproval bool
+	ExpiresAt       *time.Time
+	Revoked         bool
+	CreatedBy       uint
+	CreatedAt       time.Time
+}
@@ -0,0 +1,66 @@
+package entity
+
+import "time"
+
+// MaintenanceWindow puts the servers in its scope in MAINTENANCE while it is
+// active. A one-off window runs from StartsAt to EndsAt, a recurring one
+// starts on each Schedule tick (standard cron) and lasts Duration.
+// The scope is a server, a location and/or tags, at least one must be set.
+type MaintenanceWindow struct {
+	ID       uint
+	Name     string
+	ServerID string
+	Location string
+	Tags     []string
+
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	Schedule string
+	Duration time.Duration
+
+	Enabled   bool
+	CreatedBy uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// MaintenanceOccurrence is one run of a window
+type MaintenanceOccurrence struct {
+	Window *MaintenanceWindow
+	Start  time.Time
+	End    time.Time
+}
+
+func (w *MaintenanceWindow) IsRecurring() bool {
+	return w.Schedule != ""
+}
+
+func (w *MaintenanceWindow) HasScope() bool {
+	return w.ServerID != "" || w.Location != "" || len(w.Tags) > 0
+}
+
+// Matches reports whether the server is in the scope of the window
+func (w *MaintenanceWindow) Matches(server *Server) bool {
+	if !w.HasScope() {
+		return false
+	}
+	if w.ServerID != "" && w.ServerID != server.ServerID {
+		return false
+	}
+	if w.Location != "" && w.Location != server.Location {
+		return false
+	}
+	for _, tag := range w.Tags {
+		found := false
+		for _, serverTag := range server.Tags {
+			if serverTag == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+type ProbeType string
+
+const (
+	ProbeTypeTCP  ProbeType = "tcp"
+	ProbeTypeHTTP ProbeType = "http"
+)
+
+// Probe checks a server from the outside, for servers without an agent. A
+// TCP probe connects to Port on the server IPv4, an HTTP probe sends a GET to
+// URL and expects ExpectedStatus and a body matching the BodyMatch regexp.
+type Probe struct {
+	ID             uint
+	ServerID       uint
+	Type           ProbeType
+	Port           int
+	URL            string
+	ExpectedStatus int
+	BodyMatch      string
+	Timeout        time.Duration
+	Enabled        bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	// Result of the last run, LastCheckedAt is nil until the probe ran
+	LastCheckedAt *time.Time
+	LastSuccess   bool
+	LastLatency   time.Duration
+	LastError     string
+}
+
+// ProbeTarget is a server together with its enabled probes
+type ProbeTarget struct {
+	Server *Server
+	Probes []*Probe
+}
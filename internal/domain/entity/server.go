@@ -8,8 +8,66 @@ const (
 	ServerStatusOn        ServerStatus = "ON"
 	ServerStatusOff       ServerStatus = "OFF"
 	ServerStatusUndefined ServerStatus = "UNDEFINED"
+
+	ServerStatusPendingApproval ServerStatus = "PENDING_APPROVAL"
+
+	// ServerStatusDegraded is a server that sends heartbeats while one of
+	// its alert rules is firing
+	ServerStatusDegraded ServerStatus = "DEGRADED"
+	// ServerStatusMaintenance is set and cleared manually or by a maintenance
+	// window, heartbeats and their absence leave it untouched
+	ServerStatusMaintenance ServerStatus = "MAINTENANCE"
+	// ServerStatusUnreachable is a server that cannot be reached because a
+	// server it depends on is down
+	ServerStatusUnreachable ServerStatus = "UNREACHABLE"
+	// ServerStatusFlapping is a server going up and down too often, it is
+	// kept until the changes calm down
+	ServerStatusFlapping ServerStatus = "FLAPPING"
 )
 
+// serverStatusTransitions lists the statuses each status may move to
+var serverStatusTransitions = map[ServerStatus][]ServerStatus{
+	ServerStatusUndefined:       {ServerStatusOn, ServerStatusOff, ServerStatusDegraded, ServerStatusMaintenance, ServerStatusUnreachable, ServerStatusFlapping},
+	ServerStatusPendingApproval: {ServerStatusOff},
+	ServerStatusOn:              {ServerStatusOff, ServerStatusDegraded, ServerStatusMaintenance, ServerStatusUnreachable, ServerStatusFlapping},
+	ServerStatusDegraded:        {ServerStatusOn, ServerStatusOff, ServerStatusMaintenance, ServerStatusUnreachable, ServerStatusFlapping},
+	ServerStatusOff:             {ServerStatusOn, ServerStatusDegraded, ServerStatusMaintenance, ServerStatusUnreachable, ServerStatusFlapping},
+	ServerStatusUnreachable:     {ServerStatusOn, ServerStatusDegraded, ServerStatusOff, ServerStatusMaintenance, ServerStatusFlapping},
+	ServerStatusMaintenance:     {ServerStatusOff},
+	ServerStatusFlapping:        {ServerStatusOn, ServerStatusDegraded, ServerStatusOff, ServerStatusMaintenance, ServerStatusUnreachable},
+}
+
+func (s ServerStatus) IsValid() bool {
+	_, ok := serverStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a server may move from s to next
+func (s ServerStatus) CanTransitionTo(next ServerStatus) bool {
+	for _, status := range serverStatusTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUp reports whether a server in this status is sending heartbeats
+func (s ServerStatus) IsUp() bool {
+	return s == ServerStatusOn || s == ServerStatusDegraded
+}
+
+// ServerStatusesTo returns the statuses that may move to status
+func ServerStatusesTo(status ServerStatus) []ServerStatus {
+	from := make([]ServerStatus, 0, len(serverStatusTransitions))
+	for current := range serverStatusTransitions {
+		if current.CanTransitionTo(status) {
+			from = append(from, current)
+		}
+	}
+	return from
+}
+
 type Server struct {
 	ID           uint
 	ServerID     string
@@ -19,6 +77,26 @@ type Server struct {
 	Description  string
 	Location     string
 	OS           string
+	Tags         []string
 	IntervalTime int64
 	CreatedAt    time.Time
+
+	AgentConfig        AgentSettings
+	AgentConfigVersion int64
+
+	// ScrapeURL is a Prometheus text format endpoint polled instead of
+	// waiting for the server to push its metrics
+	ScrapeURL string
+
+	// HeartbeatKey signs the UDP heartbeats of the server. It is never
+	// serialized, server lists are returned as entities.
+	HeartbeatKey string `json:"-"`
+
+	// StatusChangedAt is when Status last changed, LastSeenAt is the time of
+	// the last heartbeat, written at most once per minute while online
+	StatusChangedAt *time.Time
+	LastSeenAt      *time.Time
+
+	// StatusPolicy overrides the global status policy for this server
+	StatusPolicy *StatusPolicy
 }
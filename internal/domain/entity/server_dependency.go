@@ -0,0 +1,9 @@
+package entity
+
+// ServerDependency makes the server ServerID depend on the server ParentID,
+// both are server IDs as in Server.ID. While a parent is down, the servers
+// depending on it are UNREACHABLE instead of OFF.
+type ServerDependency struct {
+	ServerID uint
+	ParentID uint
+}
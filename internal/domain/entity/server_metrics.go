@@ -1,8 +1,100 @@
 package entity
 
+import (
+	"strings"
+	"time"
+)
+
 type ServerMetrics struct {
-	ServerID string
-	CPU      int
-	RAM      int
-	Disk     int
+	ServerID      string
+	SchemaVersion int
+	CPU           int
+	RAM           int
+	Disk          int
+	Timestamp     time.Time
+
+	// Only reported by schema version 2, nil otherwise
+	Load1            *float64
+	Load5            *float64
+	Load15           *float64
+	CPUCores         []float64
+	Mounts           []MountUsage
+	NetRxBytesPerSec *float64
+	NetTxBytesPerSec *float64
+	Processes        *int
+	UptimeSeconds    *int64
+	Custom           map[string]float64
+}
+
+type MountUsage struct {
+	Path       string  `json:"path"`
+	UsedBytes  int64   `json:"used_bytes"`
+	TotalBytes int64   `json:"total_bytes"`
+	Percent    float64 `json:"percent"`
+}
+
+// Value returns the named metric of the sample, false when it was not reported.
+// Custom gauges are named custom.<gauge>.
+func (m *ServerMetrics) Value(metric string) (float64, bool) {
+	if name, ok := strings.CutPrefix(metric, CustomMetricPrefix); ok {
+		value, found := m.Custom[name]
+		return value, found
+	}
+	switch metric {
+	case "cpu":
+		return float64(m.CPU), true
+	case "ram":
+		return float64(m.RAM), true
+	case "disk":
+		return float64(m.Disk), true
+	case "load1":
+		return floatValue(m.Load1)
+	case "load5":
+		return floatValue(m.Load5)
+	case "load15":
+		return floatValue(m.Load15)
+	case "net_rx_bytes_per_sec":
+		return floatValue(m.NetRxBytesPerSec)
+	case "net_tx_bytes_per_sec":
+		return floatValue(m.NetTxBytesPerSec)
+	case "processes":
+		if m.Processes == nil {
+			return 0, false
+		}
+		return float64(*m.Processes), true
+	}
+	return 0, false
+}
+
+func floatValue(value *float64) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+	return *value, true
+}
+
+// MetricsAggregate holds the avg/min/max of one metric over a bucket
+type MetricsAggregate struct {
+	Avg float64
+	Min float64
+	Max float64
+}
+
+// MetricsBucket is one step-aligned bucket of a metrics series. The extended
+// aggregates are nil when no sample of the bucket reported them.
+type MetricsBucket struct {
+	Timestamp       time.Time
+	Samples         int64
+	ExtendedSamples int64
+	CPU             MetricsAggregate
+	RAM             MetricsAggregate
+	Disk            MetricsAggregate
+
+	Load1     *MetricsAggregate
+	Load5     *MetricsAggregate
+	Load15    *MetricsAggregate
+	NetRx     *MetricsAggregate
+	NetTx     *MetricsAggregate
+	Processes *MetricsAggregate
+	Custom    map[string]MetricsAggregate
 }
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// ServerStatusEvent is a status a server entered at ChangedAt, it lasts until
+// the next event of the server. Backfilled events come from buffered agent
+// samples and leave the current status untouched. RootCause is the server_id
+// of the down server an UNREACHABLE status is attributed to.
+type ServerStatusEvent struct {
+	ID         uint64
+	ServerID   string
+	Status     ServerStatus
+	ChangedAt  time.Time
+	Backfilled bool
+	RootCause  string
+}
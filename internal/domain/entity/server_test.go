@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from ServerStatus
+		to   ServerStatus
+		want bool
+	}{
+		{ServerStatusUndefined, ServerStatusOn, true},
+		{ServerStatusUndefined, ServerStatusPendingApproval, false},
+		{ServerStatusPendingApproval, ServerStatusOff, true},
+		{ServerStatusPendingApproval, ServerStatusOn, false},
+		{ServerStatusPendingApproval, ServerStatusMaintenance, false},
+		{ServerStatusOn, ServerStatusOff, true},
+		{ServerStatusOn, ServerStatusDegraded, true},
+		{ServerStatusOn, ServerStatusUnreachable, true},
+		{ServerStatusOn, ServerStatusFlapping, true},
+		{ServerStatusOn, ServerStatusOn, false},
+		{ServerStatusOn, ServerStatusPendingApproval, false},
+		{ServerStatusOn, ServerStatusUndefined, false},
+		{ServerStatusDegraded, ServerStatusOn, true},
+		{ServerStatusOff, ServerStatusOn, true},
+		{ServerStatusOff, ServerStatusOff, false},
+		{ServerStatusUnreachable, ServerStatusOff, true},
+		{ServerStatusUnreachable, ServerStatusUnreachable, false},
+		// Maintenance is only left to OFF, the next heartbeat turns it ON
+		{ServerStatusMaintenance, ServerStatusOff, true},
+		{ServerStatusMaintenance, ServerStatusOn, false},
+		{ServerStatusMaintenance, ServerStatusUnreachable, false},
+		{ServerStatusMaintenance, ServerStatusFlapping, false},
+		{ServerStatusFlapping, ServerStatusOn, true},
+		{ServerStatusFlapping, ServerStatusMaintenance, true},
+		{ServerStatusFlapping, ServerStatusFlapping, false},
+		{ServerStatus("UNKNOWN"), ServerStatusOn, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestServerStatusTransitionsAreValid(t *testing.T) {
+	for from, targets := range serverStatusTransitions {
+		for _, to := range targets {
+			assert.True(t, to.IsValid(), "%s -> %s", from, to)
+			assert.NotEqual(t, from, to, "%s moves to itself", from)
+		}
+	}
+	// Nothing moves back to pending approval or undefined
+	for from := range serverStatusTransitions {
+		assert.False(t, from.CanTransitionTo(ServerStatusPendingApproval), string(from))
+		assert.False(t, from.CanTransitionTo(ServerStatusUndefined), string(from))
+	}
+}
+
+func TestServerStatusesTo(t *testing.T) {
+	tests := []struct {
+		to   ServerStatus
+		want []ServerStatus
+	}{
+		{
+			to:   ServerStatusMaintenance,
+			want: []ServerStatus{ServerStatusUndefined, ServerStatusOn, ServerStatusDegraded, ServerStatusOff, ServerStatusUnreachable, ServerStatusFlapping},
+		},
+		{
+			to: ServerStatusOff,
+			want: []ServerStatus{ServerStatusUndefined, ServerStatusPendingApproval, ServerStatusOn, ServerStatusDegraded,
+				ServerStatusUnreachable, ServerStatusMaintenance, ServerStatusFlapping},
+		},
+		{
+			to:   ServerStatusPendingApproval,
+			want: []ServerStatus{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.to), func(t *testing.T) {
+			got := ServerStatusesTo(tt.to)
+			sortStatuses(got)
+			sortStatuses(tt.want)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServerStatusIsUp(t *testing.T) {
+	up := map[ServerStatus]bool{ServerStatusOn: true, ServerStatusDegraded: true}
+	for status := range serverStatusTransitions {
+		assert.Equal(t, up[status], status.IsUp(), string(status))
+	}
+}
+
+func sortStatuses(statuses []ServerStatus) {
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+// StatusPolicy tunes how heartbeats drive the status of a server.
+// A server turns OFF once it missed MissedIntervals heartbeat intervals, each
+// stretched by GraceMultiplier, and back ON after RecoveryHeartbeats
+// consecutive heartbeats. Changing between up and down FlapThreshold times
+// within FlapWindow makes it FLAPPING, a zero FlapThreshold disables flap
+// detection. Reports count it as online when its uptime reaches
+// OnlineThreshold percent.
+type StatusPolicy struct {
+	MissedIntervals    int           `json:"missed_intervals,omitempty"`
+	GraceMultiplier    float64       `json:"grace_multiplier,omitempty"`
+	RecoveryHeartbeats int           `json:"recovery_heartbeats,omitempty"`
+	OnlineThreshold    float64       `json:"online_threshold,omitempty"`
+	FlapThreshold      int           `json:"flap_threshold,omitempty"`
+	FlapWindow         time.Duration `json:"flap_window,omitempty"`
+}
+
+// Override returns the policy with the non-zero fields of override applied
+func (p StatusPolicy) Override(override *StatusPolicy) StatusPolicy {
+	if override == nil {
+		return p
+	}
+	if override.MissedIntervals > 0 {
+		p.MissedIntervals = override.MissedIntervals
+	}
+	if override.GraceMultiplier > 0 {
+		p.GraceMultiplier = override.GraceMultiplier
+	}
+	if override.RecoveryHeartbeats > 0 {
+		p.RecoveryHeartbeats = override.RecoveryHeartbeats
+	}
+	if override.OnlineThreshold > 0 {
+		p.OnlineThreshold = override.OnlineThreshold
+	}
+	if override.FlapThreshold > 0 {
+		p.FlapThreshold = override.FlapThreshold
+	}
+	if override.FlapWindow > 0 {
+		p.FlapWindow = override.FlapWindow
+	}
+	return p
+}
+
+// IsZero reports whether the policy overrides nothing
+func (p StatusPolicy) IsZero() bool {
+	return p == StatusPolicy{}
+}
+
+// HeartbeatWindow is how long a server heartbeating every intervalTime
+// seconds stays up after a heartbeat
+func (p StatusPolicy) HeartbeatWindow(intervalTime int64) time.Duration {
+	return time.Duration(float64(p.MissedIntervals) * p.GraceMultiplier * float64(intervalTime) * float64(time.Second))
+}
+
+// DetectsFlapping reports whether flap detection is enabled
+func (p StatusPolicy) DetectsFlapping() bool {
+	return p.FlapThreshold > 0 && p.FlapWindow > 0
+}
@@ -5,11 +5,45 @@ import "errors"
 // Domain errors
 var (
 	// Server errors
-	ErrServerNotFound      = errors.New("server not found")
-	ErrServerAlreadyExists = errors.New("server already exists")
-	ErrInvalidServerID     = errors.New("invalid server ID")
-	ErrInvalidServerName   = errors.New("invalid server name")
-	ErrInvalidIPv4         = errors.New("invalid IPv4 address")
+	ErrServerNotFound           = errors.New("server not found")
+	ErrServerAlreadyExists      = errors.New("server already exists")
+	ErrInvalidServerID          = errors.New("invalid server ID")
+	ErrInvalidServerName        = errors.New("invalid server name")
+	ErrInvalidIPv4              = errors.New("invalid IPv4 address")
+	ErrServerPendingApproval    = errors.New("server is pending approval")
+	ErrServerNotPendingApproval = errors.New("server is not pending approval")
+	ErrInvalidStatusTransition  = errors.New("invalid server status transition")
+	ErrDependencyCycle          = errors.New("server dependencies would form a cycle")
+
+	// Enrollment errors
+	ErrEnrollmentTokenNotFound = errors.New("enrollment token not found")
+	ErrInvalidEnrollmentToken  = errors.New("invalid enrollment token")
+
+	// Metrics errors
+	ErrInvalidTimeRange = errors.New("invalid time range")
+	ErrInvalidStep      = errors.New("invalid step")
+	ErrFutureTimestamp  = errors.New("timestamp is in the future")
+	ErrInvalidMetrics   = errors.New("invalid metrics")
+
+	// Alert errors
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+	// Probe errors
+	ErrProbeNotFound = errors.New("probe not found")
+
+	// Scrape and probe target errors
+	ErrTargetNotAllowed = errors.New("target address not allowed")
+
+	// Maintenance window errors
+	ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+
+	// Token errors
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+
+	// Heartbeat errors
+	ErrInvalidHeartbeatSignature = errors.New("invalid heartbeat signature")
+	ErrHeartbeatReplayed         = errors.New("heartbeat replayed or outside the replay window")
 
 	// User errors
 	ErrUserNotFound            = errors.New("user not found")
@@ -1,6 +1,10 @@
 package query
 
-import "github.com/th1enq/server_management_system/internal/domain/entity"
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
 
 type ServerFilter struct {
 	ServerID    string
@@ -13,4 +17,9 @@ type ServerFilter struct {
 	CPU         int
 	RAM         int
 	Disk        int
+
+	StatusChangedAfter  time.Time
+	StatusChangedBefore time.Time
+	LastSeenAfter       time.Time
+	LastSeenBefore      time.Time
 }
@@ -0,0 +1,11 @@
+package query
+
+import "time"
+
+type MetricsRange struct {
+	From time.Time
+	To   time.Time
+	Step time.Duration
+	// Gauges are the custom gauge names to aggregate
+	Gauges []string
+}
@@ -9,12 +9,37 @@ type ServerUpTime struct {
 	AvgUpTime float64
 }
 
+// MaintenanceWindow is a run of a maintenance window during the report
+type MaintenanceWindow struct {
+	Name  string
+	Scope string
+	Start time.Time
+	End   time.Time
+}
+
+// RootCause is a server whose outage made its dependents UNREACHABLE
+type RootCause struct {
+	ServerID        string
+	AffectedServers int
+	UnreachableTime time.Duration
+}
+
+// DailyReport counts servers up at least the online threshold of their
+// status policy (70% by default) of the time outside maintenance as online.
+// Servers in maintenance for the whole day are only counted in
+// MaintenanceCount and left out of the average uptime. Time spent UNREACHABLE
+// is attributed to the root cause instead of the server, a server unreachable
+// for the whole day is counted offline and left out of the average uptime.
 type DailyReport struct {
-	StartOfDay   time.Time
-	EndOfDay     time.Time
-	TotalServers int64
-	OnlineCount  int64
-	OfflineCount int64
-	AvgUptime    float64
-	Detail       []ServerUpTime
+	StartOfDay       time.Time
+	EndOfDay         time.Time
+	TotalServers     int64
+	OnlineCount      int64
+	OfflineCount     int64
+	MaintenanceCount int64
+	AvgUptime        float64
+	Detail           []ServerUpTime
+
+	MaintenanceWindows []MaintenanceWindow
+	RootCauses         []RootCause
 }
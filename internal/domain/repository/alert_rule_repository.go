@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type AlertRuleRepository interface {
+	Create(ctx context.Context, rule *entity.AlertRule) error
+	GetByID(ctx context.Context, id uint) (*entity.AlertRule, error)
+	List(ctx context.Context) ([]*entity.AlertRule, error)
+	ListEnabled(ctx context.Context) ([]*entity.AlertRule, error)
+	Update(ctx context.Context, rule *entity.AlertRule) error
+	Delete(ctx context.Context, id uint) error
+
+	GetStates(ctx context.Context, serverID string, ruleIDs []uint) ([]*entity.AlertRuleState, error)
+	ListStates(ctx context.Context, ruleID uint) ([]*entity.AlertRuleState, error)
+	CountFiring(ctx context.Context, serverID string) (int64, error)
+	// SaveState upserts the state. With notify, the transition is published
+	// through the outbox in the same transaction.
+	SaveState(ctx context.Context, rule *entity.AlertRule, state *entity.AlertRuleState, notify bool) error
+	DeleteState(ctx context.Context, ruleID uint, serverID string) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type EnrollmentTokenRepository interface {
+	Create(ctx context.Context, token *entity.EnrollmentToken) error
+	GetByID(ctx context.Context, id uint) (*entity.EnrollmentToken, error)
+	List(ctx context.Context) ([]*entity.EnrollmentToken, error)
+	Revoke(ctx context.Context, id uint) error
+	// Consume atomically takes one use of a valid token and returns it.
+	// It returns nil when the token is unknown, revoked, expired or used up.
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*entity.EnrollmentToken, error)
+	// Release gives back a use taken by Consume
+	Release(ctx context.Context, id uint) error
+}
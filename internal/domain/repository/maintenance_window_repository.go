@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type MaintenanceWindowRepository interface {
+	Create(ctx context.Context, window *entity.MaintenanceWindow) error
+	GetByID(ctx context.Context, id uint) (*entity.MaintenanceWindow, error)
+	List(ctx context.Context) ([]*entity.MaintenanceWindow, error)
+	ListEnabled(ctx context.Context) ([]*entity.MaintenanceWindow, error)
+	Update(ctx context.Context, window *entity.MaintenanceWindow) error
+	Delete(ctx context.Context, id uint) error
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/query"
+)
+
+type MetricsRepository interface {
+	Insert(ctx context.Context, metrics *entity.ServerMetrics) error
+	BatchInsert(ctx context.Context, metrics []*entity.ServerMetrics) error
+	QuerySeries(ctx context.Context, serverID string, metricsRange query.MetricsRange) ([]entity.MetricsBucket, error)
+}
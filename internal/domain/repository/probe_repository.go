@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type ProbeRepository interface {
+	Create(ctx context.Context, probe *entity.Probe) error
+	GetByID(ctx context.Context, id uint) (*entity.Probe, error)
+	ListByServer(ctx context.Context, serverID uint) ([]*entity.Probe, error)
+	Update(ctx context.Context, probe *entity.Probe) error
+	Delete(ctx context.Context, id uint) error
+
+	// ListTargets returns the approved servers having enabled probes
+	ListTargets(ctx context.Context) ([]*entity.ProbeTarget, error)
+	// SaveResult only writes the result of the last run, so it never undoes
+	// a concurrent edit of the probe
+	SaveResult(ctx context.Context, probe *entity.Probe) error
+}
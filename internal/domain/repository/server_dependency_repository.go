@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type ServerDependencyRepository interface {
+	// SetParents replaces the parents of the server with ID id. It fails with
+	// ErrDependencyCycle when a parent already depends on the server, directly
+	// or not.
+	SetParents(ctx context.Context, id uint, parentIDs []uint) error
+	// ListParents and ListChildren take the server_id of the server
+	ListParents(ctx context.Context, serverID string) ([]*entity.Server, error)
+	ListChildren(ctx context.Context, serverID string) ([]*entity.Server, error)
+	List(ctx context.Context) ([]entity.ServerDependency, error)
+}
@@ -11,13 +11,34 @@ import (
 type ServerRepository interface {
 	Create(ctx context.Context, server *entity.Server) error
 	GetByID(ctx context.Context, id uint) (*entity.Server, error)
+	// GetByServerID returns ErrServerNotFound when no server has the ID
 	GetByServerID(ctx context.Context, serverID string) (*entity.Server, error)
 	GetByServerName(ctx context.Context, serverName string) (*entity.Server, error)
 	Delete(ctx context.Context, id uint) error
 	Update(ctx context.Context, server *entity.Server) error
 	List(ctx context.Context, filter query.ServerFilter, pagination query.Pagination) ([]*entity.Server, int64, error)
 	BatchCreate(ctx context.Context, servers []entity.Server) ([]*entity.Server, error)
-	UpdateStatus(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) error
+	// UpdateStatus sets the status only if the current one may move to it and
+	// publishes the change. It reports whether the status changed. A server
+	// in MAINTENANCE or FLAPPING is never moved by it.
+	UpdateStatus(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) (bool, error)
+	// ResolveFlapping moves a FLAPPING server to status
+	ResolveFlapping(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) (bool, error)
+	// EnterMaintenance puts the server in MAINTENANCE, held by the window when
+	// windowID is set or by an admin otherwise
+	EnterMaintenance(ctx context.Context, serverID string, windowID *uint, timestamp time.Time) (bool, error)
+	// LeaveMaintenance turns a server in MAINTENANCE OFF. With scheduledOnly a
+	// server put in maintenance by an admin is left untouched.
+	LeaveMaintenance(ctx context.Context, serverID string, scheduledOnly bool, timestamp time.Time) (bool, error)
+	// MarkUnreachable moves the server to UNREACHABLE because of the down
+	// server rootCause, like UpdateStatus. The change is recorded in the
+	// status history but not published.
+	MarkUnreachable(ctx context.Context, serverID string, rootCause string, timestamp time.Time) (bool, error)
+	// TouchLastSeen moves the last heartbeat time forward
+	TouchLastSeen(ctx context.Context, serverID string, timestamp time.Time) error
+	// RecordStatusEvent adds a backfilled event to the status history and
+	// publishes it without changing the current status
+	RecordStatusEvent(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) error
 	CountByStatus(ctx context.Context, status entity.ServerStatus) (int64, error)
 	CountAll(ctx context.Context) (int64, error)
 	GetAll(ctx context.Context) ([]*entity.Server, error)
@@ -25,5 +46,10 @@ type ServerRepository interface {
 	GetByIPv4(ctx context.Context, ipv4 string) (*entity.Server, error)
 	ExecuteRawQuery(ctx context.Context, query string, args ...interface{}) error
 	GetServerIDs(ctx context.Context) ([]string, error)
+	ListByStatus(ctx context.Context, status entity.ServerStatus) ([]*entity.Server, error)
+	// ListInMaintenanceWindow returns the servers held in MAINTENANCE by a window
+	ListInMaintenanceWindow(ctx context.Context) ([]*entity.Server, error)
+	// ListScrapeTargets returns the approved servers having a scrape URL
+	ListScrapeTargets(ctx context.Context) ([]*entity.Server, error)
 	GetIntervalTime(ctx context.Context, serverID string) (int64, error)
 }
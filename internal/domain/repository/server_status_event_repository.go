@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/query"
+)
+
+// ServerStatusEventRepository reads the status history written along with
+// the status changes by ServerRepository
+type ServerStatusEventRepository interface {
+	// List returns a page of the events of the server in [from, to), newest
+	// first, and the number of events in the range
+	List(ctx context.Context, serverID string, from, to time.Time, pagination query.Pagination) ([]*entity.ServerStatusEvent, int64, error)
+	// ListBetween returns every event of the server in [from, to], oldest first
+	ListBetween(ctx context.Context, serverID string, from, to time.Time) ([]*entity.ServerStatusEvent, error)
+	// LastBefore returns the last event of the server before t, nil if there
+	// is none
+	LastBefore(ctx context.Context, serverID string, t time.Time) (*entity.ServerStatusEvent, error)
+}
@@ -10,4 +10,10 @@ type TokenRepository interface {
 	IsTokenWhitelisted(ctx context.Context, token string) bool
 	RemoveTokenFromWhitelist(ctx context.Context, token string) error
 	RemoveUserTokensFromWhitelist(ctx context.Context, userID uint) error
+	AddServerTokenToWhitelist(ctx context.Context, token string, serverID string, expiration time.Duration) error
+	RemoveServerTokensFromWhitelist(ctx context.Context, serverID string) error
+	RemoveServerTokenFromWhitelist(ctx context.Context, serverID string, token string) error
+	MarkServerTokenUsed(ctx context.Context, token string, expiration time.Duration) (bool, error)
+	IsServerTokenUsed(ctx context.Context, token string) (bool, error)
+	UnmarkServerTokenUsed(ctx context.Context, token string) error
 }
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+type MetricsScraper interface {
+	// Scrape fetches a Prometheus text format endpoint. Every sample is
+	// stamped with the time of the scrape.
+	Scrape(ctx context.Context, url string) ([]dto.PrometheusTimeSeries, error)
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+// ErrProbeCheckFailed wraps the failures of the checks of a probe, like an
+// unexpected status. Unlike connection errors their message is safe to show.
+var ErrProbeCheckFailed = errors.New("probe check failed")
+
+type Prober interface {
+	// Probe runs the probe against the server and returns its latency
+	Probe(ctx context.Context, probe *entity.Probe, server *entity.Server) (time.Duration, error)
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+	"net"
+)
+
+type TargetPolicy interface {
+	// Allows reports whether scrapes and probes may connect to ip
+	Allows(ip net.IP) bool
+	// CheckHost resolves host and returns ErrTargetNotAllowed if one of its
+	// addresses is not allowed. Hosts that do not resolve are accepted, the
+	// policy is enforced again on every connection.
+	CheckHost(ctx context.Context, host string) error
+}
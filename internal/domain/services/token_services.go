@@ -13,4 +13,5 @@ type TokenServices interface {
 	GenerateServerAccessToken(ctx context.Context, server *entity.Server) (string, error)
 	GenerateServerRefreshToken(ctx context.Context, server *entity.Server) (string, error)
 	ValidateToken(tokenString string) (*dto.Claims, error)
+	ValidateServerToken(tokenString string) (*dto.ServerClaims, error)
 }
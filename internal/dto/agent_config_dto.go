@@ -0,0 +1,46 @@
+package dto
+
+import "github.com/th1enq/server_management_system/internal/domain/entity"
+
+// AgentConfig is the configuration document delivered to agents on heartbeat.
+// Agents apply it when Version is newer than the one they run.
+type AgentConfig struct {
+	Version      int64           `json:"version"`
+	IntervalTime int64           `json:"interval_time"` // in seconds
+	Collectors   []string        `json:"collectors"`
+	Thresholds   AgentThresholds `json:"thresholds"`
+}
+
+type AgentThresholds struct {
+	CPU  int `json:"cpu" binding:"gte=0,lte=100"`
+	RAM  int `json:"ram" binding:"gte=0,lte=100"`
+	Disk int `json:"disk" binding:"gte=0,lte=100"`
+}
+
+type UpdateAgentConfigRequest struct {
+	IntervalTime int64            `json:"interval_time,omitempty" binding:"omitempty,gte=1"`
+	Collectors   []string         `json:"collectors,omitempty" binding:"omitempty,min=1,dive,oneof=cpu ram disk"`
+	Thresholds   *AgentThresholds `json:"thresholds,omitempty"`
+}
+
+// MonitoringResponse is returned to agents after a heartbeat
+type MonitoringResponse struct {
+	Config *AgentConfig `json:"config,omitempty"`
+}
+
+func FromEntityToAgentConfig(server *entity.Server) *AgentConfig {
+	collectors := server.AgentConfig.Collectors
+	if len(collectors) == 0 {
+		collectors = entity.AllCollectors
+	}
+	return &AgentConfig{
+		Version:      server.AgentConfigVersion,
+		IntervalTime: server.IntervalTime,
+		Collectors:   collectors,
+		Thresholds: AgentThresholds{
+			CPU:  server.AgentConfig.Thresholds.CPU,
+			RAM:  server.AgentConfig.Thresholds.RAM,
+			Disk: server.AgentConfig.Thresholds.Disk,
+		},
+	}
+}
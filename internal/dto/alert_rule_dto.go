@@ -0,0 +1,87 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type CreateAlertRuleRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Metric    string   `json:"metric" binding:"required"`
+	Operator  string   `json:"operator" binding:"required,oneof=> >= < <="`
+	Threshold float64  `json:"threshold"`
+	For       string   `json:"for,omitempty"` // Go duration, e.g. 5m
+	Location  string   `json:"location,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Severity  string   `json:"severity,omitempty" binding:"omitempty,oneof=warning critical"`
+	Enabled   *bool    `json:"enabled,omitempty"`
+}
+
+type UpdateAlertRuleRequest struct {
+	Name      string   `json:"name,omitempty"`
+	Metric    string   `json:"metric,omitempty"`
+	Operator  string   `json:"operator,omitempty" binding:"omitempty,oneof=> >= < <="`
+	Threshold *float64 `json:"threshold,omitempty"`
+	For       *string  `json:"for,omitempty"`
+	Location  *string  `json:"location,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Severity  string   `json:"severity,omitempty" binding:"omitempty,oneof=warning critical"`
+	Enabled   *bool    `json:"enabled,omitempty"`
+}
+
+type AlertRuleResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Metric    string    `json:"metric"`
+	Operator  string    `json:"operator"`
+	Threshold float64   `json:"threshold"`
+	For       string    `json:"for"`
+	Location  string    `json:"location,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Severity  string    `json:"severity"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AlertStateResponse struct {
+	RuleID       uint       `json:"rule_id"`
+	ServerID     string     `json:"server_id"`
+	State        string     `json:"state"`
+	Value        float64    `json:"value"`
+	PendingSince time.Time  `json:"pending_since"`
+	FiredAt      *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func FromEntityToAlertRuleResponse(rule *entity.AlertRule) AlertRuleResponse {
+	return AlertRuleResponse{
+		ID:        rule.ID,
+		Name:      rule.Name,
+		Metric:    rule.Metric,
+		Operator:  rule.Operator,
+		Threshold: rule.Threshold,
+		For:       rule.For.String(),
+		Location:  rule.Location,
+		Tags:      rule.Tags,
+		Severity:  string(rule.Severity),
+		Enabled:   rule.Enabled,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
+
+func FromEntityToAlertStateResponse(state *entity.AlertRuleState) AlertStateResponse {
+	return AlertStateResponse{
+		RuleID:       state.RuleID,
+		ServerID:     state.ServerID,
+		State:        string(state.State),
+		Value:        state.Value,
+		PendingSince: state.PendingSince,
+		FiredAt:      state.FiredAt,
+		ResolvedAt:   state.ResolvedAt,
+		UpdatedAt:    state.UpdatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package dto
+
+import "github.com/th1enq/server_management_system/internal/domain/entity"
+
+// UpdateDependenciesRequest replaces the parents of a server, an empty list
+// removes them
+type UpdateDependenciesRequest struct {
+	ParentIDs []uint `json:"parent_ids" binding:"max=100,dive,gt=0"`
+}
+
+type ServerNode struct {
+	ID         uint                `json:"id"`
+	ServerID   string              `json:"server_id"`
+	ServerName string              `json:"server_name"`
+	Status     entity.ServerStatus `json:"status"`
+}
+
+type ServerDependenciesResponse struct {
+	Server   ServerNode   `json:"server"`
+	Parents  []ServerNode `json:"parents"`
+	Children []ServerNode `json:"children"`
+}
+
+// TopologyEdge makes the server ServerID depend on the server ParentID
+type TopologyEdge struct {
+	ServerID uint `json:"server_id"`
+	ParentID uint `json:"parent_id"`
+}
+
+// TopologyResponse is the dependency graph, nodes are the servers having a
+// parent or a child
+type TopologyResponse struct {
+	Nodes []ServerNode   `json:"nodes"`
+	Edges []TopologyEdge `json:"edges"`
+}
+
+func FromEntityToServerNode(server *entity.Server) ServerNode {
+	return ServerNode{
+		ID:         server.ID,
+		ServerID:   server.ServerID,
+		ServerName: server.ServerName,
+		Status:     server.Status,
+	}
+}
+
+func FromEntitiesToServerNodes(servers []*entity.Server) []ServerNode {
+	nodes := make([]ServerNode, 0, len(servers))
+	for _, server := range servers {
+		nodes = append(nodes, FromEntityToServerNode(server))
+	}
+	return nodes
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type CreateEnrollmentTokenRequest struct {
+	Name            string     `json:"name" binding:"required"`
+	Location        string     `json:"location,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+	MaxUses         int        `json:"max_uses,omitempty" binding:"omitempty,gte=1"`
+	RequireApproval bool       `json:"require_approval"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+}
+
+type EnrollmentTokenResponse struct {
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Location        string     `json:"location,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+	MaxUses         int        `json:"max_uses"`
+	UsedCount       int        `json:"used_count"`
+	RequireApproval bool       `json:"require_approval"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	Revoked         bool       `json:"revoked"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// CreateEnrollmentTokenResponse carries the plain token, it is only returned once
+type CreateEnrollmentTokenResponse struct {
+	EnrollmentTokenResponse
+	Token string `json:"token"`
+}
+
+func FromEntityToEnrollmentTokenResponse(token *entity.EnrollmentToken) EnrollmentTokenResponse {
+	return EnrollmentTokenResponse{
+		ID:              token.ID,
+		Name:            token.Name,
+		Location:        token.Location,
+		Tags:            token.Tags,
+		MaxUses:         token.MaxUses,
+		UsedCount:       token.UsedCount,
+		RequireApproval: token.RequireApproval,
+		ExpiresAt:       token.ExpiresAt,
+		Revoked:         token.Revoked,
+		CreatedAt:       token.CreatedAt,
+	}
+}
@@ -9,6 +9,8 @@ type AuthResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	TokenType    string `json:"token_type"`
+	// HeartbeatKey signs UDP heartbeats, only issued to servers
+	HeartbeatKey string `json:"heartbeat_key,omitempty"`
 }
 
 type Claims struct {
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+// A window is either one-off, with starts_at and ends_at, or recurring, with
+// a standard cron schedule and a duration. The scope is a server, a location
+// and/or tags.
+type CreateMaintenanceWindowRequest struct {
+	Name     string     `json:"name" binding:"required"`
+	ServerID string     `json:"server_id,omitempty"`
+	Location string     `json:"location,omitempty"`
+	Tags     []string   `json:"tags,omitempty"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+	Schedule string     `json:"schedule,omitempty"` // e.g. 0 2 * * SUN
+	Duration string     `json:"duration,omitempty"` // Go duration, e.g. 2h
+	Enabled  *bool      `json:"enabled,omitempty"`
+}
+
+type UpdateMaintenanceWindowRequest struct {
+	Name     string     `json:"name,omitempty"`
+	ServerID *string    `json:"server_id,omitempty"`
+	Location *string    `json:"location,omitempty"`
+	Tags     []string   `json:"tags,omitempty"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+	Schedule *string    `json:"schedule,omitempty"`
+	Duration *string    `json:"duration,omitempty"`
+	Enabled  *bool      `json:"enabled,omitempty"`
+}
+
+type MaintenanceWindowResponse struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	ServerID  string     `json:"server_id,omitempty"`
+	Location  string     `json:"location,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	Schedule  string     `json:"schedule,omitempty"`
+	Duration  string     `json:"duration,omitempty"`
+	Enabled   bool       `json:"enabled"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func FromEntityToMaintenanceWindowResponse(window *entity.MaintenanceWindow, active bool) MaintenanceWindowResponse {
+	response := MaintenanceWindowResponse{
+		ID:        window.ID,
+		Name:      window.Name,
+		ServerID:  window.ServerID,
+		Location:  window.Location,
+		Tags:      window.Tags,
+		StartsAt:  window.StartsAt,
+		EndsAt:    window.EndsAt,
+		Schedule:  window.Schedule,
+		Enabled:   window.Enabled,
+		Active:    active,
+		CreatedAt: window.CreatedAt,
+		UpdatedAt: window.UpdatedAt,
+	}
+	if window.IsRecurring() {
+		response.Duration = window.Duration.String()
+	}
+	return response
+}
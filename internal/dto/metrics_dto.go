@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+// MetricsQuery for querying a server's metrics history via query parameters
+type MetricsQuery struct {
+	From   time.Time `form:"from"`
+	To     time.Time `form:"to"`
+	Step   string    `form:"step"`
+	Gauges []string  `form:"gauges" binding:"omitempty,max=20,dive,min=1,max=64"`
+}
+
+type MetricsAggregate struct {
+	Avg float64 `json:"avg"`
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// MetricsPoint is one step of the series, aggregates are null when no samples were received.
+// Extended aggregates are only computed over the schema version 2 samples of the step
+// and are omitted when there are none.
+type MetricsPoint struct {
+	Timestamp       time.Time                    `json:"timestamp"`
+	Samples         int64                        `json:"samples"`
+	ExtendedSamples int64                        `json:"extended_samples"`
+	CPU             *MetricsAggregate            `json:"cpu"`
+	RAM             *MetricsAggregate            `json:"ram"`
+	Disk            *MetricsAggregate            `json:"disk"`
+	Load1           *MetricsAggregate            `json:"load1,omitempty"`
+	Load5           *MetricsAggregate            `json:"load5,omitempty"`
+	Load15          *MetricsAggregate            `json:"load15,omitempty"`
+	NetRx           *MetricsAggregate            `json:"net_rx_bytes_per_sec,omitempty"`
+	NetTx           *MetricsAggregate            `json:"net_tx_bytes_per_sec,omitempty"`
+	Processes       *MetricsAggregate            `json:"processes,omitempty"`
+	Custom          map[string]*MetricsAggregate `json:"custom,omitempty"`
+}
+
+type MetricsSeriesResponse struct {
+	ServerID string         `json:"server_id"`
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Step     string         `json:"step"`
+	Points   []MetricsPoint `json:"points"`
+}
+
+func FromEntityToMetricsPoint(bucket entity.MetricsBucket) MetricsPoint {
+	point := MetricsPoint{
+		Timestamp:       bucket.Timestamp,
+		Samples:         bucket.Samples,
+		ExtendedSamples: bucket.ExtendedSamples,
+		CPU:             fromEntityToMetricsAggregate(bucket.CPU),
+		RAM:             fromEntityToMetricsAggregate(bucket.RAM),
+		Disk:            fromEntityToMetricsAggregate(bucket.Disk),
+		Load1:           fromNullableMetricsAggregate(bucket.Load1),
+		Load5:           fromNullableMetricsAggregate(bucket.Load5),
+		Load15:          fromNullableMetricsAggregate(bucket.Load15),
+		NetRx:           fromNullableMetricsAggregate(bucket.NetRx),
+		NetTx:           fromNullableMetricsAggregate(bucket.NetTx),
+		Processes:       fromNullableMetricsAggregate(bucket.Processes),
+	}
+	if len(bucket.Custom) > 0 {
+		point.Custom = make(map[string]*MetricsAggregate, len(bucket.Custom))
+		for name, aggregate := range bucket.Custom {
+			point.Custom[name] = fromEntityToMetricsAggregate(aggregate)
+		}
+	}
+	return point
+}
+
+func fromNullableMetricsAggregate(aggregate *entity.MetricsAggregate) *MetricsAggregate {
+	if aggregate == nil {
+		return nil
+	}
+	return fromEntityToMetricsAggregate(*aggregate)
+}
+
+func fromEntityToMetricsAggregate(aggregate entity.MetricsAggregate) *MetricsAggregate {
+	return &MetricsAggregate{
+		Avg: aggregate.Avg,
+		Min: aggregate.Min,
+		Max: aggregate.Max,
+	}
+}
+
+const (
+	MetricsBatchItemAccepted = "accepted"
+	MetricsBatchItemRejected = "rejected"
+)
+
+type MetricsBatchItemResult struct {
+	Index    int    `json:"index"`
+	ServerID string `json:"server_id,omitempty"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	// Retryable is set when the sample was rejected by a server fault rather
+	// than for its content, sending it again later may succeed
+	Retryable bool `json:"retryable,omitempty"`
+}
+
+type MetricsBatchResponse struct {
+	Accepted int                      `json:"accepted"`
+	Rejected int                      `json:"rejected"`
+	Results  []MetricsBatchItemResult `json:"results"`
+	Config   *AgentConfig             `json:"config,omitempty"`
+}
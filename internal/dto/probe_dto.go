@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type CreateProbeRequest struct {
+	Type           string `json:"type" binding:"required,oneof=tcp http"`
+	Port           int    `json:"port,omitempty" binding:"omitempty,gte=1,lte=65535"` // tcp
+	URL            string `json:"url,omitempty" binding:"omitempty,http_url"`         // http
+	ExpectedStatus int    `json:"expected_status,omitempty" binding:"omitempty,gte=100,lte=599"`
+	BodyMatch      string `json:"body_match,omitempty"` // regexp
+	Timeout        string `json:"timeout,omitempty"`    // Go duration, e.g. 5s
+	Enabled        *bool  `json:"enabled,omitempty"`
+}
+
+type UpdateProbeRequest struct {
+	Port           *int    `json:"port,omitempty" binding:"omitempty,gte=1,lte=65535"`
+	URL            *string `json:"url,omitempty" binding:"omitempty,http_url"`
+	ExpectedStatus *int    `json:"expected_status,omitempty" binding:"omitempty,gte=100,lte=599"`
+	BodyMatch      *string `json:"body_match,omitempty"`
+	Timeout        *string `json:"timeout,omitempty"`
+	Enabled        *bool   `json:"enabled,omitempty"`
+}
+
+type ProbeResponse struct {
+	ID             uint       `json:"id"`
+	Type           string     `json:"type"`
+	Port           int        `json:"port,omitempty"`
+	URL            string     `json:"url,omitempty"`
+	ExpectedStatus int        `json:"expected_status,omitempty"`
+	BodyMatch      string     `json:"body_match,omitempty"`
+	Timeout        string     `json:"timeout"`
+	Enabled        bool       `json:"enabled"`
+	LastCheckedAt  *time.Time `json:"last_checked_at,omitempty"`
+	LastSuccess    bool       `json:"last_success"`
+	LastLatencyMs  int64      `json:"last_latency_ms"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func FromEntityToProbeResponse(probe *entity.Probe) ProbeResponse {
+	return ProbeResponse{
+		ID:             probe.ID,
+		Type:           string(probe.Type),
+		Port:           probe.Port,
+		URL:            probe.URL,
+		ExpectedStatus: probe.ExpectedStatus,
+		BodyMatch:      probe.BodyMatch,
+		Timeout:        probe.Timeout.String(),
+		Enabled:        probe.Enabled,
+		LastCheckedAt:  probe.LastCheckedAt,
+		LastSuccess:    probe.LastSuccess,
+		LastLatencyMs:  probe.LastLatency.Milliseconds(),
+		LastError:      probe.LastError,
+		CreatedAt:      probe.CreatedAt,
+		UpdatedAt:      probe.UpdatedAt,
+	}
+}
+
+func FromEntitiesToProbeResponses(probes []*entity.Probe) []ProbeResponse {
+	responses := make([]ProbeResponse, 0, len(probes))
+	for _, probe := range probes {
+		responses = append(responses, FromEntityToProbeResponse(probe))
+	}
+	return responses
+}
@@ -0,0 +1,23 @@
+package dto
+
+// PrometheusTimeSeries is one series of a Prometheus remote_write request
+type PrometheusTimeSeries struct {
+	Labels  map[string]string
+	Samples []PrometheusSample
+}
+
+type PrometheusSample struct {
+	Value     float64
+	Timestamp int64 // in milliseconds
+}
+
+// RemoteWriteResult summarizes a remote write request. Series and Samples
+// count what Prometheus sent, Accepted and Rejected count the metrics samples
+// mapped from it, one per server and scrape timestamp.
+type RemoteWriteResult struct {
+	Series         int      `json:"series"`
+	Samples        int      `json:"samples"`
+	Accepted       int      `json:"accepted"`
+	Rejected       int      `json:"rejected"`
+	UnknownServers []string `json:"unknown_servers,omitempty"`
+}
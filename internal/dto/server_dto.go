@@ -9,42 +9,158 @@ import (
 type EsStatus struct {
 	Status    entity.ServerStatus `json:"status"`
 	Timestamp time.Time           `json:"timestamp"`
+	RootCause string              `json:"root_cause,omitempty"`
+}
+
+// ServerStatusMessage is the body of the server_status_updates events
+type ServerStatusMessage struct {
+	ServerID  string              `json:"server_id"`
+	Status    entity.ServerStatus `json:"status"`
+	Timestamp time.Time           `json:"timestamp"`
+	RootCause string              `json:"root_cause,omitempty"`
+	// Backfilled changes were replayed from late samples, they are not the
+	// current status
+	Backfilled bool `json:"backfilled,omitempty"`
 }
 
 type RegisterMetricsRequest struct {
-	ServerID     string `json:"server_id" binding:"required"`
-	ServerName   string `json:"server_name" binding:"required"`
-	Description  string `json:"description,omitempty"`
-	Location     string `json:"location,omitempty"`
-	OS           string `json:"os,omitempty"`
-	IntervalTime int64  `json:"interval_time,omitempty" binding:"omitempty,gte=1"` // in seconds
+	EnrollmentToken string   `json:"enrollment_token" binding:"required"`
+	ServerID        string   `json:"server_id" binding:"required"`
+	ServerName      string   `json:"server_name" binding:"required"`
+	Description     string   `json:"description,omitempty"`
+	Location        string   `json:"location,omitempty"`
+	OS              string   `json:"os,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	IntervalTime    int64    `json:"interval_time,omitempty" binding:"omitempty,gte=1"` // in seconds
 }
 
+const (
+	MetricsSchemaV1 = 1
+	MetricsSchemaV2 = 2
+)
+
+// MetricsRequest is one monitoring sample. Version 1 (schema_version omitted)
+// only carries the cpu/ram/disk percentages, version 2 adds the extended fields.
 type MetricsRequest struct {
-	ServerID  string    `json:"server_id" binding:"required"`
-	CPU       int       `json:"cpu" binding:"required,gte=0"`
-	RAM       int       `json:"ram" binding:"required,gte=0"`
-	Disk      int       `json:"disk" binding:"required,gte=0"`
-	Timestamp time.Time `json:"timestamp" binding:"required"`
+	SchemaVersion int       `json:"schema_version,omitempty" binding:"omitempty,oneof=1 2"`
+	ServerID      string    `json:"server_id" binding:"required"`
+	CPU           int       `json:"cpu" binding:"gte=0,lte=100"`
+	RAM           int       `json:"ram" binding:"gte=0,lte=100"`
+	Disk          int       `json:"disk" binding:"gte=0,lte=100"`
+	Timestamp     time.Time `json:"timestamp" binding:"required"`
+
+	// Schema version 2
+	Load          *LoadAverage       `json:"load,omitempty"`
+	CPUCores      []float64          `json:"cpu_cores,omitempty" binding:"omitempty,max=1024,dive,gte=0,lte=100"`
+	Mounts        []MountUsage       `json:"mounts,omitempty" binding:"omitempty,max=64,dive"`
+	Network       *NetworkUsage      `json:"network,omitempty"`
+	Processes     *int               `json:"processes,omitempty" binding:"omitempty,gte=0"`
+	UptimeSeconds *int64             `json:"uptime_seconds,omitempty" binding:"omitempty,gte=0"`
+	Custom        map[string]float64 `json:"custom,omitempty" binding:"omitempty,max=64"`
+}
+
+type LoadAverage struct {
+	Load1  float64 `json:"load1" binding:"gte=0"`
+	Load5  float64 `json:"load5" binding:"gte=0"`
+	Load15 float64 `json:"load15" binding:"gte=0"`
+}
+
+type MountUsage struct {
+	Path       string  `json:"path" binding:"required"`
+	UsedBytes  int64   `json:"used_bytes" binding:"gte=0"`
+	TotalBytes int64   `json:"total_bytes" binding:"gte=0"`
+	Percent    float64 `json:"percent" binding:"gte=0,lte=100"`
+}
+
+// NetworkUsage is the throughput over all interfaces since the previous sample
+type NetworkUsage struct {
+	RxBytesPerSec float64 `json:"rx_bytes_per_sec" binding:"gte=0"`
+	TxBytesPerSec float64 `json:"tx_bytes_per_sec" binding:"gte=0"`
+}
+
+// Version returns the schema version, payloads without one are version 1
+func (m MetricsRequest) Version() int {
+	if m.SchemaVersion == 0 {
+		return MetricsSchemaV1
+	}
+	return m.SchemaVersion
+}
+
+// HasExtendedFields reports whether any schema version 2 field is set
+func (m MetricsRequest) HasExtendedFields() bool {
+	return m.Load != nil || len(m.CPUCores) > 0 || len(m.Mounts) > 0 || m.Network != nil ||
+		m.Processes != nil || m.UptimeSeconds != nil || len(m.Custom) > 0
+}
+
+func ToServerMetricsEntity(m MetricsRequest) *entity.ServerMetrics {
+	metrics := &entity.ServerMetrics{
+		ServerID:      m.ServerID,
+		SchemaVersion: m.Version(),
+		CPU:           m.CPU,
+		RAM:           m.RAM,
+		Disk:          m.Disk,
+		Timestamp:     m.Timestamp,
+		CPUCores:      m.CPUCores,
+		Processes:     m.Processes,
+		UptimeSeconds: m.UptimeSeconds,
+		Custom:        m.Custom,
+	}
+	if m.Load != nil {
+		metrics.Load1 = &m.Load.Load1
+		metrics.Load5 = &m.Load.Load5
+		metrics.Load15 = &m.Load.Load15
+	}
+	if m.Network != nil {
+		metrics.NetRxBytesPerSec = &m.Network.RxBytesPerSec
+		metrics.NetTxBytesPerSec = &m.Network.TxBytesPerSec
+	}
+	for _, mount := range m.Mounts {
+		metrics.Mounts = append(metrics.Mounts, entity.MountUsage{
+			Path:       mount.Path,
+			UsedBytes:  mount.UsedBytes,
+			TotalBytes: mount.TotalBytes,
+			Percent:    mount.Percent,
+		})
+	}
+	return metrics
 }
 
 type CreateServerRequest struct {
-	ServerID     string `json:"server_id" binding:"required"`
-	ServerName   string `json:"server_name" binding:"required"`
-	IPv4         string `json:"ipv4" binding:"required,ipv4"`
-	Description  string `json:"description,omitempty"`
-	Location     string `json:"location,omitempty"`
-	OS           string `json:"os,omitempty"`
-	IntervalTime int64  `json:"interval_time,omitempty" binding:"omitempty,gte=1"` // in seconds
+	ServerID     string   `json:"server_id" binding:"required"`
+	ServerName   string   `json:"server_name" binding:"required"`
+	IPv4         string   `json:"ipv4" binding:"required,ipv4"`
+	Description  string   `json:"description,omitempty"`
+	Location     string   `json:"location,omitempty"`
+	OS           string   `json:"os,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	IntervalTime int64    `json:"interval_time,omitempty" binding:"omitempty,gte=1"` // in seconds
+	ScrapeURL    string   `json:"scrape_url,omitempty" binding:"omitempty,http_url"`
 }
 
 type UpdateServerRequest struct {
-	ServerName   string `json:"server_name,omitempty"`
-	IPv4         string `json:"ipv4" binding:"omitempty,ipv4"`
-	Description  string `json:"description,omitempty"`
-	Location     string `json:"location,omitempty"`
-	OS           string `json:"os,omitempty"`
-	IntervalTime int64  `json:"interval_time,omitempty" binding:"omitempty,gte=1"`
+	ServerName   string   `json:"server_name,omitempty"`
+	IPv4         string   `json:"ipv4" binding:"omitempty,ipv4"`
+	Description  string   `json:"description,omitempty"`
+	Location     string   `json:"location,omitempty"`
+	OS           string   `json:"os,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	IntervalTime int64    `json:"interval_time,omitempty" binding:"omitempty,gte=1"`
+	// ScrapeURL switches the server to pull mode, an empty string back to push
+	ScrapeURL *string `json:"scrape_url,omitempty" binding:"omitempty,len=0|http_url"`
+	// StatusPolicy replaces the overrides of the global status policy, an
+	// empty object drops them
+	StatusPolicy *StatusPolicy `json:"status_policy,omitempty"`
+}
+
+// StatusPolicy holds the fields of the global status policy a server
+// overrides, zero fields follow the global policy
+type StatusPolicy struct {
+	MissedIntervals    int     `json:"missed_intervals,omitempty" binding:"omitempty,gte=1"`
+	GraceMultiplier    float64 `json:"grace_multiplier,omitempty" binding:"omitempty,gte=1"`
+	RecoveryHeartbeats int     `json:"recovery_heartbeats,omitempty" binding:"omitempty,gte=1"`
+	OnlineThreshold    float64 `json:"online_threshold,omitempty" binding:"omitempty,gt=0,lte=100"`
+	FlapThreshold      int     `json:"flap_threshold,omitempty" binding:"omitempty,gte=2"`
+	FlapWindow         string  `json:"flap_window,omitempty"` // Go duration, e.g. 10m
 }
 
 // ServerFilter for filtering servers via query parameters
@@ -55,6 +171,11 @@ type ServerFilter struct {
 	IPv4       string              `form:"ipv4" binding:"omitempty,ipv4"`
 	Location   string              `form:"location"`
 	Disk       int                 `form:"disk" binding:"omitempty,gte=0"`
+
+	StatusChangedAfter  time.Time `form:"status_changed_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	StatusChangedBefore time.Time `form:"status_changed_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	LastSeenAfter       time.Time `form:"last_seen_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	LastSeenBefore      time.Time `form:"last_seen_before" time_format:"2006-01-02T15:04:05Z07:00"`
 }
 
 // ServerResponse for API responses
@@ -66,12 +187,31 @@ type ServerResponse struct {
 	Description string              `json:"description,omitempty"`
 	Location    string              `json:"location,omitempty"`
 	OS          string              `json:"os,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	ScrapeURL   string              `json:"scrape_url,omitempty"`
+
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	LastSeenAt      *time.Time `json:"last_seen_at,omitempty"`
+
+	StatusPolicy *StatusPolicy `json:"status_policy,omitempty"`
 }
 
+// UpdateServerStatusRequest puts a server in maintenance or takes it out,
+// every other status is derived from heartbeats
+type UpdateServerStatusRequest struct {
+	Status entity.ServerStatus `json:"status" binding:"required,oneof=MAINTENANCE OFF"`
+}
+
+// ServerStatusResponse counts DEGRADED servers as online and UNREACHABLE
+// ones as offline, MAINTENANCE and FLAPPING servers are neither
 type ServerStatusResponse struct {
-	TotalCount   int64 `json:"total_count"`
-	OnlineCount  int64 `json:"online_count"`
-	OfflineCount int64 `json:"offline_count"`
+	TotalCount       int64 `json:"total_count"`
+	OnlineCount      int64 `json:"online_count"`
+	OfflineCount     int64 `json:"offline_count"`
+	DegradedCount    int64 `json:"degraded_count"`
+	MaintenanceCount int64 `json:"maintenance_count"`
+	UnreachableCount int64 `json:"unreachable_count"`
+	FlappingCount    int64 `json:"flapping_count"`
 }
 
 // Pagination parameters (for query)
@@ -110,7 +250,31 @@ func FromEntityToServerResponse(server *entity.Server) *ServerResponse {
 		Description: server.Description,
 		Location:    server.Location,
 		OS:          server.OS,
+		Tags:        server.Tags,
+		ScrapeURL:   server.ScrapeURL,
+
+		StatusChangedAt: server.StatusChangedAt,
+		LastSeenAt:      server.LastSeenAt,
+
+		StatusPolicy: FromEntityToStatusPolicy(server.StatusPolicy),
+	}
+}
+
+func FromEntityToStatusPolicy(policy *entity.StatusPolicy) *StatusPolicy {
+	if policy == nil {
+		return nil
+	}
+	response := &StatusPolicy{
+		MissedIntervals:    policy.MissedIntervals,
+		GraceMultiplier:    policy.GraceMultiplier,
+		RecoveryHeartbeats: policy.RecoveryHeartbeats,
+		OnlineThreshold:    policy.OnlineThreshold,
+		FlapThreshold:      policy.FlapThreshold,
+	}
+	if policy.FlapWindow > 0 {
+		response.FlapWindow = policy.FlapWindow.String()
 	}
+	return response
 }
 
 func FromEntityListToServerResponseList(servers []entity.Server) []*ServerResponse {
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+// StatusHistoryQuery for querying a server's status timeline via query parameters
+type StatusHistoryQuery struct {
+	From     time.Time `form:"from"`
+	To       time.Time `form:"to"`
+	Page     int       `form:"page" binding:"omitempty,gte=1"`
+	PageSize int       `form:"page_size" binding:"omitempty,gte=1,lte=1000"`
+}
+
+type StatusEventResponse struct {
+	Status    entity.ServerStatus `json:"status"`
+	ChangedAt time.Time           `json:"changed_at"`
+	// Backfilled events were replayed from buffered agent samples
+	Backfilled bool `json:"backfilled,omitempty"`
+}
+
+// StatusHistoryResponse lists the status changes of the range, newest first
+type StatusHistoryResponse struct {
+	ServerID string                `json:"server_id"`
+	From     time.Time             `json:"from"`
+	To       time.Time             `json:"to"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	Size     int                   `json:"size"`
+	Events   []StatusEventResponse `json:"events"`
+}
+
+func FromEntityToStatusEventResponse(event *entity.ServerStatusEvent) StatusEventResponse {
+	return StatusEventResponse{
+		Status:     event.Status,
+		ChangedAt:  event.ChangedAt,
+		Backfilled: event.Backfilled,
+	}
+}
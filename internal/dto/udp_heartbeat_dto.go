@@ -0,0 +1,9 @@
+package dto
+
+// SignedHeartbeat is a heartbeat received as a UDP datagram. Payload is the
+// signed part of the datagram.
+type SignedHeartbeat struct {
+	Metrics   MetricsRequest
+	Payload   []byte
+	Signature []byte
+}
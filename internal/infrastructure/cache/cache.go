@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -18,13 +19,35 @@ var (
 
 type CacheClient interface {
 	Set(ctx context.Context, key string, data any, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, data any, ttl time.Duration) (bool, error)
+	// ExpireIfEqual resets the expiry of the key to ttl if it holds data
+	ExpireIfEqual(ctx context.Context, key string, data any, ttl time.Duration) (bool, error)
+	// DelIfEqual deletes the key if it holds data
+	DelIfEqual(ctx context.Context, key string, data any) (bool, error)
 	Get(ctx context.Context, key string, dest any) error
+	// Incr increments a counter and resets its expiry to ttl
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
 	Del(ctx context.Context, key string) error
 	SADD(ctx context.Context, key string, members ...string) error
 	SMEMBERS(ctx context.Context, key string) ([]string, error)
+	SISMEMBER(ctx context.Context, key string, member string) (bool, error)
+	SREM(ctx context.Context, key string, members ...string) error
 	Expire(ctx context.Context, key string, ttl time.Duration) error
 	HMGet(ctx context.Context, key string) (map[string]string, error)
 	HSET(ctx context.Context, key string, values map[string]string) error
+	ZADD(ctx context.Context, key string, score float64, member string) error
+	// ZADDNX adds the member only if it is not in the sorted set yet
+	ZADDNX(ctx context.Context, key string, score float64, member string) error
+	ZREM(ctx context.Context, key string, members ...string) error
+	ZSCORE(ctx context.Context, key string, member string) (float64, error)
+	// ZPopByScore atomically removes and returns up to count members scored
+	// at most max, lowest scores first
+	ZPopByScore(ctx context.Context, key string, max float64, count int64) ([]string, error)
+	ZCOUNT(ctx context.Context, key string, min float64, max float64) (int64, error)
+	// ZAddWindow adds the member, drops the members scored more than window
+	// (in milliseconds) below it and returns how many are left. The key
+	// expires after window.
+	ZAddWindow(ctx context.Context, key string, score float64, member string, window time.Duration) (int64, error)
 }
 
 type redisClient struct {
@@ -46,6 +69,34 @@ func (r *redisClient) Set(ctx context.Context, key string, data any, ttl time.Du
 	return nil
 }
 
+func (r *redisClient) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to increment Redis counter", zap.String("key", key), zap.Error(err))
+		return 0, fmt.Errorf("failed to increment Redis counter: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+func (r *redisClient) SetNX(ctx context.Context, key string, data any, ttl time.Duration) (bool, error) {
+	byte, err := json.Marshal(data)
+	if err != nil {
+		r.logger.Error("Failed to marshal data for Redis", zap.String("key", key), zap.Error(err))
+		return false, fmt.Errorf("failed to marshal data for Redis: %w", err)
+	}
+	ok, err := r.client.SetNX(ctx, key, byte, ttl).Result()
+	if err != nil {
+		r.logger.Error("Failed to set data in Redis", zap.String("key", key), zap.Error(err))
+		return false, fmt.Errorf("failed to set data in Redis: %w", err)
+	}
+	return ok, nil
+}
+
 func (r *redisClient) Get(ctx context.Context, key string, dest any) error {
 	byte, err := r.client.Get(ctx, key).Bytes()
 	if err != nil {
@@ -89,6 +140,23 @@ func (r *redisClient) SMEMBERS(ctx context.Context, key string) ([]string, error
 	return members, nil
 }
 
+func (r *redisClient) SISMEMBER(ctx context.Context, key string, member string) (bool, error) {
+	found, err := r.client.SIsMember(ctx, key, member).Result()
+	if err != nil {
+		r.logger.Error("Failed to check member of Redis set", zap.String("key", key), zap.Error(err))
+		return false, fmt.Errorf("failed to check member of Redis set: %w", err)
+	}
+	return found, nil
+}
+
+func (r *redisClient) SREM(ctx context.Context, key string, members ...string) error {
+	if err := r.client.SRem(ctx, key, members).Err(); err != nil {
+		r.logger.Error("Failed to remove members from Redis set", zap.String("key", key), zap.Error(err))
+		return fmt.Errorf("failed to remove members from Redis set: %w", err)
+	}
+	return nil
+}
+
 func (r *redisClient) Expire(ctx context.Context, key string, ttl time.Duration) error {
 	if err := r.client.Expire(ctx, key, ttl).Err(); err != nil {
 		r.logger.Error("Failed to set expiration for Redis key", zap.String("key", key), zap.Error(err))
@@ -118,6 +186,130 @@ func (r *redisClient) HSET(ctx context.Context, key string, values map[string]st
 	return nil
 }
 
+func (r *redisClient) ZADD(ctx context.Context, key string, score float64, member string) error {
+	if err := r.client.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err(); err != nil {
+		r.logger.Error("Failed to add member to Redis sorted set", zap.String("key", key), zap.Error(err))
+		return fmt.Errorf("failed to add member to Redis sorted set: %w", err)
+	}
+	return nil
+}
+
+func (r *redisClient) ZADDNX(ctx context.Context, key string, score float64, member string) error {
+	if err := r.client.ZAddNX(ctx, key, redis.Z{Score: score, Member: member}).Err(); err != nil {
+		r.logger.Error("Failed to add member to Redis sorted set", zap.String("key", key), zap.Error(err))
+		return fmt.Errorf("failed to add member to Redis sorted set: %w", err)
+	}
+	return nil
+}
+
+func (r *redisClient) ZREM(ctx context.Context, key string, members ...string) error {
+	if err := r.client.ZRem(ctx, key, members).Err(); err != nil {
+		r.logger.Error("Failed to remove members from Redis sorted set", zap.String("key", key), zap.Error(err))
+		return fmt.Errorf("failed to remove members from Redis sorted set: %w", err)
+	}
+	return nil
+}
+
+func (r *redisClient) ZSCORE(ctx context.Context, key string, member string) (float64, error) {
+	score, err := r.client.ZScore(ctx, key, member).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrCacheMiss
+		}
+		r.logger.Error("Failed to get member score from Redis sorted set", zap.String("key", key), zap.Error(err))
+		return 0, fmt.Errorf("failed to get member score from Redis sorted set: %w", err)
+	}
+	return score, nil
+}
+
+// zPopByScoreScript keeps the range and the removal in one step, so two
+// callers never pop the same member
+var zPopByScoreScript = redis.NewScript(`
+local members = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+if #members > 0 then
+	redis.call('ZREM', KEYS[1], unpack(members))
+end
+return members
+`)
+
+func (r *redisClient) ZPopByScore(ctx context.Context, key string, max float64, count int64) ([]string, error) {
+	members, err := zPopByScoreScript.Run(ctx, r.client, []string{key}, max, count).StringSlice()
+	if err != nil {
+		r.logger.Error("Failed to pop members from Redis sorted set", zap.String("key", key), zap.Error(err))
+		return nil, fmt.Errorf("failed to pop members from Redis sorted set: %w", err)
+	}
+	return members, nil
+}
+
+func (r *redisClient) ZCOUNT(ctx context.Context, key string, min float64, max float64) (int64, error) {
+	count, err := r.client.ZCount(ctx, key, strconv.FormatFloat(min, 'f', -1, 64), strconv.FormatFloat(max, 'f', -1, 64)).Result()
+	if err != nil {
+		r.logger.Error("Failed to count members of Redis sorted set", zap.String("key", key), zap.Error(err))
+		return 0, fmt.Errorf("failed to count members of Redis sorted set: %w", err)
+	}
+	return count, nil
+}
+
+var zAddWindowScript = redis.NewScript(`
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. (tonumber(ARGV[1]) - tonumber(ARGV[3])))
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return redis.call('ZCARD', KEYS[1])
+`)
+
+func (r *redisClient) ZAddWindow(ctx context.Context, key string, score float64, member string, window time.Duration) (int64, error) {
+	count, err := zAddWindowScript.Run(ctx, r.client, []string{key}, score, member, window.Milliseconds()).Int64()
+	if err != nil {
+		r.logger.Error("Failed to add member to Redis sorted set window", zap.String("key", key), zap.Error(err))
+		return 0, fmt.Errorf("failed to add member to Redis sorted set window: %w", err)
+	}
+	return count, nil
+}
+
+// expireIfEqualScript and delIfEqualScript compare and act in one step, so a
+// key set again by another client in between is left alone
+var expireIfEqualScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var delIfEqualScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func (r *redisClient) ExpireIfEqual(ctx context.Context, key string, data any, ttl time.Duration) (bool, error) {
+	byte, err := json.Marshal(data)
+	if err != nil {
+		r.logger.Error("Failed to marshal data for Redis", zap.String("key", key), zap.Error(err))
+		return false, fmt.Errorf("failed to marshal data for Redis: %w", err)
+	}
+	updated, err := expireIfEqualScript.Run(ctx, r.client, []string{key}, byte, ttl.Milliseconds()).Int64()
+	if err != nil {
+		r.logger.Error("Failed to set expiration for Redis key", zap.String("key", key), zap.Error(err))
+		return false, fmt.Errorf("failed to set expiration for Redis key: %w", err)
+	}
+	return updated == 1, nil
+}
+
+func (r *redisClient) DelIfEqual(ctx context.Context, key string, data any) (bool, error) {
+	byte, err := json.Marshal(data)
+	if err != nil {
+		r.logger.Error("Failed to marshal data for Redis", zap.String("key", key), zap.Error(err))
+		return false, fmt.Errorf("failed to marshal data for Redis: %w", err)
+	}
+	deleted, err := delIfEqualScript.Run(ctx, r.client, []string{key}, byte).Int64()
+	if err != nil {
+		r.logger.Error("Failed to delete data from Redis", zap.String("key", key), zap.Error(err))
+		return false, fmt.Errorf("failed to delete data from Redis: %w", err)
+	}
+	return deleted == 1, nil
+}
+
 func NewCache(cfg configs.Cache, logger *zap.Logger) (CacheClient, error) {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
@@ -1,15 +1,14 @@
 package database
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
-	"encoding/gob"
 	"fmt"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/th1enq/server_management_system/internal/configs"
 	"github.com/th1enq/server_management_system/internal/infrastructure/models"
 	"go.uber.org/zap"
@@ -39,12 +38,13 @@ type DatabaseClient interface {
 	Pluck(column string, dest interface{}) error
 	BatchCreateOnConflict(value interface{}, dest interface{}) error
 	Select(query string, args ...interface{}) DatabaseClient
+	Raw(query string, args ...interface{}) DatabaseClient
 	DB() (*sql.DB, error)
 
 	Transaction(ctx context.Context, fn func(tx DatabaseClient) error) error
 
 	GetRecordsByLockID(lockID string) ([]models.Record, error)
-	UpdateRecordLockByState(lockID string, lockedOn time.Time, state models.RecordState) error
+	UpdateRecordLockByState(lockID string, lockedOn time.Time, lockExpiry time.Time, state models.RecordState, limit int) error
 	UpdateRecordByID(message models.Record) error
 	ClearLocksWithDurationBeforeDate(time time.Time) error
 	ClearLocksByLockID(lockID string) error
@@ -105,40 +105,23 @@ func (p *gormDatabase) ClearLocksWithDurationBeforeDate(time time.Time) error {
 
 // GetRecordsByLockID implements DatabaseClient.
 func (p *gormDatabase) GetRecordsByLockID(lockID string) ([]models.Record, error) {
-	rows, err := p.client.Model(&models.Record{}).
+	var records []models.Record
+	err := p.client.Model(&models.Record{}).
 		Where("lock_id = ?", lockID).
-		Find(&[]models.Record{}).Rows()
+		Order("seq").
+		Find(&records).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get records by lock ID: %w", err)
 	}
-	defer rows.Close()
-
-	var messages []models.Record
-	for rows.Next() {
-		var rec models.Record
-		var data []byte
-		if err := rows.Scan(&rec.ID, &data, &rec.State, &rec.CreatedAt, &rec.LockID, &rec.LockedAt, &rec.ProcessedAt, &rec.NumberOfAttempts, &rec.LastAttemptAt, &rec.Error); err != nil {
-			if err == sql.ErrNoRows {
-				return messages, nil
-			}
-			return messages, fmt.Errorf("failed to scan record: %w", err)
-		}
-		decErr := gob.NewDecoder(bytes.NewBuffer(data)).Decode(&rec.Message)
-		if decErr != nil {
-			return nil, fmt.Errorf("failed to decode message: %w", decErr)
-		}
-		messages = append(messages, rec)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over rows: %w", err)
-	}
-	return messages, nil
+	return records, nil
 }
 
-// RemoveRecordsBeforeDatetime implements DatabaseClient.
+// RemoveRecordsBeforeDatetime implements DatabaseClient. Only records done
+// with, delivered or out of attempts, are removed; a record waiting for its
+// next attempt is kept however old it is.
 func (p *gormDatabase) RemoveRecordsBeforeDatetime(expiryTime time.Time) error {
 	err := p.client.Model(&models.Record{}).
-		Where("created_at < ?", expiryTime).
+		Where("created_at < ? AND processed_at IS NOT NULL", expiryTime).
 		Delete(&models.Record{}).Error
 	if err != nil {
 		return fmt.Errorf("failed to remove records before datetime: %w", err)
@@ -146,23 +129,19 @@ func (p *gormDatabase) RemoveRecordsBeforeDatetime(expiryTime time.Time) error {
 	return nil
 }
 
-// UpdateRecordByID implements DatabaseClient.
+// UpdateRecordByID implements DatabaseClient. The event itself is never
+// rewritten.
 func (p *gormDatabase) UpdateRecordByID(message models.Record) error {
-	msgData := new(bytes.Buffer)
-	enc := gob.NewEncoder(msgData)
-	if err := enc.Encode(message); err != nil {
-		return fmt.Errorf("failed to encode message: %w", err)
-	}
 	err := p.client.Model(&models.Record{}).
 		Where("id = ?", message.ID).
 		Updates(map[string]interface{}{
-			"message":            msgData.Bytes(),
 			"state":              message.State,
 			"locked_at":          message.LockedAt,
 			"lock_id":            message.LockID,
 			"processed_at":       message.ProcessedAt,
 			"number_of_attempts": message.NumberOfAttempts,
 			"last_attempt_at":    message.LastAttemptAt,
+			"next_attempt_at":    message.NextAttemptAt,
 			"error":              message.Error,
 		}).Error
 	if err != nil {
@@ -171,19 +150,91 @@ func (p *gormDatabase) UpdateRecordByID(message models.Record) error {
 	return nil
 }
 
-func (p *gormDatabase) UpdateRecordLockByState(lockID string, lockedOn time.Time, state models.RecordState) error {
-	err := p.client.Model(&models.Record{}).
-		Where("state = ?", state).
-		Updates(map[string]interface{}{
-			"locked_at": lockedOn,
-			"lock_id":   lockID,
-		}).Error
+// UpdateRecordLockByState locks up to limit of the oldest records in state
+// whose next attempt is due at lockedOn. Rows another dispatcher is locking
+// are skipped, and a record is only locked along with every earlier record in
+// state with the same key, so that the events of a key are sent in order.
+// Locks taken before lockExpiry belong to a dispatcher that stopped, they are
+// taken over without waiting for the unlocker.
+func (p *gormDatabase) UpdateRecordLockByState(lockID string, lockedOn time.Time, lockExpiry time.Time, state models.RecordState, limit int) error {
+	err := p.client.Transaction(func(tx *gorm.DB) error {
+		// Keys waiting on a record locked or backing off are left out before
+		// the limit, so they cannot fill the batch
+		var candidates []models.Record
+		err := tx.Raw(`SELECT id, message_key FROM outbox
+			WHERE state = ? AND (lock_id IS NULL OR locked_at < ?) AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
+			AND NOT EXISTS (SELECT 1 FROM outbox o2
+				WHERE o2.message_key = outbox.message_key AND o2.state = ? AND o2.seq < outbox.seq
+				AND ((o2.lock_id IS NOT NULL AND o2.locked_at >= ?) OR o2.next_attempt_at > ?))
+			ORDER BY seq
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED`, state, lockExpiry, lockedOn, state, lockExpiry, lockedOn, limit).
+			Scan(&candidates).Error
+		if err != nil || len(candidates) == 0 {
+			return err
+		}
+
+		keySet := make(map[string]bool)
+		keys := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			if !keySet[candidate.MessageKey] {
+				keySet[candidate.MessageKey] = true
+				keys = append(keys, candidate.MessageKey)
+			}
+		}
+
+		// A row skipped while another dispatcher locks it is not visible as
+		// locked yet, the records after it are dropped here
+		var pending []models.Record
+		err = tx.Raw(`SELECT id, message_key FROM outbox
+			WHERE state = ? AND message_key IN ?
+			ORDER BY seq`, state, keys).
+			Scan(&pending).Error
+		if err != nil {
+			return err
+		}
+		ids := lockableRecords(candidates, pending)
+		if len(ids) == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.Record{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"locked_at": lockedOn,
+				"lock_id":   lockID,
+			}).Error
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update record lock by state: %w", err)
 	}
 	return nil
 }
 
+// lockableRecords returns the candidates that come before the first pending
+// record of their key left out of the candidates. pending holds every record
+// in state of the candidate keys, in seq order.
+func lockableRecords(candidates, pending []models.Record) []uuid.UUID {
+	selected := make(map[uuid.UUID]bool, len(candidates))
+	for _, candidate := range candidates {
+		selected[candidate.ID] = true
+	}
+
+	blocked := make(map[string]bool)
+	ids := make([]uuid.UUID, 0, len(candidates))
+	for _, record := range pending {
+		if blocked[record.MessageKey] {
+			continue
+		}
+		if !selected[record.ID] {
+			blocked[record.MessageKey] = true
+			continue
+		}
+		ids = append(ids, record.ID)
+	}
+	return ids
+}
+
 func (p *gormDatabase) Count(count *int64) error {
 	if err := p.client.Count(count).Error; err != nil {
 		return fmt.Errorf("failed to count records: %w", err)
@@ -349,6 +400,12 @@ func (p *gormDatabase) Select(query string, args ...interface{}) DatabaseClient
 	}
 }
 
+func (p *gormDatabase) Raw(query string, args ...interface{}) DatabaseClient {
+	return &gormDatabase{
+		client: p.client.Raw(query, args...),
+	}
+}
+
 func (p *gormDatabase) Clauses(conds ...interface{}) DatabaseClient {
 	exprs := make([]clause.Expression, len(conds))
 	for i, cond := range conds {
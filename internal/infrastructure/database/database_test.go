@@ -0,0 +1,116 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+)
+
+// outboxRows returns pending records in seq order, named after their key and
+// position within it: a1 is the first record of key a
+func outboxRows(names ...string) map[string]models.Record {
+	rows := make(map[string]models.Record, len(names))
+	for i, name := range names {
+		rows[name] = models.Record{
+			ID:         uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1)),
+			MessageKey: name[:1],
+			Seq:        int64(i + 1),
+		}
+	}
+	return rows
+}
+
+func pick(rows map[string]models.Record, names ...string) []models.Record {
+	records := make([]models.Record, 0, len(names))
+	for _, name := range names {
+		records = append(records, rows[name])
+	}
+	return records
+}
+
+func ids(rows map[string]models.Record, names ...string) []uuid.UUID {
+	recordIDs := make([]uuid.UUID, 0, len(names))
+	for _, name := range names {
+		recordIDs = append(recordIDs, rows[name].ID)
+	}
+	return recordIDs
+}
+
+func TestLockableRecords(t *testing.T) {
+	rows := outboxRows("a1", "b1", "a2", "c1", "b2", "a3")
+
+	tests := []struct {
+		name       string
+		candidates []string
+		// pending is every record of the candidate keys still in state
+		pending []string
+		want    []string
+	}{
+		{
+			name:       "every record of the keys is a candidate",
+			candidates: []string{"a1", "b1", "a2", "c1", "b2", "a3"},
+			pending:    []string{"a1", "b1", "a2", "c1", "b2", "a3"},
+			want:       []string{"a1", "b1", "a2", "c1", "b2", "a3"},
+		},
+		{
+			// The limit cut the batch after b2, a3 waits for the next one
+			name:       "batch cut by the limit",
+			candidates: []string{"a1", "b1", "a2", "c1", "b2"},
+			pending:    []string{"a1", "b1", "a2", "c1", "b2", "a3"},
+			want:       []string{"a1", "b1", "a2", "c1", "b2"},
+		},
+		{
+			// a1 is being locked by another dispatcher whose transaction has
+			// not committed, SKIP LOCKED left it out but not a2 and a3
+			name:       "record skipped while another dispatcher locks it",
+			candidates: []string{"b1", "a2", "c1", "b2", "a3"},
+			pending:    []string{"a1", "b1", "a2", "c1", "b2", "a3"},
+			want:       []string{"b1", "c1", "b2"},
+		},
+		{
+			// b1 failed and waits for next_attempt_at, the candidate query
+			// already leaves b2 out and c1 still goes
+			name:       "key backing off",
+			candidates: []string{"a1", "a2", "c1", "a3"},
+			pending:    []string{"a1", "a2", "c1", "a3"},
+			want:       []string{"a1", "a2", "c1", "a3"},
+		},
+		{
+			// Same as above if a record of the key became due between the
+			// two queries
+			name:       "key backing off when its later record is a candidate",
+			candidates: []string{"a1", "a2", "c1", "b2", "a3"},
+			pending:    []string{"a1", "b1", "a2", "c1", "b2", "a3"},
+			want:       []string{"a1", "a2", "c1", "a3"},
+		},
+		{
+			name: "no candidates",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lockableRecords(pick(rows, tt.candidates...), pick(rows, tt.pending...))
+			assert.Equal(t, ids(rows, tt.want...), got)
+		})
+	}
+}
+
+// Two dispatchers running side by side never lock the same record, and each
+// takes the records of a key in order
+func TestLockableRecordsTwoDispatchers(t *testing.T) {
+	rows := outboxRows("a1", "b1", "a2", "c1", "b2", "a3", "d1")
+	pending := pick(rows, "a1", "b1", "a2", "c1", "b2", "a3", "d1")
+
+	// The first dispatcher's batch of 3 is still uncommitted when the second
+	// one runs, SKIP LOCKED hands it the rows after them
+	first := lockableRecords(pick(rows, "a1", "b1", "a2"), pending)
+	second := lockableRecords(pick(rows, "c1", "b2", "a3", "d1"), pending)
+
+	assert.Equal(t, ids(rows, "a1", "b1", "a2"), first)
+	// b2 and a3 wait for the records the first dispatcher is sending
+	assert.Equal(t, ids(rows, "c1", "d1"), second)
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type AlertRule struct {
+	ID         uint    `gorm:"primaryKey"`
+	Name       string  `gorm:"not null"`
+	Metric     string  `gorm:"not null"`
+	Operator   string  `gorm:"not null"`
+	Threshold  float64 `gorm:"not null"`
+	ForSeconds int64   `gorm:"not null;default:0"`
+	Location   string
+	Tags       []string             `gorm:"type:jsonb;serializer:json"`
+	Severity   entity.AlertSeverity `gorm:"not null;default:'warning'"`
+	Enabled    bool                 `gorm:"not null;default:true"`
+	CreatedBy  uint
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
+
+type AlertRuleState struct {
+	RuleID       uint              `gorm:"primaryKey"`
+	ServerID     string            `gorm:"primaryKey"`
+	State        entity.AlertState `gorm:"not null"`
+	Value        float64           `gorm:"not null"`
+	PendingSince time.Time         `gorm:"not null"`
+	FiredAt      *time.Time
+	ResolvedAt   *time.Time
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (AlertRuleState) TableName() string {
+	return "alert_states"
+}
+
+func FromAlertRuleEntity(r *entity.AlertRule) *AlertRule {
+	return &AlertRule{
+		ID:         r.ID,
+		Name:       r.Name,
+		Metric:     r.Metric,
+		Operator:   r.Operator,
+		Threshold:  r.Threshold,
+		ForSeconds: int64(r.For / time.Second),
+		Location:   r.Location,
+		Tags:       r.Tags,
+		Severity:   r.Severity,
+		Enabled:    r.Enabled,
+		CreatedBy:  r.CreatedBy,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+func ToAlertRuleEntity(r *AlertRule) *entity.AlertRule {
+	return &entity.AlertRule{
+		ID:        r.ID,
+		Name:      r.Name,
+		Metric:    r.Metric,
+		Operator:  r.Operator,
+		Threshold: r.Threshold,
+		For:       time.Duration(r.ForSeconds) * time.Second,
+		Location:  r.Location,
+		Tags:      r.Tags,
+		Severity:  r.Severity,
+		Enabled:   r.Enabled,
+		CreatedBy: r.CreatedBy,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+func ToAlertRuleEntities(rules []AlertRule) []*entity.AlertRule {
+	entities := make([]*entity.AlertRule, 0, len(rules))
+	for i := range rules {
+		entities = append(entities, ToAlertRuleEntity(&rules[i]))
+	}
+	return entities
+}
+
+func FromAlertRuleStateEntity(s *entity.AlertRuleState) *AlertRuleState {
+	return &AlertRuleState{
+		RuleID:       s.RuleID,
+		ServerID:     s.ServerID,
+		State:        s.State,
+		Value:        s.Value,
+		PendingSince: s.PendingSince,
+		FiredAt:      s.FiredAt,
+		ResolvedAt:   s.ResolvedAt,
+		UpdatedAt:    s.UpdatedAt,
+	}
+}
+
+func ToAlertRuleStateEntities(states []AlertRuleState) []*entity.AlertRuleState {
+	entities := make([]*entity.AlertRuleState, 0, len(states))
+	for _, s := range states {
+		entities = append(entities, &entity.AlertRuleState{
+			RuleID:       s.RuleID,
+			ServerID:     s.ServerID,
+			State:        s.State,
+			Value:        s.Value,
+			PendingSince: s.PendingSince,
+			FiredAt:      s.FiredAt,
+			ResolvedAt:   s.ResolvedAt,
+			UpdatedAt:    s.UpdatedAt,
+		})
+	}
+	return entities
+}
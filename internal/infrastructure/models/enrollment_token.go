@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type EnrollmentToken struct {
+	ID              uint   `gorm:"primaryKey"`
+	Name            string `gorm:"not null"`
+	TokenHash       string `gorm:"uniqueIndex;not null"`
+	Location        string
+	Tags            []string `gorm:"type:jsonb;serializer:json"`
+	MaxUses         int      `gorm:"not null;default:1"`
+	UsedCount       int      `gorm:"not null;default:0"`
+	RequireApproval bool     `gorm:"not null;default:false"`
+	ExpiresAt       *time.Time
+	Revoked         bool `gorm:"not null;default:false"`
+	CreatedBy       uint
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+func (EnrollmentToken) TableName() string {
+	return "enrollment_tokens"
+}
+
+func FromEnrollmentTokenEntity(t *entity.EnrollmentToken) *EnrollmentToken {
+	return &EnrollmentToken{
+		ID:              t.ID,
+		Name:            t.Name,
+		TokenHash:       t.TokenHash,
+		Location:        t.Location,
+		Tags:            t.Tags,
+		MaxUses:         t.MaxUses,
+		UsedCount:       t.UsedCount,
+		RequireApproval: t.RequireApproval,
+		ExpiresAt:       t.ExpiresAt,
+		Revoked:         t.Revoked,
+		CreatedBy:       t.CreatedBy,
+		CreatedAt:       t.CreatedAt,
+	}
+}
+
+func ToEnrollmentTokenEntity(t *EnrollmentToken) *entity.EnrollmentToken {
+	return &entity.EnrollmentToken{
+		ID:              t.ID,
+		Name:            t.Name,
+		TokenHash:       t.TokenHash,
+		Location:        t.Location,
+		Tags:            t.Tags,
+		MaxUses:         t.MaxUses,
+		UsedCount:       t.UsedCount,
+		RequireApproval: t.RequireApproval,
+		ExpiresAt:       t.ExpiresAt,
+		Revoked:         t.Revoked,
+		CreatedBy:       t.CreatedBy,
+		CreatedAt:       t.CreatedAt,
+	}
+}
+
+func ToEnrollmentTokenEntities(tokens []EnrollmentToken) []*entity.EnrollmentToken {
+	var entities []*entity.EnrollmentToken
+	for _, t := range tokens {
+		entities = append(entities, ToEnrollmentTokenEntity(&t))
+	}
+	return entities
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type MaintenanceWindow struct {
+	ID              uint   `gorm:"primaryKey"`
+	Name            string `gorm:"not null"`
+	ServerID        string
+	Location        string
+	Tags            []string `gorm:"type:jsonb;serializer:json"`
+	StartsAt        *time.Time
+	EndsAt          *time.Time
+	Schedule        string
+	DurationSeconds int64 `gorm:"not null;default:0"`
+	Enabled         bool  `gorm:"not null;default:true"`
+	CreatedBy       uint
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+func (MaintenanceWindow) TableName() string {
+	return "maintenance_windows"
+}
+
+func FromMaintenanceWindowEntity(w *entity.MaintenanceWindow) *MaintenanceWindow {
+	return &MaintenanceWindow{
+		ID:              w.ID,
+		Name:            w.Name,
+		ServerID:        w.ServerID,
+		Location:        w.Location,
+		Tags:            w.Tags,
+		StartsAt:        w.StartsAt,
+		EndsAt:          w.EndsAt,
+		Schedule:        w.Schedule,
+		DurationSeconds: int64(w.Duration / time.Second),
+		Enabled:         w.Enabled,
+		CreatedBy:       w.CreatedBy,
+		CreatedAt:       w.CreatedAt,
+		UpdatedAt:       w.UpdatedAt,
+	}
+}
+
+func ToMaintenanceWindowEntity(w *MaintenanceWindow) *entity.MaintenanceWindow {
+	return &entity.MaintenanceWindow{
+		ID:        w.ID,
+		Name:      w.Name,
+		ServerID:  w.ServerID,
+		Location:  w.Location,
+		Tags:      w.Tags,
+		StartsAt:  w.StartsAt,
+		EndsAt:    w.EndsAt,
+		Schedule:  w.Schedule,
+		Duration:  time.Duration(w.DurationSeconds) * time.Second,
+		Enabled:   w.Enabled,
+		CreatedBy: w.CreatedBy,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+func ToMaintenanceWindowEntities(windows []MaintenanceWindow) []*entity.MaintenanceWindow {
+	result := make([]*entity.MaintenanceWindow, 0, len(windows))
+	for i := range windows {
+		result = append(result, ToMaintenanceWindowEntity(&windows[i]))
+	}
+	return result
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,33 @@ const (
 	MaxAttemptsReached
 )
 
+// CloudEvents attributes of the events published through the outbox
+const (
+	EventSpecVersion     = "1.0"
+	EventSource          = "/server-management-system"
+	EventDataContentType = "application/json"
+
+	EventTypeServerStatusChanged    = "com.vcs.sms.server.status_changed"
+	EventTypeServerStatusBackfilled = "com.vcs.sms.server.status_backfilled"
+	EventTypeAlertStateChanged      = "com.vcs.sms.alert.state_changed"
+
+	// EventSchemaVersion is the version of the data of every event type, it
+	// changes when a field is renamed or removed
+	EventSchemaVersion = "1"
+)
+
+// Kafka headers of an event, sent in the CloudEvents binary content mode:
+// the data is the message value and the attributes are headers
+const (
+	HeaderContentType   = "content-type"
+	HeaderSpecVersion   = "ce_specversion"
+	HeaderID            = "ce_id"
+	HeaderType          = "ce_type"
+	HeaderSource        = "ce_source"
+	HeaderTime          = "ce_time"
+	HeaderSchemaVersion = "ce_schemaversion"
+)
+
 type Message struct {
 	Key     string
 	Headers map[string]string
@@ -21,28 +49,55 @@ type Message struct {
 	Topic   string
 }
 
+// Event is a CloudEvents 1.0 envelope, SchemaVersion is an extension
+// attribute
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   string          `json:"schemaversion"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// Record is a row of the outbox table, the event is stored as JSONB
 type Record struct {
 	ID               uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Message          Message
+	Topic            string
+	MessageKey       string
+	Event            Event `gorm:"type:jsonb;serializer:json"`
 	State            RecordState
 	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	Seq              int64     `gorm:"->"` // assigned on insert, orders the records
 	LockID           *string
 	LockedAt         *time.Time
 	ProcessedAt      *time.Time
 	NumberOfAttempts int
 	LastAttemptAt    *time.Time
+	NextAttemptAt    *time.Time
 	Error            *string
 }
 
-type RawRecord struct {
-	ID               uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Message          []byte
-	State            RecordState
-	CreatedAt        time.Time `gorm:"autoCreateTime"`
-	LockID           *string
-	LockedAt         *time.Time
-	ProcessedAt      *time.Time
-	NumberOfAttempts int
-	LastAttemptAt    *time.Time
-	Error            *string
+func (Record) TableName() string {
+	return "outbox"
+}
+
+// Message returns the Kafka message of the record
+func (r Record) Message() Message {
+	return Message{
+		Key: r.MessageKey,
+		Headers: map[string]string{
+			HeaderContentType:   r.Event.DataContentType,
+			HeaderSpecVersion:   r.Event.SpecVersion,
+			HeaderID:            r.Event.ID,
+			HeaderType:          r.Event.Type,
+			HeaderSource:        r.Event.Source,
+			HeaderTime:          r.Event.Time.UTC().Format(time.RFC3339Nano),
+			HeaderSchemaVersion: r.Event.SchemaVersion,
+		},
+		Body:  r.Event.Data,
+		Topic: r.Topic,
+	}
 }
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type Probe struct {
+	ID             uint             `gorm:"primaryKey"`
+	ServerID       uint             `gorm:"index;not null"`
+	Type           entity.ProbeType `gorm:"not null"`
+	Port           int
+	URL            string
+	ExpectedStatus int
+	BodyMatch      string
+	TimeoutMs      int64     `gorm:"not null"`
+	Enabled        bool      `gorm:"not null;default:true"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+
+	LastCheckedAt *time.Time
+	LastSuccess   bool `gorm:"not null;default:false"`
+	LastLatencyMs int64
+	LastError     string
+}
+
+func (Probe) TableName() string {
+	return "server_probes"
+}
+
+func FromProbeEntity(p *entity.Probe) *Probe {
+	return &Probe{
+		ID:             p.ID,
+		ServerID:       p.ServerID,
+		Type:           p.Type,
+		Port:           p.Port,
+		URL:            p.URL,
+		ExpectedStatus: p.ExpectedStatus,
+		BodyMatch:      p.BodyMatch,
+		TimeoutMs:      p.Timeout.Milliseconds(),
+		Enabled:        p.Enabled,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+
+		LastCheckedAt: p.LastCheckedAt,
+		LastSuccess:   p.LastSuccess,
+		LastLatencyMs: p.LastLatency.Milliseconds(),
+		LastError:     p.LastError,
+	}
+}
+
+func ToProbeEntity(p *Probe) *entity.Probe {
+	return &entity.Probe{
+		ID:             p.ID,
+		ServerID:       p.ServerID,
+		Type:           p.Type,
+		Port:           p.Port,
+		URL:            p.URL,
+		ExpectedStatus: p.ExpectedStatus,
+		BodyMatch:      p.BodyMatch,
+		Timeout:        time.Duration(p.TimeoutMs) * time.Millisecond,
+		Enabled:        p.Enabled,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+
+		LastCheckedAt: p.LastCheckedAt,
+		LastSuccess:   p.LastSuccess,
+		LastLatency:   time.Duration(p.LastLatencyMs) * time.Millisecond,
+		LastError:     p.LastError,
+	}
+}
+
+func ToProbeEntities(probes []Probe) []*entity.Probe {
+	entities := make([]*entity.Probe, 0, len(probes))
+	for i := range probes {
+		entities = append(entities, ToProbeEntity(&probes[i]))
+	}
+	return entities
+}
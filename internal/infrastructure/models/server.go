@@ -10,13 +10,27 @@ type Server struct {
 	ID           uint   `gorm:"primaryKey"`
 	ServerID     string `gorm:"index;unique;not null"`
 	ServerName   string `gorm:"index;unique;not null"`
-	Status       string `gorm:"not null;default:'OFF'"`
+	Status       string `gorm:"<-:create;not null;default:'OFF'"` // then only by the status updates, like StatusChangedAt
 	IPv4         string
 	Description  string
 	Location     string
 	OS           string
+	Tags         []string  `gorm:"type:jsonb;serializer:json"`
 	IntervalTime int64     `gorm:"default:10"`
 	CreatedAt    time.Time `gorm:"autoCreateTime"`
+
+	AgentConfig        entity.AgentSettings `gorm:"type:jsonb;serializer:json"`
+	AgentConfigVersion int64                `gorm:"not null;default:1"`
+
+	HeartbeatKey string `gorm:"not null;default:''"`
+	ScrapeURL    string `gorm:"not null;default:''"`
+
+	// Only written by UpdateStatus and TouchLastSeen, a full save of a
+	// server read earlier must not move them back
+	StatusChangedAt *time.Time `gorm:"->"`
+	LastSeenAt      *time.Time `gorm:"->"`
+
+	StatusPolicy *entity.StatusPolicy `gorm:"type:jsonb;serializer:json"`
 }
 
 func FromServerEntity(s *entity.Server) *Server {
@@ -29,8 +43,20 @@ func FromServerEntity(s *entity.Server) *Server {
 		Description:  s.Description,
 		Location:     s.Location,
 		OS:           s.OS,
+		Tags:         s.Tags,
 		IntervalTime: s.IntervalTime,
 		CreatedAt:    s.CreatedAt,
+
+		AgentConfig:        s.AgentConfig,
+		AgentConfigVersion: s.AgentConfigVersion,
+
+		HeartbeatKey: s.HeartbeatKey,
+		ScrapeURL:    s.ScrapeURL,
+
+		StatusChangedAt: s.StatusChangedAt,
+		LastSeenAt:      s.LastSeenAt,
+
+		StatusPolicy: s.StatusPolicy,
 	}
 }
 
@@ -52,8 +78,20 @@ func ToServerEntity(s *Server) *entity.Server {
 		Description:  s.Description,
 		Location:     s.Location,
 		OS:           s.OS,
+		Tags:         s.Tags,
 		IntervalTime: s.IntervalTime,
 		CreatedAt:    s.CreatedAt,
+
+		AgentConfig:        s.AgentConfig,
+		AgentConfigVersion: s.AgentConfigVersion,
+
+		HeartbeatKey: s.HeartbeatKey,
+		ScrapeURL:    s.ScrapeURL,
+
+		StatusChangedAt: s.StatusChangedAt,
+		LastSeenAt:      s.LastSeenAt,
+
+		StatusPolicy: s.StatusPolicy,
 	}
 }
 
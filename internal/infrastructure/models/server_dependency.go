@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type ServerDependency struct {
+	ServerID  uint      `gorm:"primaryKey"`
+	ParentID  uint      `gorm:"primaryKey;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (ServerDependency) TableName() string {
+	return "server_dependencies"
+}
+
+func ToServerDependencyEntities(dependencies []ServerDependency) []entity.ServerDependency {
+	entities := make([]entity.ServerDependency, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		entities = append(entities, entity.ServerDependency{
+			ServerID: dependency.ServerID,
+			ParentID: dependency.ParentID,
+		})
+	}
+	return entities
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type ServerMetrics struct {
+	ID            uint      `gorm:"primaryKey"`
+	ServerID      string    `gorm:"index;not null"`
+	SchemaVersion int       `gorm:"not null;default:1"`
+	CPU           int       `gorm:"not null"`
+	RAM           int       `gorm:"not null"`
+	Disk          int       `gorm:"not null"`
+	CollectedAt   time.Time `gorm:"index;not null"`
+
+	Load1            *float64
+	Load5            *float64
+	Load15           *float64
+	CPUCores         []float64           `gorm:"type:jsonb;serializer:json"`
+	Mounts           []entity.MountUsage `gorm:"type:jsonb;serializer:json"`
+	NetRxBytesPerSec *float64
+	NetTxBytesPerSec *float64
+	Processes        *int
+	UptimeSeconds    *int64
+	Custom           map[string]float64 `gorm:"type:jsonb;serializer:json"`
+}
+
+func (ServerMetrics) TableName() string {
+	return "server_metrics"
+}
+
+// MetricsBucket is the row shape returned by the series aggregation query.
+// Extended aggregates are NULL when no sample of the bucket reported them.
+type MetricsBucket struct {
+	Bucket          time.Time
+	Samples         int64
+	ExtendedSamples int64
+	CPUAvg          float64
+	CPUMin          float64
+	CPUMax          float64
+	RAMAvg          float64
+	RAMMin          float64
+	RAMMax          float64
+	DiskAvg         float64
+	DiskMin         float64
+	DiskMax         float64
+	Load1Avg        *float64
+	Load1Min        *float64
+	Load1Max        *float64
+	Load5Avg        *float64
+	Load5Min        *float64
+	Load5Max        *float64
+	Load15Avg       *float64
+	Load15Min       *float64
+	Load15Max       *float64
+	NetRxAvg        *float64
+	NetRxMin        *float64
+	NetRxMax        *float64
+	NetTxAvg        *float64
+	NetTxMin        *float64
+	NetTxMax        *float64
+	ProcessesAvg    *float64
+	ProcessesMin    *float64
+	ProcessesMax    *float64
+}
+
+// GaugeBucket is the row shape of the custom gauge aggregation query
+type GaugeBucket struct {
+	Bucket time.Time
+	Name   string
+	Avg    float64
+	Min    float64
+	Max    float64
+}
+
+func FromServerMetricsEntity(m *entity.ServerMetrics) *ServerMetrics {
+	return &ServerMetrics{
+		ServerID:         m.ServerID,
+		SchemaVersion:    m.SchemaVersion,
+		CPU:              m.CPU,
+		RAM:              m.RAM,
+		Disk:             m.Disk,
+		CollectedAt:      m.Timestamp,
+		Load1:            m.Load1,
+		Load5:            m.Load5,
+		Load15:           m.Load15,
+		CPUCores:         m.CPUCores,
+		Mounts:           m.Mounts,
+		NetRxBytesPerSec: m.NetRxBytesPerSec,
+		NetTxBytesPerSec: m.NetTxBytesPerSec,
+		Processes:        m.Processes,
+		UptimeSeconds:    m.UptimeSeconds,
+		Custom:           m.Custom,
+	}
+}
+
+func FromServerMetricsEntities(metrics []*entity.ServerMetrics) []ServerMetrics {
+	models := make([]ServerMetrics, 0, len(metrics))
+	for _, m := range metrics {
+		models = append(models, *FromServerMetricsEntity(m))
+	}
+	return models
+}
+
+func ToMetricsBucketEntities(buckets []MetricsBucket) []entity.MetricsBucket {
+	entities := make([]entity.MetricsBucket, 0, len(buckets))
+	for _, b := range buckets {
+		entities = append(entities, entity.MetricsBucket{
+			Timestamp:       b.Bucket,
+			Samples:         b.Samples,
+			ExtendedSamples: b.ExtendedSamples,
+			CPU:             entity.MetricsAggregate{Avg: b.CPUAvg, Min: b.CPUMin, Max: b.CPUMax},
+			RAM:             entity.MetricsAggregate{Avg: b.RAMAvg, Min: b.RAMMin, Max: b.RAMMax},
+			Disk:            entity.MetricsAggregate{Avg: b.DiskAvg, Min: b.DiskMin, Max: b.DiskMax},
+			Load1:           toNullableAggregate(b.Load1Avg, b.Load1Min, b.Load1Max),
+			Load5:           toNullableAggregate(b.Load5Avg, b.Load5Min, b.Load5Max),
+			Load15:          toNullableAggregate(b.Load15Avg, b.Load15Min, b.Load15Max),
+			NetRx:           toNullableAggregate(b.NetRxAvg, b.NetRxMin, b.NetRxMax),
+			NetTx:           toNullableAggregate(b.NetTxAvg, b.NetTxMin, b.NetTxMax),
+			Processes:       toNullableAggregate(b.ProcessesAvg, b.ProcessesMin, b.ProcessesMax),
+		})
+	}
+	return entities
+}
+
+func toNullableAggregate(avg, min, max *float64) *entity.MetricsAggregate {
+	if avg == nil || min == nil || max == nil {
+		return nil
+	}
+	return &entity.MetricsAggregate{Avg: *avg, Min: *min, Max: *max}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+type ServerStatusEvent struct {
+	ID         uint64              `gorm:"primaryKey"`
+	ServerID   string              `gorm:"index;not null"`
+	Status     entity.ServerStatus `gorm:"type:server_status;not null"`
+	ChangedAt  time.Time           `gorm:"not null"`
+	Backfilled bool                `gorm:"not null;default:false"`
+	RootCause  *string
+}
+
+func (ServerStatusEvent) TableName() string {
+	return "server_status_events"
+}
+
+func FromServerStatusEventEntity(e *entity.ServerStatusEvent) *ServerStatusEvent {
+	event := &ServerStatusEvent{
+		ID:         e.ID,
+		ServerID:   e.ServerID,
+		Status:     e.Status,
+		ChangedAt:  e.ChangedAt,
+		Backfilled: e.Backfilled,
+	}
+	if e.RootCause != "" {
+		event.RootCause = &e.RootCause
+	}
+	return event
+}
+
+func ToServerStatusEventEntity(e *ServerStatusEvent) *entity.ServerStatusEvent {
+	event := &entity.ServerStatusEvent{
+		ID:         e.ID,
+		ServerID:   e.ServerID,
+		Status:     e.Status,
+		ChangedAt:  e.ChangedAt,
+		Backfilled: e.Backfilled,
+	}
+	if e.RootCause != nil {
+		event.RootCause = *e.RootCause
+	}
+	return event
+}
+
+func ToServerStatusEventEntities(events []ServerStatusEvent) []*entity.ServerStatusEvent {
+	entities := make([]*entity.ServerStatusEvent, 0, len(events))
+	for i := range events {
+		entities = append(entities, ToServerStatusEventEntity(&events[i]))
+	}
+	return entities
+}
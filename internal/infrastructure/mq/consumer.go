@@ -0,0 +1,241 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRetryBackoff     = time.Second
+	defaultMaxRetryBackoff  = 30 * time.Second
+	defaultDeadLetterSuffix = ".dlq"
+	defaultCommitInterval   = time.Second
+)
+
+// ErrMalformedMessage is wrapped by handlers for messages no retry can
+// process, they go to the dead-letter topic right away
+var ErrMalformedMessage = errors.New("malformed message")
+
+// Handler processes a message of the topic it is registered for. It can be
+// called more than once for the same message and has to be idempotent.
+type Handler func(ctx context.Context, message models.Message) error
+
+type ConsumerGroup interface {
+	// Consume hands the messages of the topics to their handler until ctx is
+	// done. A message is marked for commit once handled or dead-lettered.
+	Consume(ctx context.Context, handlers map[string]Handler) error
+	Close() error
+}
+
+type consumerGroup struct {
+	group  sarama.ConsumerGroup
+	broker MessageBroker
+	config configs.Consumer
+	logger *zap.Logger
+}
+
+// NewConsumerGroup returns a nil group when the consumer is disabled
+func NewConsumerGroup(brokerCfg configs.Broker, cfg configs.Consumer, broker MessageBroker, logger *zap.Logger) (ConsumerGroup, func(), error) {
+	if !cfg.Enabled {
+		return nil, func() {}, nil
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultRetryBackoff
+	}
+	if cfg.MaxRetryBackoff < cfg.RetryBackoff {
+		cfg.MaxRetryBackoff = defaultMaxRetryBackoff
+	}
+	if cfg.DeadLetterSuffix == "" {
+		cfg.DeadLetterSuffix = defaultDeadLetterSuffix
+	}
+	if cfg.CommitInterval <= 0 {
+		cfg.CommitInterval = defaultCommitInterval
+	}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	// Only messages done with are marked, the marked offsets are committed
+	// in the background and when the session ends
+	saramaConfig.Consumer.Offsets.AutoCommit.Enable = true
+	saramaConfig.Consumer.Offsets.AutoCommit.Interval = cfg.CommitInterval
+	group, err := sarama.NewConsumerGroup(brokerCfg.Addresses, cfg.GroupID, saramaConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	consumer := &consumerGroup{
+		group:  group,
+		broker: broker,
+		config: cfg,
+		logger: logger,
+	}
+	cleanup := func() {
+		if err := consumer.Close(); err != nil {
+			logger.Error("Failed to close consumer group", zap.Error(err))
+		}
+	}
+	return consumer, cleanup, nil
+}
+
+func (c *consumerGroup) Consume(ctx context.Context, handlers map[string]Handler) error {
+	topics := make([]string, 0, len(handlers))
+	for topic := range handlers {
+		topics = append(topics, topic)
+	}
+	handler := &groupHandler{consumer: c, handlers: handlers}
+
+	c.logger.Info("Starting consumer group",
+		zap.String("group_id", c.config.GroupID),
+		zap.Strings("topics", topics))
+
+	// Consume returns at every rebalance, the group is joined again until ctx
+	// is done
+	for {
+		err := c.group.Consume(ctx, topics, handler)
+		switch {
+		case errors.Is(err, sarama.ErrClosedConsumerGroup):
+			return nil
+		case err != nil:
+			c.logger.Error("Consumer group session failed", zap.Error(err))
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.config.RetryBackoff):
+			}
+		}
+		if ctx.Err() != nil {
+			c.logger.Info("Consumer group stopped", zap.String("group_id", c.config.GroupID))
+			return nil
+		}
+	}
+}
+
+func (c *consumerGroup) Close() error {
+	return c.group.Close()
+}
+
+// handle runs the handler of the message with retries, then dead-letters the
+// message if it still fails. It only returns an error when the message was
+// neither handled nor dead-lettered and must not be committed.
+func (c *consumerGroup) handle(ctx context.Context, handler Handler, msg *sarama.ConsumerMessage) error {
+	message := toMessage(msg)
+	backoff := c.config.RetryBackoff
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = handler(ctx, message)
+		if err == nil || errors.Is(err, ErrMalformedMessage) || attempt >= c.config.MaxRetries {
+			break
+		}
+		c.logger.Warn("Failed to handle message, retrying",
+			zap.String("topic", msg.Topic),
+			zap.Int32("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, c.config.MaxRetryBackoff)
+	}
+	if err == nil {
+		return nil
+	}
+	return c.deadLetter(msg, message, err)
+}
+
+// deadLetter sends the message to the dead-letter topic of its topic, with
+// where it came from and why it failed in the headers
+func (c *consumerGroup) deadLetter(msg *sarama.ConsumerMessage, message models.Message, cause error) error {
+	headers := make(map[string]string, len(message.Headers)+4)
+	for k, v := range message.Headers {
+		headers[k] = v
+	}
+	headers["x-original-topic"] = msg.Topic
+	headers["x-original-partition"] = strconv.FormatInt(int64(msg.Partition), 10)
+	headers["x-original-offset"] = strconv.FormatInt(msg.Offset, 10)
+	headers["x-error"] = cause.Error()
+
+	deadLetterTopic := msg.Topic + c.config.DeadLetterSuffix
+	if err := c.broker.Send(models.Message{
+		Key:     message.Key,
+		Headers: headers,
+		Body:    message.Body,
+		Topic:   deadLetterTopic,
+	}); err != nil {
+		return fmt.Errorf("failed to send message to %s: %w", deadLetterTopic, err)
+	}
+
+	c.logger.Error("Message sent to dead-letter topic",
+		zap.String("topic", msg.Topic),
+		zap.String("dead_letter_topic", deadLetterTopic),
+		zap.Int32("partition", msg.Partition),
+		zap.Int64("offset", msg.Offset),
+		zap.Error(cause))
+	return nil
+}
+
+func toMessage(msg *sarama.ConsumerMessage) models.Message {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+	return models.Message{
+		Key:     string(msg.Key),
+		Headers: headers,
+		Body:    msg.Value,
+		Topic:   msg.Topic,
+	}
+}
+
+// groupHandler processes the claims of a consumer group session, the
+// messages of a partition one after another
+type groupHandler struct {
+	consumer *consumerGroup
+	handlers map[string]Handler
+}
+
+func (h *groupHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// Cleanup commits what was marked since the last automatic commit, so a
+// rebalance does not deliver it again
+func (h *groupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	session.Commit()
+	return nil
+}
+
+func (h *groupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	handler := h.handlers[claim.Topic()]
+	for {
+		select {
+		case <-session.Context().Done():
+			return nil
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			// A message that was neither handled nor dead-lettered ends the
+			// session uncommitted, it is delivered again after the rejoin
+			if err := h.consumer.handle(session.Context(), handler, msg); err != nil {
+				if session.Context().Err() != nil {
+					return nil
+				}
+				return err
+			}
+			session.MarkMessage(msg, "")
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+	"go.uber.org/zap"
+)
+
+// recordingBroker keeps the messages sent to it, or fails them with err
+type recordingBroker struct {
+	sent []models.Message
+	err  error
+}
+
+func (b *recordingBroker) Send(message models.Message) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.sent = append(b.sent, message)
+	return nil
+}
+
+func newTestConsumer(broker MessageBroker) *consumerGroup {
+	return &consumerGroup{
+		broker: broker,
+		config: configs.Consumer{
+			MaxRetries:       2,
+			RetryBackoff:     time.Millisecond,
+			MaxRetryBackoff:  2 * time.Millisecond,
+			DeadLetterSuffix: ".dlq",
+		},
+		logger: zap.NewNop(),
+	}
+}
+
+// failingHandler fails its first failures calls with err
+func failingHandler(failures int, err error) (Handler, *int) {
+	calls := 0
+	return func(ctx context.Context, message models.Message) error {
+		calls++
+		if calls <= failures {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func testMessage(offset int64) *sarama.ConsumerMessage {
+	return &sarama.ConsumerMessage{
+		Topic:     "server_status_updates",
+		Partition: 3,
+		Offset:    offset,
+		Key:       []byte("server-01"),
+		Value:     []byte(`{"server_id":"server-01"}`),
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte(models.HeaderSchemaVersion), Value: []byte("1")},
+		},
+	}
+}
+
+func TestHandle(t *testing.T) {
+	indexFailed := errors.New("index unavailable")
+
+	tests := []struct {
+		name       string
+		failures   int
+		handlerErr error
+		brokerErr  error
+		wantCalls  int
+		wantDLQ    bool
+		wantErr    bool
+	}{
+		{
+			name:      "handled at once",
+			wantCalls: 1,
+		},
+		{
+			name:       "handled after retries",
+			failures:   2,
+			handlerErr: indexFailed,
+			wantCalls:  3,
+		},
+		{
+			name:       "dead-lettered once the retries run out",
+			failures:   10,
+			handlerErr: indexFailed,
+			wantCalls:  3,
+			wantDLQ:    true,
+		},
+		{
+			name:       "malformed message skips the retries",
+			failures:   10,
+			handlerErr: fmt.Errorf("%w: missing server_id", ErrMalformedMessage),
+			wantCalls:  1,
+			wantDLQ:    true,
+		},
+		{
+			name:       "dead-letter topic unavailable",
+			failures:   10,
+			handlerErr: indexFailed,
+			brokerErr:  errors.New("broker down"),
+			wantCalls:  3,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := &recordingBroker{err: tt.brokerErr}
+			handler, calls := failingHandler(tt.failures, tt.handlerErr)
+
+			err := newTestConsumer(broker).handle(context.Background(), handler, testMessage(42))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCalls, *calls)
+
+			if !tt.wantDLQ {
+				assert.Empty(t, broker.sent)
+				return
+			}
+			require.Len(t, broker.sent, 1)
+			sent := broker.sent[0]
+			assert.Equal(t, "server_status_updates.dlq", sent.Topic)
+			assert.Equal(t, "server-01", sent.Key)
+			assert.Equal(t, `{"server_id":"server-01"}`, string(sent.Body))
+			assert.Equal(t, map[string]string{
+				models.HeaderSchemaVersion: "1",
+				"x-original-topic":         "server_status_updates",
+				"x-original-partition":     "3",
+				"x-original-offset":        "42",
+				"x-error":                  tt.handlerErr.Error(),
+			}, sent.Headers)
+		})
+	}
+}
+
+func TestHandleStopsRetryingOnShutdown(t *testing.T) {
+	broker := &recordingBroker{}
+	consumer := newTestConsumer(broker)
+	consumer.config.RetryBackoff = time.Hour
+	consumer.config.MaxRetryBackoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := func(context.Context, models.Message) error {
+		cancel()
+		return errors.New("index unavailable")
+	}
+
+	err := consumer.handle(ctx, handler, testMessage(42))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, broker.sent, "an interrupted message is not dead-lettered")
+}
+
+// testSession records the marked offsets and commits of a session
+type testSession struct {
+	sarama.ConsumerGroupSession
+	ctx     context.Context
+	marked  []int64
+	commits int
+}
+
+func (s *testSession) Context() context.Context { return s.ctx }
+
+func (s *testSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+func (s *testSession) Commit() { s.commits++ }
+
+type testClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *testClaim) Topic() string                            { return "server_status_updates" }
+func (c *testClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func TestConsumeClaim(t *testing.T) {
+	tests := []struct {
+		name       string
+		brokerErr  error
+		wantMarked []int64
+		wantErr    bool
+	}{
+		{
+			name:       "handled and dead-lettered messages are marked",
+			wantMarked: []int64{10, 11, 12},
+		},
+		{
+			// The session ends at the message that could not be dead-lettered,
+			// it is delivered again from the last marked offset
+			name:       "dead-letter failure leaves the message for redelivery",
+			brokerErr:  errors.New("broker down"),
+			wantMarked: []int64{10},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &testClaim{messages: make(chan *sarama.ConsumerMessage, 3)}
+			for offset := int64(10); offset < 13; offset++ {
+				msg := testMessage(offset)
+				msg.Key = []byte(fmt.Sprint(offset))
+				claim.messages <- msg
+			}
+			close(claim.messages)
+
+			// The message at offset 11 never gets indexed
+			h := &groupHandler{
+				consumer: newTestConsumer(&recordingBroker{err: tt.brokerErr}),
+				handlers: map[string]Handler{
+					"server_status_updates": func(ctx context.Context, message models.Message) error {
+						if message.Key == "11" {
+							return errors.New("index unavailable")
+						}
+						return nil
+					},
+				},
+			}
+			session := &testSession{ctx: context.Background()}
+
+			err := h.ConsumeClaim(session, claim)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantMarked, session.marked)
+			assert.Zero(t, session.commits, "offsets are not committed per message")
+
+			require.NoError(t, h.Cleanup(session))
+			assert.Equal(t, 1, session.commits, "the marked offsets are committed when the session ends")
+		})
+	}
+}
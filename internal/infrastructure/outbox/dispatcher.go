@@ -2,6 +2,7 @@ package outbox
 
 import (
 	"log"
+	"sync"
 	"time"
 
 	"github.com/th1enq/server_management_system/internal/configs"
@@ -36,7 +37,10 @@ func NewDispatcher(store database.DatabaseClient, broker mq.MessageBroker, cfg c
 			store,
 			broker,
 			cfg.MachineID,
+			cfg.BatchSize,
+			cfg.MaxLockTimeDuration,
 			cfg.RetrialPolicy,
+			cfg.TopicRetrialPolicies,
 		),
 		recordUnlocker: newRecordUnlocker(
 			store,
@@ -51,7 +55,7 @@ func NewDispatcher(store database.DatabaseClient, broker mq.MessageBroker, cfg c
 }
 
 // Run periodically checks for new outbox messages from the Store, sends the messages through the MessageBroker
-// and updates the message status accordingly
+// and updates the message status accordingly. errChan is closed once doneChan is closed and the workers have stopped.
 func (d Dispatcher) Run(errChan chan<- error, doneChan <-chan struct{}) {
 	doneProc := make(chan struct{}, 1)
 	doneUnlock := make(chan struct{}, 1)
@@ -64,9 +68,25 @@ func (d Dispatcher) Run(errChan chan<- error, doneChan <-chan struct{}) {
 		doneClear <- struct{}{}
 	}()
 
-	go d.runRecordProcessor(errChan, doneProc)
-	go d.runRecordUnlocker(errChan, doneUnlock)
-	go d.runRecordCleaner(errChan, doneClear)
+	var workers sync.WaitGroup
+	workers.Add(3)
+	go func() {
+		defer workers.Done()
+		d.runRecordProcessor(errChan, doneProc)
+	}()
+	go func() {
+		defer workers.Done()
+		d.runRecordUnlocker(errChan, doneUnlock)
+	}()
+	go func() {
+		defer workers.Done()
+		d.runRecordCleaner(errChan, doneClear)
+	}()
+
+	go func() {
+		workers.Wait()
+		close(errChan)
+	}()
 }
 
 // runRecordProcessor processes the unsent records of the store
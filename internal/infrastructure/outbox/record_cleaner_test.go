@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/server_management_system/internal/infrastructure/database"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newOutboxStore returns a store over an in-memory outbox table with the
+// columns the cleaner reads
+func newOutboxStore(t *testing.T) (database.DatabaseClient, *gorm.DB) {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, gormDB.Exec(`CREATE TABLE outbox (
+		id TEXT PRIMARY KEY,
+		topic TEXT,
+		message_key TEXT,
+		event TEXT,
+		state INTEGER,
+		created_at DATETIME,
+		seq INTEGER,
+		lock_id TEXT,
+		locked_at DATETIME,
+		processed_at DATETIME,
+		number_of_attempts INTEGER,
+		last_attempt_at DATETIME,
+		next_attempt_at DATETIME,
+		error TEXT
+	)`).Error)
+	return database.NewDatabaseWithGorm(gormDB), gormDB
+}
+
+func TestRemoveExpiredMessages(t *testing.T) {
+	now := time.Now().UTC()
+	old := now.Add(-time.Hour)
+	retry := now.Add(10 * time.Minute)
+
+	records := map[string]models.Record{
+		"delivered": {
+			State:       models.Delivered,
+			CreatedAt:   old,
+			ProcessedAt: &old,
+		},
+		"out of attempts": {
+			State:            models.MaxAttemptsReached,
+			CreatedAt:        old,
+			ProcessedAt:      &old,
+			NumberOfAttempts: 10,
+		},
+		"backing off": {
+			State:            models.PendingDelivery,
+			CreatedAt:        old,
+			NumberOfAttempts: 2,
+			NextAttemptAt:    &retry,
+		},
+		"never sent": {
+			State:     models.PendingDelivery,
+			CreatedAt: old,
+		},
+		"recently delivered": {
+			State:       models.Delivered,
+			CreatedAt:   now,
+			ProcessedAt: &now,
+		},
+	}
+	wantKept := []string{"backing off", "never sent", "recently delivered"}
+
+	store, gormDB := newOutboxStore(t)
+	for name, record := range records {
+		record.ID = uuid.New()
+		record.MessageKey = name
+		require.NoError(t, gormDB.Omit("Seq").Create(&record).Error)
+	}
+
+	cleaner := newRecordCleaner(store, time.Minute)
+	require.NoError(t, cleaner.RemoveExpiredMessages())
+
+	var kept []string
+	require.NoError(t, gormDB.Model(&models.Record{}).Order("message_key").Pluck("message_key", &kept).Error)
+	assert.Equal(t, wantKept, kept)
+}
@@ -1,92 +1,169 @@
 package outbox
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/th1enq/server_management_system/internal/configs"
 	"github.com/th1enq/server_management_system/internal/infrastructure/database"
 	"github.com/th1enq/server_management_system/internal/infrastructure/models"
 	"github.com/th1enq/server_management_system/internal/infrastructure/mq"
 )
 
+const (
+	defaultInitialBackoff = 5 * time.Second
+	defaultMaxBackoff     = 10 * time.Minute
+	defaultBatchSize      = 100
+	defaultMaxLockTime    = 5 * time.Minute
+)
+
 type defaultRecordProcessor struct {
 	messageBroker mq.MessageBroker
 	store         database.DatabaseClient
 	machineID     string
+	batchSize     int
+	maxLockTime   time.Duration
 	cfg           configs.RetrialPolicy
+	topicCfgs     map[string]configs.RetrialPolicy
 }
 
-func newProcessor(store database.DatabaseClient, messageBroker mq.MessageBroker, machineID string, cfg configs.RetrialPolicy) *defaultRecordProcessor {
+func newProcessor(store database.DatabaseClient, messageBroker mq.MessageBroker, machineID string, batchSize int, maxLockTime time.Duration, cfg configs.RetrialPolicy, topicCfgs map[string]configs.RetrialPolicy) *defaultRecordProcessor {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	// Without a lock timeout every lock would look expired
+	if maxLockTime <= 0 {
+		maxLockTime = defaultMaxLockTime
+	}
 	return &defaultRecordProcessor{
 		store:         store,
 		messageBroker: messageBroker,
 		machineID:     machineID,
+		batchSize:     batchSize,
+		maxLockTime:   maxLockTime,
 		cfg:           cfg,
+		topicCfgs:     topicCfgs,
+	}
+}
+
+// retrialPolicy returns the policy of the topic, or the default one
+func (d defaultRecordProcessor) retrialPolicy(topic string) configs.RetrialPolicy {
+	cfg, ok := d.topicCfgs[topic]
+	if !ok {
+		cfg = d.cfg
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = max(defaultMaxBackoff, cfg.InitialBackoff)
+	}
+	return cfg
+}
+
+// backoff doubles the initial backoff for every failed attempt up to the
+// maximum, then picks a random delay in its upper half so that records
+// failing together are not all retried at once
+func backoff(cfg configs.RetrialPolicy, attempts int) time.Duration {
+	backoff := cfg.InitialBackoff
+	for i := 1; i < attempts && backoff < cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > cfg.MaxBackoff {
+		backoff = cfg.MaxBackoff
 	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
 }
 
-func (d defaultRecordProcessor) lockUnprocessedEntities() error {
+func (d defaultRecordProcessor) lockUnprocessedEntities(lockID string) error {
 	lockTime := time.Now().UTC()
-	lockErr := d.store.UpdateRecordLockByState(d.machineID, lockTime, models.PendingDelivery)
+	lockErr := d.store.UpdateRecordLockByState(lockID, lockTime, lockTime.Add(-d.maxLockTime), models.PendingDelivery, d.batchSize)
 	if lockErr != nil {
 		return lockErr
 	}
 	return nil
 }
 
+// publishMessages sends every record, a failure only delays the failed record
+// and the ones after it with the same key. The failures are returned together.
 func (d defaultRecordProcessor) publishMessages(records []models.Record) error {
+	var errs []error
+	failedKeys := make(map[string]bool)
 	for _, rec := range records {
-		// Send message to message broker
-		now := time.Now().UTC()
-		rec.LastAttemptAt = &now
-		rec.NumberOfAttempts++
-		err := d.messageBroker.Send(rec.Message)
-		// If an error occurs, remove the lock information, update retrial times and continue
-		if err != nil {
-			rec.LockedAt = nil
-			rec.LockID = nil
-			errorMsg := err.Error()
-			rec.Error = &errorMsg
-			if d.cfg.MaxSendAttemptsEnabled && rec.NumberOfAttempts == d.cfg.MaxSendAttempts {
-				rec.State = models.MaxAttemptsReached
-			}
-			dbErr := d.store.UpdateRecordByID(rec)
-			if dbErr != nil {
-				return fmt.Errorf("could not update the record in the db: %w", dbErr)
-			}
-
-			return fmt.Errorf("an error occurred when trying to send the message to the broker: %w", err)
+		// Sending a later record of the key first would reorder its events
+		if failedKeys[rec.MessageKey] {
+			continue
+		}
+		if err := d.publishMessage(rec); err != nil {
+			failedKeys[rec.MessageKey] = true
+			errs = append(errs, fmt.Errorf("record %s: %w", rec.ID, err))
 		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d outbox records failed: %w", len(errs), len(records), errors.Join(errs...))
+	}
+	return nil
+}
 
-		// Remove lock information and update state
-		rec.State = models.Delivered
+func (d defaultRecordProcessor) publishMessage(rec models.Record) error {
+	// Send message to message broker
+	now := time.Now().UTC()
+	rec.LastAttemptAt = &now
+	rec.NumberOfAttempts++
+	err := d.messageBroker.Send(rec.Message())
+	// If an error occurs, remove the lock information and schedule the next attempt
+	if err != nil {
+		cfg := d.retrialPolicy(rec.Topic)
 		rec.LockedAt = nil
 		rec.LockID = nil
-		rec.ProcessedAt = &now
-		err = d.store.UpdateRecordByID(rec)
-
-		if err != nil {
-			return fmt.Errorf("culd not update the record in the db: %w", err)
+		errorMsg := err.Error()
+		rec.Error = &errorMsg
+		nextAttemptAt := now.Add(backoff(cfg, rec.NumberOfAttempts))
+		rec.NextAttemptAt = &nextAttemptAt
+		if cfg.MaxSendAttemptsEnabled && rec.NumberOfAttempts >= cfg.MaxSendAttempts {
+			rec.State = models.MaxAttemptsReached
+			rec.NextAttemptAt = nil
+			rec.ProcessedAt = &now
+		}
+		if dbErr := d.store.UpdateRecordByID(rec); dbErr != nil {
+			return fmt.Errorf("could not update the record in the db: %w", dbErr)
 		}
+		return fmt.Errorf("an error occurred when trying to send the message to the broker: %w", err)
+	}
+
+	// Remove lock information and update state
+	rec.State = models.Delivered
+	rec.LockedAt = nil
+	rec.LockID = nil
+	rec.ProcessedAt = &now
+	rec.NextAttemptAt = nil
+	if err := d.store.UpdateRecordByID(rec); err != nil {
+		return fmt.Errorf("could not update the record in the db: %w", err)
 	}
 	return nil
 }
 
 func (d defaultRecordProcessor) ProcessRecords() error {
-	err := d.lockUnprocessedEntities()
-	defer d.store.ClearLocksByLockID(d.machineID)
-	if err != nil {
+	// Each batch has its own lock ID, releasing it cannot touch the records
+	// of another dispatcher
+	lockID := fmt.Sprintf("%s-%s", d.machineID, uuid.NewString())
+	if err := d.lockUnprocessedEntities(lockID); err != nil {
 		return err
 	}
 
-	records, err := d.store.GetRecordsByLockID(d.machineID)
+	records, err := d.store.GetRecordsByLockID(lockID)
 	if err != nil {
-		return err
+		return errors.Join(err, d.store.ClearLocksByLockID(lockID))
 	}
 
 	if len(records) == 0 {
 		return nil
 	}
-	return d.publishMessages(records)
+	publishErr := d.publishMessages(records)
+	// Records skipped after a failure of their key are still locked
+	return errors.Join(publishErr, d.store.ClearLocksByLockID(lockID))
 }
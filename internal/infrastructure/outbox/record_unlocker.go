@@ -7,19 +7,21 @@ import (
 )
 
 type recordUnlocker struct {
-	store                   database.DatabaseClient
-	MaxLockTimeDurationMins time.Duration
+	store               database.DatabaseClient
+	MaxLockTimeDuration time.Duration
 }
 
-func newRecordUnlocker(store database.DatabaseClient, maxLockTimeDurationMins time.Duration) recordUnlocker {
+func newRecordUnlocker(store database.DatabaseClient, maxLockTimeDuration time.Duration) recordUnlocker {
 	return recordUnlocker{
-		store:                   store,
-		MaxLockTimeDurationMins: maxLockTimeDurationMins,
+		store:               store,
+		MaxLockTimeDuration: maxLockTimeDuration,
 	}
 }
 
+// UnlockExpiredMessages releases the records of dispatchers that stopped while
+// sending them. A lock younger than MaxLockTimeDuration may still be in use.
 func (d recordUnlocker) UnlockExpiredMessages() error {
-	unlockTime := time.Now().Add(-d.MaxLockTimeDurationMins * time.Minute).UTC()
+	unlockTime := time.Now().Add(-d.MaxLockTimeDuration).UTC()
 	err := d.store.ClearLocksWithDurationBeforeDate(unlockTime)
 	if err != nil {
 		return err
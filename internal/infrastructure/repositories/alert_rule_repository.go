@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/infrastructure/database"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+)
+
+type alertRuleRepository struct {
+	db database.DatabaseClient
+}
+
+func NewAlertRuleRepository(db database.DatabaseClient) repository.AlertRuleRepository {
+	return &alertRuleRepository{
+		db: db,
+	}
+}
+
+func (a *alertRuleRepository) Create(ctx context.Context, rule *entity.AlertRule) error {
+	model := models.FromAlertRuleEntity(rule)
+	if err := a.db.WithContext(ctx).Create(model); err != nil {
+		return err
+	}
+	rule.ID = model.ID
+	rule.CreatedAt = model.CreatedAt
+	rule.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (a *alertRuleRepository) GetByID(ctx context.Context, id uint) (*entity.AlertRule, error) {
+	var rule models.AlertRule
+	if err := a.db.WithContext(ctx).First(&rule, id); err != nil {
+		return nil, err
+	}
+	return models.ToAlertRuleEntity(&rule), nil
+}
+
+func (a *alertRuleRepository) List(ctx context.Context) ([]*entity.AlertRule, error) {
+	var rules []models.AlertRule
+	if err := a.db.WithContext(ctx).Order("id").Find(&rules); err != nil {
+		return nil, err
+	}
+	return models.ToAlertRuleEntities(rules), nil
+}
+
+func (a *alertRuleRepository) ListEnabled(ctx context.Context) ([]*entity.AlertRule, error) {
+	var rules []models.AlertRule
+	if err := a.db.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&rules); err != nil {
+		return nil, err
+	}
+	return models.ToAlertRuleEntities(rules), nil
+}
+
+func (a *alertRuleRepository) Update(ctx context.Context, rule *entity.AlertRule) error {
+	model := models.FromAlertRuleEntity(rule)
+	if err := a.db.WithContext(ctx).Save(model); err != nil {
+		return err
+	}
+	rule.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (a *alertRuleRepository) Delete(ctx context.Context, id uint) error {
+	return a.db.WithContext(ctx).Delete(&models.AlertRule{}, id)
+}
+
+func (a *alertRuleRepository) GetStates(ctx context.Context, serverID string, ruleIDs []uint) ([]*entity.AlertRuleState, error) {
+	var states []models.AlertRuleState
+	if err := a.db.WithContext(ctx).Where("server_id = ? AND rule_id IN ?", serverID, ruleIDs).Find(&states); err != nil {
+		return nil, err
+	}
+	return models.ToAlertRuleStateEntities(states), nil
+}
+
+func (a *alertRuleRepository) ListStates(ctx context.Context, ruleID uint) ([]*entity.AlertRuleState, error) {
+	var states []models.AlertRuleState
+	if err := a.db.WithContext(ctx).Where("rule_id = ?", ruleID).Order("server_id").Find(&states); err != nil {
+		return nil, err
+	}
+	return models.ToAlertRuleStateEntities(states), nil
+}
+
+func (a *alertRuleRepository) CountFiring(ctx context.Context, serverID string) (int64, error) {
+	var count int64
+	err := a.db.WithContext(ctx).Model(&models.AlertRuleState{}).
+		Where("server_id = ? AND state = ?", serverID, entity.AlertStateFiring).
+		Count(&count)
+	return count, err
+}
+
+func (a *alertRuleRepository) SaveState(ctx context.Context, rule *entity.AlertRule, state *entity.AlertRuleState, notify bool) error {
+	model := models.FromAlertRuleStateEntity(state)
+	if !notify {
+		return a.db.WithContext(ctx).Save(model)
+	}
+
+	return a.db.Transaction(ctx, func(tx database.DatabaseClient) error {
+		if err := tx.Save(model); err != nil {
+			return err
+		}
+
+		record, err := newAlertRecord(rule, state)
+		if err != nil {
+			return err
+		}
+		return tx.Create(record)
+	})
+}
+
+func (a *alertRuleRepository) DeleteState(ctx context.Context, ruleID uint, serverID string) error {
+	return a.db.WithContext(ctx).Where("rule_id = ? AND server_id = ?", ruleID, serverID).Delete(&models.AlertRuleState{})
+}
+
+// newAlertRecord builds the outbox record announcing that an alert fired or resolved
+func newAlertRecord(rule *entity.AlertRule, state *entity.AlertRuleState) (*models.Record, error) {
+	data := map[string]interface{}{
+		"rule_id":   rule.ID,
+		"rule_name": rule.Name,
+		"server_id": state.ServerID,
+		"state":     state.State,
+		"severity":  rule.Severity,
+		"metric":    rule.Metric,
+		"operator":  rule.Operator,
+		"threshold": rule.Threshold,
+		"value":     state.Value,
+		"timestamp": state.UpdatedAt,
+	}
+	return newOutboxRecord(fmt.Sprintf("server_alert:%s", state.ServerID), "server_alerts", models.EventTypeAlertStateChanged, data)
+}
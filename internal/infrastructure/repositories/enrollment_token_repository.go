@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/infrastructure/database"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+)
+
+type enrollmentTokenRepository struct {
+	db database.DatabaseClient
+}
+
+func NewEnrollmentTokenRepository(db database.DatabaseClient) repository.EnrollmentTokenRepository {
+	return &enrollmentTokenRepository{
+		db: db,
+	}
+}
+
+func (e *enrollmentTokenRepository) Create(ctx context.Context, token *entity.EnrollmentToken) error {
+	model := models.FromEnrollmentTokenEntity(token)
+	if err := e.db.WithContext(ctx).Create(model); err != nil {
+		return err
+	}
+	token.ID = model.ID
+	token.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (e *enrollmentTokenRepository) GetByID(ctx context.Context, id uint) (*entity.EnrollmentToken, error) {
+	var token models.EnrollmentToken
+	if err := e.db.WithContext(ctx).First(&token, id); err != nil {
+		return nil, err
+	}
+	return models.ToEnrollmentTokenEntity(&token), nil
+}
+
+func (e *enrollmentTokenRepository) List(ctx context.Context) ([]*entity.EnrollmentToken, error) {
+	var tokens []models.EnrollmentToken
+	if err := e.db.WithContext(ctx).Order("created_at DESC").Find(&tokens); err != nil {
+		return nil, err
+	}
+	return models.ToEnrollmentTokenEntities(tokens), nil
+}
+
+func (e *enrollmentTokenRepository) Revoke(ctx context.Context, id uint) error {
+	return e.db.WithContext(ctx).Model(&models.EnrollmentToken{}).Where("id = ?", id).Update("revoked", true)
+}
+
+func (e *enrollmentTokenRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*entity.EnrollmentToken, error) {
+	var tokens []models.EnrollmentToken
+	err := e.db.WithContext(ctx).Raw(`
+		UPDATE enrollment_tokens
+		SET used_count = used_count + 1
+		WHERE token_hash = ?
+			AND revoked = FALSE
+			AND used_count < max_uses
+			AND (expires_at IS NULL OR expires_at > ?)
+		RETURNING *`,
+		tokenHash, now,
+	).Scan(&tokens)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return models.ToEnrollmentTokenEntity(&tokens[0]), nil
+}
+
+func (e *enrollmentTokenRepository) Release(ctx context.Context, id uint) error {
+	return e.db.WithContext(ctx).Exec(
+		"UPDATE enrollment_tokens SET used_count = used_count - 1 WHERE id = ? AND used_count > 0", id)
+}
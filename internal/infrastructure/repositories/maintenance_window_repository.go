@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/infrastructure/database"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+)
+
+type maintenanceWindowRepository struct {
+	db database.DatabaseClient
+}
+
+func NewMaintenanceWindowRepository(db database.DatabaseClient) repository.MaintenanceWindowRepository {
+	return &maintenanceWindowRepository{
+		db: db,
+	}
+}
+
+func (m *maintenanceWindowRepository) Create(ctx context.Context, window *entity.MaintenanceWindow) error {
+	model := models.FromMaintenanceWindowEntity(window)
+	if err := m.db.WithContext(ctx).Create(model); err != nil {
+		return err
+	}
+	window.ID = model.ID
+	window.CreatedAt = model.CreatedAt
+	window.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (m *maintenanceWindowRepository) GetByID(ctx context.Context, id uint) (*entity.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	if err := m.db.WithContext(ctx).First(&window, id); err != nil {
+		return nil, err
+	}
+	return models.ToMaintenanceWindowEntity(&window), nil
+}
+
+func (m *maintenanceWindowRepository) List(ctx context.Context) ([]*entity.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	if err := m.db.WithContext(ctx).Order("id").Find(&windows); err != nil {
+		return nil, err
+	}
+	return models.ToMaintenanceWindowEntities(windows), nil
+}
+
+func (m *maintenanceWindowRepository) ListEnabled(ctx context.Context) ([]*entity.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	if err := m.db.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&windows); err != nil {
+		return nil, err
+	}
+	return models.ToMaintenanceWindowEntities(windows), nil
+}
+
+func (m *maintenanceWindowRepository) Update(ctx context.Context, window *entity.MaintenanceWindow) error {
+	model := models.FromMaintenanceWindowEntity(window)
+	if err := m.db.WithContext(ctx).Save(model); err != nil {
+		return err
+	}
+	window.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (m *maintenanceWindowRepository) Delete(ctx context.Context, id uint) error {
+	return m.db.WithContext(ctx).Delete(&models.MaintenanceWindow{}, id)
+}
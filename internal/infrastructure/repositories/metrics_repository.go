@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/query"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/infrastructure/database"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+)
+
+const (
+	metricsDriverPostgres = "postgres"
+)
+
+type metricsRepository struct {
+	db database.DatabaseClient
+}
+
+// NewMetricsRepository returns the metrics store selected by the tsdb driver.
+// Postgres is the default and reuses the main database connection.
+func NewMetricsRepository(db database.DatabaseClient, cfg configs.TSDB) (repository.MetricsRepository, error) {
+	switch cfg.Driver {
+	case "", metricsDriverPostgres:
+		return &metricsRepository{
+			db: db,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tsdb driver: %s", cfg.Driver)
+	}
+}
+
+func (m *metricsRepository) Insert(ctx context.Context, metrics *entity.ServerMetrics) error {
+	model := models.FromServerMetricsEntity(metrics)
+	return m.db.WithContext(ctx).Create(model)
+}
+
+func (m *metricsRepository) BatchInsert(ctx context.Context, metrics []*entity.ServerMetrics) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	model := models.FromServerMetricsEntities(metrics)
+	return m.db.WithContext(ctx).Create(&model)
+}
+
+func (m *metricsRepository) QuerySeries(ctx context.Context, serverID string, metricsRange query.MetricsRange) ([]entity.MetricsBucket, error) {
+	step := metricsRange.Step.Seconds()
+
+	var buckets []models.MetricsBucket
+	err := m.db.WithContext(ctx).Raw(`
+		SELECT
+			to_timestamp(floor(extract(epoch FROM collected_at) / ?) * ?) AS bucket,
+			count(*) AS samples,
+			avg(cpu) AS cpu_avg, min(cpu) AS cpu_min, max(cpu) AS cpu_max,
+			avg(ram) AS ram_avg, min(ram) AS ram_min, max(ram) AS ram_max,
+			avg(disk) AS disk_avg, min(disk) AS disk_min, max(disk) AS disk_max,
+			count(*) FILTER (WHERE schema_version >= 2) AS extended_samples,
+			avg(load1) AS load1_avg, min(load1) AS load1_min, max(load1) AS load1_max,
+			avg(load5) AS load5_avg, min(load5) AS load5_min, max(load5) AS load5_max,
+			avg(load15) AS load15_avg, min(load15) AS load15_min, max(load15) AS load15_max,
+			avg(net_rx_bytes_per_sec) AS net_rx_avg, min(net_rx_bytes_per_sec) AS net_rx_min, max(net_rx_bytes_per_sec) AS net_rx_max,
+			avg(net_tx_bytes_per_sec) AS net_tx_avg, min(net_tx_bytes_per_sec) AS net_tx_min, max(net_tx_bytes_per_sec) AS net_tx_max,
+			avg(processes) AS processes_avg, min(processes) AS processes_min, max(processes) AS processes_max
+		FROM server_metrics
+		WHERE server_id = ? AND collected_at >= ? AND collected_at < ?
+		GROUP BY bucket
+		ORDER BY bucket`,
+		step, step, serverID, metricsRange.From, metricsRange.To,
+	).Scan(&buckets)
+	if err != nil {
+		return nil, err
+	}
+	entities := models.ToMetricsBucketEntities(buckets)
+	if len(metricsRange.Gauges) == 0 || len(entities) == 0 {
+		return entities, nil
+	}
+
+	var gauges []models.GaugeBucket
+	err = m.db.WithContext(ctx).Raw(`
+		SELECT
+			to_timestamp(floor(extract(epoch FROM collected_at) / ?) * ?) AS bucket,
+			gauge.key AS name,
+			avg(gauge.value::float8) AS avg, min(gauge.value::float8) AS min, max(gauge.value::float8) AS max
+		FROM server_metrics, jsonb_each_text(custom) AS gauge
+		WHERE server_id = ? AND collected_at >= ? AND collected_at < ? AND gauge.key IN ?
+		GROUP BY bucket, gauge.key`,
+		step, step, serverID, metricsRange.From, metricsRange.To, metricsRange.Gauges,
+	).Scan(&gauges)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[int64]int, len(entities))
+	for i := range entities {
+		index[entities[i].Timestamp.Unix()] = i
+	}
+	for _, gauge := range gauges {
+		i, ok := index[gauge.Bucket.Unix()]
+		if !ok {
+			continue
+		}
+		if entities[i].Custom == nil {
+			entities[i].Custom = make(map[string]entity.MetricsAggregate)
+		}
+		entities[i].Custom[gauge.Name] = entity.MetricsAggregate{Avg: gauge.Avg, Min: gauge.Min, Max: gauge.Max}
+	}
+	return entities, nil
+}
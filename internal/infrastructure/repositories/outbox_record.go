@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+)
+
+// newOutboxRecord wraps data in a CloudEvents envelope for the outbox
+// dispatcher. It has to be created in the transaction of the change it announces.
+func newOutboxRecord(key string, topic string, eventType string, data interface{}) (*models.Record, error) {
+	encodedData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	return &models.Record{
+		ID:         id,
+		Topic:      topic,
+		MessageKey: key,
+		Event: models.Event{
+			SpecVersion:     models.EventSpecVersion,
+			ID:              id.String(),
+			Type:            eventType,
+			Source:          models.EventSource,
+			Time:            time.Now().UTC(),
+			DataContentType: models.EventDataContentType,
+			SchemaVersion:   models.EventSchemaVersion,
+			Data:            encodedData,
+		},
+		State: models.PendingDelivery,
+	}, nil
+}
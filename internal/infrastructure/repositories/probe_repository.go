@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/infrastructure/database"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+)
+
+type probeRepository struct {
+	db database.DatabaseClient
+}
+
+func NewProbeRepository(db database.DatabaseClient) repository.ProbeRepository {
+	return &probeRepository{
+		db: db,
+	}
+}
+
+func (p *probeRepository) Create(ctx context.Context, probe *entity.Probe) error {
+	model := models.FromProbeEntity(probe)
+	if err := p.db.WithContext(ctx).Create(model); err != nil {
+		return err
+	}
+	probe.ID = model.ID
+	probe.CreatedAt = model.CreatedAt
+	probe.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (p *probeRepository) GetByID(ctx context.Context, id uint) (*entity.Probe, error) {
+	var probe models.Probe
+	if err := p.db.WithContext(ctx).First(&probe, id); err != nil {
+		return nil, err
+	}
+	return models.ToProbeEntity(&probe), nil
+}
+
+func (p *probeRepository) ListByServer(ctx context.Context, serverID uint) ([]*entity.Probe, error) {
+	var probes []models.Probe
+	if err := p.db.WithContext(ctx).Where("server_id = ?", serverID).Order("id").Find(&probes); err != nil {
+		return nil, err
+	}
+	return models.ToProbeEntities(probes), nil
+}
+
+func (p *probeRepository) Update(ctx context.Context, probe *entity.Probe) error {
+	model := models.FromProbeEntity(probe)
+	if err := p.db.WithContext(ctx).Save(model); err != nil {
+		return err
+	}
+	probe.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (p *probeRepository) Delete(ctx context.Context, id uint) error {
+	return p.db.WithContext(ctx).Delete(&models.Probe{}, id)
+}
+
+func (p *probeRepository) ListTargets(ctx context.Context) ([]*entity.ProbeTarget, error) {
+	var probes []models.Probe
+	if err := p.db.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&probes); err != nil {
+		return nil, err
+	}
+	if len(probes) == 0 {
+		return nil, nil
+	}
+
+	byServer := make(map[uint][]*entity.Probe)
+	serverIDs := make([]uint, 0)
+	for _, probe := range models.ToProbeEntities(probes) {
+		if _, ok := byServer[probe.ServerID]; !ok {
+			serverIDs = append(serverIDs, probe.ServerID)
+		}
+		byServer[probe.ServerID] = append(byServer[probe.ServerID], probe)
+	}
+
+	var servers []models.Server
+	err := p.db.WithContext(ctx).
+		Where("id IN ? AND status <> ?", serverIDs, entity.ServerStatusPendingApproval).
+		Find(&servers)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]*entity.ProbeTarget, 0, len(servers))
+	for _, server := range models.ToServerEntities(servers) {
+		targets = append(targets, &entity.ProbeTarget{
+			Server: server,
+			Probes: byServer[server.ID],
+		})
+	}
+	return targets, nil
+}
+
+func (p *probeRepository) SaveResult(ctx context.Context, probe *entity.Probe) error {
+	return p.db.WithContext(ctx).Model(&models.Probe{}).Where("id = ?", probe.ID).Updates(map[string]interface{}{
+		"last_checked_at": probe.LastCheckedAt,
+		"last_success":    probe.LastSuccess,
+		"last_latency_ms": probe.LastLatency.Milliseconds(),
+		"last_error":      probe.LastError,
+	})
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/infrastructure/database"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+)
+
+type serverDependencyRepository struct {
+	db database.DatabaseClient
+}
+
+func NewServerDependencyRepository(db database.DatabaseClient) repository.ServerDependencyRepository {
+	return &serverDependencyRepository{
+		db: db,
+	}
+}
+
+func (d *serverDependencyRepository) SetParents(ctx context.Context, id uint, parentIDs []uint) error {
+	return d.db.Transaction(ctx, func(tx database.DatabaseClient) error {
+		// Two concurrent changes could each close half of a cycle, the
+		// lock lets the second one see the first
+		if err := tx.Exec("LOCK TABLE server_dependencies IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM server_dependencies WHERE server_id = ?", id); err != nil {
+			return err
+		}
+		if len(parentIDs) == 0 {
+			return nil
+		}
+
+		var cycle []uint
+		err := tx.Raw(`WITH RECURSIVE ancestors (id) AS (
+				SELECT id FROM servers WHERE id IN ?
+				UNION
+				SELECT d.parent_id FROM server_dependencies d JOIN ancestors a ON d.server_id = a.id
+			)
+			SELECT id FROM ancestors WHERE id = ?`, parentIDs, id).
+			Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if len(cycle) > 0 {
+			return domainerrors.ErrDependencyCycle
+		}
+
+		dependencies := make([]models.ServerDependency, 0, len(parentIDs))
+		for _, parentID := range parentIDs {
+			dependencies = append(dependencies, models.ServerDependency{
+				ServerID: id,
+				ParentID: parentID,
+			})
+		}
+		return tx.Create(&dependencies)
+	})
+}
+
+func (d *serverDependencyRepository) ListParents(ctx context.Context, serverID string) ([]*entity.Server, error) {
+	var servers []models.Server
+	err := d.db.WithContext(ctx).
+		Where(`id IN (SELECT d.parent_id FROM server_dependencies d
+			JOIN servers c ON c.id = d.server_id WHERE c.server_id = ?)`, serverID).
+		Order("id").
+		Find(&servers)
+	if err != nil {
+		return nil, err
+	}
+	return models.ToServerEntities(servers), nil
+}
+
+func (d *serverDependencyRepository) ListChildren(ctx context.Context, serverID string) ([]*entity.Server, error) {
+	var servers []models.Server
+	err := d.db.WithContext(ctx).
+		Where(`id IN (SELECT d.server_id FROM server_dependencies d
+			JOIN servers p ON p.id = d.parent_id WHERE p.server_id = ?)`, serverID).
+		Order("id").
+		Find(&servers)
+	if err != nil {
+		return nil, err
+	}
+	return models.ToServerEntities(servers), nil
+}
+
+func (d *serverDependencyRepository) List(ctx context.Context) ([]entity.ServerDependency, error) {
+	var dependencies []models.ServerDependency
+	if err := d.db.WithContext(ctx).Order("server_id, parent_id").Find(&dependencies); err != nil {
+		return nil, err
+	}
+	return models.ToServerDependencyEntities(dependencies), nil
+}
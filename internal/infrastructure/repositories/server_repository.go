@@ -1,18 +1,18 @@
 package repositories
 
 import (
-	"bytes"
 	"context"
-	"encoding/gob"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
 	"github.com/th1enq/server_management_system/internal/domain/query"
 	"github.com/th1enq/server_management_system/internal/domain/repository"
 	"github.com/th1enq/server_management_system/internal/infrastructure/database"
 	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+	"gorm.io/gorm"
 )
 
 type serverRepository struct {
@@ -39,13 +39,45 @@ func (s *serverRepository) GetIntervalTime(ctx context.Context, serverID string)
 
 func (s *serverRepository) GetServerIDs(ctx context.Context) ([]string, error) {
 	var serverIDs []string
-	err := s.db.WithContext(ctx).Model(&models.Server{}).Pluck("server_id", &serverIDs)
+	err := s.db.WithContext(ctx).Model(&models.Server{}).
+		Where("status <> ?", entity.ServerStatusPendingApproval).
+		Pluck("server_id", &serverIDs)
 	if err != nil {
 		return nil, err
 	}
 	return serverIDs, nil
 }
 
+func (s *serverRepository) ListByStatus(ctx context.Context, status entity.ServerStatus) ([]*entity.Server, error) {
+	var servers []models.Server
+	if err := s.db.WithContext(ctx).Where("status = ?", status).Find(&servers); err != nil {
+		return nil, err
+	}
+	return models.ToServerEntities(servers), nil
+}
+
+func (s *serverRepository) ListInMaintenanceWindow(ctx context.Context) ([]*entity.Server, error) {
+	var servers []models.Server
+	err := s.db.WithContext(ctx).
+		Where("status = ? AND maintenance_window_id IS NOT NULL", entity.ServerStatusMaintenance).
+		Find(&servers)
+	if err != nil {
+		return nil, err
+	}
+	return models.ToServerEntities(servers), nil
+}
+
+func (s *serverRepository) ListScrapeTargets(ctx context.Context) ([]*entity.Server, error) {
+	var servers []models.Server
+	err := s.db.WithContext(ctx).
+		Where("scrape_url <> '' AND status <> ?", entity.ServerStatusPendingApproval).
+		Find(&servers)
+	if err != nil {
+		return nil, err
+	}
+	return models.ToServerEntities(servers), nil
+}
+
 func (s *serverRepository) GetByIPv4(ctx context.Context, ipv4 string) (*entity.Server, error) {
 	var server models.Server
 	if err := s.db.WithContext(ctx).Where("ipv4 = ?", ipv4).First(&server); err != nil {
@@ -106,6 +138,9 @@ func (s *serverRepository) GetAll(ctx context.Context) ([]*entity.Server, error)
 func (s *serverRepository) GetByServerID(ctx context.Context, serverID string) (*entity.Server, error) {
 	var server models.Server
 	if err := s.db.WithContext(ctx).Where("server_id = ?", serverID).First(&server); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domainerrors.ErrServerNotFound
+		}
 		return nil, err
 	}
 	return models.ToServerEntity(&server), nil
@@ -155,6 +190,20 @@ func (s *serverRepository) List(ctx context.Context, filter query.ServerFilter,
 		query = query.Where("disk = ?", filter.Disk)
 	}
 
+	if !filter.StatusChangedAfter.IsZero() {
+		query = query.Where("status_changed_at >= ?", filter.StatusChangedAfter)
+	}
+	if !filter.StatusChangedBefore.IsZero() {
+		query = query.Where("status_changed_at < ?", filter.StatusChangedBefore)
+	}
+	if !filter.LastSeenAfter.IsZero() {
+		query = query.Where("last_seen_at >= ?", filter.LastSeenAfter)
+	}
+	if !filter.LastSeenBefore.IsZero() {
+		// Servers never seen are not seen before any time either
+		query = query.Where("(last_seen_at < ? OR last_seen_at IS NULL)", filter.LastSeenBefore)
+	}
+
 	if err := query.Count(&total); err != nil {
 		return nil, 0, err
 	}
@@ -176,53 +225,126 @@ func (s *serverRepository) Update(ctx context.Context, server *entity.Server) er
 	return s.db.WithContext(ctx).Save(model)
 }
 
-func (s *serverRepository) UpdateStatus(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) error {
-	err := s.db.Transaction(ctx, func(tx database.DatabaseClient) error {
-		var server models.Server
-		if err := tx.Model(&server).Where("server_id = ?", serverID).Update("status", status); err != nil {
-			return err
+func (s *serverRepository) UpdateStatus(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) (bool, error) {
+	from := make([]string, 0)
+	for _, current := range entity.ServerStatusesTo(status) {
+		// Maintenance is only left through LeaveMaintenance, flapping
+		// through ResolveFlapping
+		if current != entity.ServerStatusMaintenance && current != entity.ServerStatusFlapping {
+			from = append(from, string(current))
 		}
+	}
+	return s.updateStatus(ctx, serverID, status, timestamp, nil, "", "status IN ? OR status IS NULL", from)
+}
 
-		data := map[string]interface{}{
-			"server_id": serverID,
-			"status":    status,
-			"timestamp": timestamp,
+func (s *serverRepository) ResolveFlapping(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) (bool, error) {
+	return s.updateStatus(ctx, serverID, status, timestamp, nil, "", "status = ?", entity.ServerStatusFlapping)
+}
+
+func (s *serverRepository) EnterMaintenance(ctx context.Context, serverID string, windowID *uint, timestamp time.Time) (bool, error) {
+	from := make([]string, 0)
+	for _, current := range entity.ServerStatusesTo(entity.ServerStatusMaintenance) {
+		from = append(from, string(current))
+	}
+	return s.updateStatus(ctx, serverID, entity.ServerStatusMaintenance, timestamp, windowID, "", "status IN ? OR status IS NULL", from)
+}
+
+func (s *serverRepository) LeaveMaintenance(ctx context.Context, serverID string, scheduledOnly bool, timestamp time.Time) (bool, error) {
+	if scheduledOnly {
+		return s.updateStatus(ctx, serverID, entity.ServerStatusOff, timestamp, nil, "", "status = ? AND maintenance_window_id IS NOT NULL", entity.ServerStatusMaintenance)
+	}
+	return s.updateStatus(ctx, serverID, entity.ServerStatusOff, timestamp, nil, "", "status = ?", entity.ServerStatusMaintenance)
+}
+
+func (s *serverRepository) MarkUnreachable(ctx context.Context, serverID string, rootCause string, timestamp time.Time) (bool, error) {
+	from := make([]string, 0)
+	for _, current := range entity.ServerStatusesTo(entity.ServerStatusUnreachable) {
+		if current != entity.ServerStatusMaintenance && current != entity.ServerStatusFlapping {
+			from = append(from, string(current))
 		}
-		encodedData, err := json.Marshal(data)
+	}
+	return s.updateStatus(ctx, serverID, entity.ServerStatusUnreachable, timestamp, nil, rootCause, "status IN ? OR status IS NULL", from)
+}
+
+// updateStatus sets the status and the maintenance window holding the server
+// if the current row matches condition, and records and publishes the change
+func (s *serverRepository) updateStatus(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time, windowID *uint, rootCause string, condition string, args ...interface{}) (bool, error) {
+	changed := false
+	err := s.db.Transaction(ctx, func(tx database.DatabaseClient) error {
+		values := append([]interface{}{status, timestamp, windowID, serverID}, args...)
+
+		var updated []string
+		err := tx.Raw(`UPDATE servers SET status = ?, status_changed_at = ?, maintenance_window_id = ?
+			WHERE server_id = ? AND (`+condition+`)
+			RETURNING server_id`, values...).
+			Scan(&updated)
 		if err != nil {
 			return err
 		}
-		msg := models.Message{
-			Key:     fmt.Sprintf("server_status:%s", serverID),
-			Headers: nil,
-			Body:    encodedData,
-			Topic:   "server_status_updates",
+		if len(updated) == 0 {
+			return nil
 		}
-		msgBuf := new(bytes.Buffer)
-		msgEnc := gob.NewEncoder(msgBuf)
+		changed = true
 
-		if err := msgEnc.Encode(msg); err != nil {
-			return err
-		}
+		return createStatusEvent(tx, serverID, status, timestamp, false, rootCause)
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
 
-		record := models.RawRecord{
-			Message:     msgBuf.Bytes(),
-			State:       models.PendingDelivery,
-			LockID:      nil,
-			LockedAt:    nil,
-			ProcessedAt: nil,
-		}
+func (s *serverRepository) TouchLastSeen(ctx context.Context, serverID string, timestamp time.Time) error {
+	return s.db.WithContext(ctx).Exec(`UPDATE servers SET last_seen_at = ?
+		WHERE server_id = ? AND (last_seen_at IS NULL OR last_seen_at < ?)`, timestamp, serverID, timestamp)
+}
 
-		if err := tx.Create(&record); err != nil {
-			return err
-		}
+func (s *serverRepository) RecordStatusEvent(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) error {
+	return s.db.Transaction(ctx, func(tx database.DatabaseClient) error {
+		return createStatusEvent(tx, serverID, status, timestamp, true, "")
+	})
+}
 
-		return nil
+// createStatusEvent adds the status change to the status history and to the
+// outbox, in the transaction of the change. Changes caused by another server
+// carry its ID as root cause, consumers alert on the root cause only.
+func createStatusEvent(tx database.DatabaseClient, serverID string, status entity.ServerStatus, timestamp time.Time, backfilled bool, rootCause string) error {
+	event := models.FromServerStatusEventEntity(&entity.ServerStatusEvent{
+		ServerID:   serverID,
+		Status:     status,
+		ChangedAt:  timestamp,
+		Backfilled: backfilled,
+		RootCause:  rootCause,
 	})
+	if err := tx.Create(event); err != nil {
+		return err
+	}
+
+	record, err := newStatusRecord(serverID, status, timestamp, backfilled, rootCause)
 	if err != nil {
 		return err
 	}
-	return nil
+	return tx.Create(record)
+}
+
+// newStatusRecord builds the outbox record announcing a status change.
+// Backfilled changes have their own event type, they are history and not the
+// current status of the server.
+func newStatusRecord(serverID string, status entity.ServerStatus, timestamp time.Time, backfilled bool, rootCause string) (*models.Record, error) {
+	data := map[string]interface{}{
+		"server_id":  serverID,
+		"status":     status,
+		"timestamp":  timestamp,
+		"backfilled": backfilled,
+	}
+	if rootCause != "" {
+		data["root_cause"] = rootCause
+	}
+	eventType := models.EventTypeServerStatusChanged
+	if backfilled {
+		eventType = models.EventTypeServerStatusBackfilled
+	}
+	return newOutboxRecord(fmt.Sprintf("server_status:%s", serverID), "server_status_updates", eventType, data)
 }
 
 func (s *serverRepository) ExistsByServerIDOrServerName(ctx context.Context, serverID string, serverName string) (bool, error) {
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/query"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/infrastructure/database"
+	"github.com/th1enq/server_management_system/internal/infrastructure/models"
+)
+
+type serverStatusEventRepository struct {
+	db database.DatabaseClient
+}
+
+func NewServerStatusEventRepository(db database.DatabaseClient) repository.ServerStatusEventRepository {
+	return &serverStatusEventRepository{
+		db: db,
+	}
+}
+
+func (s *serverStatusEventRepository) List(ctx context.Context, serverID string, from, to time.Time, pagination query.Pagination) ([]*entity.ServerStatusEvent, int64, error) {
+	var events []models.ServerStatusEvent
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&models.ServerStatusEvent{}).
+		Where("server_id = ? AND changed_at >= ? AND changed_at < ?", serverID, from, to)
+
+	if err := query.Count(&total); err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("changed_at DESC, id DESC").
+		Limit(pagination.PageSize).
+		Offset(pagination.Offset()).
+		Find(&events)
+
+	return models.ToServerStatusEventEntities(events), total, err
+}
+
+func (s *serverStatusEventRepository) ListBetween(ctx context.Context, serverID string, from, to time.Time) ([]*entity.ServerStatusEvent, error) {
+	var events []models.ServerStatusEvent
+	err := s.db.WithContext(ctx).
+		Where("server_id = ? AND changed_at >= ? AND changed_at <= ?", serverID, from, to).
+		Order("changed_at, id").
+		Find(&events)
+	if err != nil {
+		return nil, err
+	}
+	return models.ToServerStatusEventEntities(events), nil
+}
+
+func (s *serverStatusEventRepository) LastBefore(ctx context.Context, serverID string, t time.Time) (*entity.ServerStatusEvent, error) {
+	var events []models.ServerStatusEvent
+	err := s.db.WithContext(ctx).
+		Where("server_id = ? AND changed_at < ?", serverID, t).
+		Order("changed_at DESC, id DESC").
+		Limit(1).
+		Find(&events)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	return models.ToServerStatusEventEntity(&events[0]), nil
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -48,6 +49,89 @@ func (t *tokenRepository) RemoveTokenFromWhitelist(ctx context.Context, token st
 	return t.cache.Del(ctx, key)
 }
 
+// AddServerTokenToWhitelist also keeps the token set of the server alive as
+// long as the token. The refresh token is added last and outlives the access
+// token, so the set outlives every token in it.
+func (t *tokenRepository) AddServerTokenToWhitelist(ctx context.Context, token string, serverID string, expiration time.Duration) error {
+	cacheKey := fmt.Sprintf("server_tokens:%s", serverID)
+	err := t.cache.SADD(ctx, cacheKey, token)
+	if err != nil {
+		return err
+	}
+	if err := t.cache.Expire(ctx, cacheKey, expiration); err != nil {
+		return err
+	}
+	cacheKey = fmt.Sprintf("token:whitelist:%s", token)
+	err = t.cache.Set(ctx, cacheKey, "valid", expiration)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *tokenRepository) RemoveServerTokensFromWhitelist(ctx context.Context, serverID string) error {
+	cacheKey := fmt.Sprintf("server_tokens:%s", serverID)
+	token, err := t.cache.SMEMBERS(ctx, cacheKey)
+	if err != nil {
+		return err
+	}
+	for _, to := range token {
+		t.cache.Del(ctx, fmt.Sprintf("token:whitelist:%s", to))
+	}
+	if err := t.cache.Del(ctx, cacheKey); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RemoveServerTokenFromWhitelist revokes a rotated token and drops the
+// expired tokens from the token set of the server
+func (t *tokenRepository) RemoveServerTokenFromWhitelist(ctx context.Context, serverID string, token string) error {
+	if err := t.RemoveTokenFromWhitelist(ctx, token); err != nil {
+		return err
+	}
+
+	cacheKey := fmt.Sprintf("server_tokens:%s", serverID)
+	tokens, err := t.cache.SMEMBERS(ctx, cacheKey)
+	if err != nil {
+		return err
+	}
+	stale := []string{token}
+	for _, member := range tokens {
+		if member != token && !t.IsTokenWhitelisted(ctx, member) {
+			stale = append(stale, member)
+		}
+	}
+	return t.cache.SREM(ctx, cacheKey, stale...)
+}
+
+// MarkServerTokenUsed records that a refresh token has been consumed.
+// It returns false when the token was already used before.
+func (t *tokenRepository) MarkServerTokenUsed(ctx context.Context, token string, expiration time.Duration) (bool, error) {
+	cacheKey := fmt.Sprintf("server_token:used:%s", token)
+	return t.cache.SetNX(ctx, cacheKey, "used", expiration)
+}
+
+func (t *tokenRepository) IsServerTokenUsed(ctx context.Context, token string) (bool, error) {
+	cacheKey := fmt.Sprintf("server_token:used:%s", token)
+	var used string
+	err := t.cache.Get(ctx, cacheKey, &used)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnmarkServerTokenUsed lets a refresh token be used again after a refresh
+// that failed before rotating the credentials
+func (t *tokenRepository) UnmarkServerTokenUsed(ctx context.Context, token string) error {
+	cacheKey := fmt.Sprintf("server_token:used:%s", token)
+	return t.cache.Del(ctx, cacheKey)
+}
+
 func (t *tokenRepository) RemoveUserTokensFromWhitelist(ctx context.Context, userID uint) error {
 	cacheKey := fmt.Sprintf("user_tokens:%d", userID)
 	token, err := t.cache.SMEMBERS(ctx, cacheKey)
@@ -6,4 +6,11 @@ var WireSet = wire.NewSet(
 	NewServerRepository,
 	NewUserRepository,
 	NewTokenRepository,
+	NewMetricsRepository,
+	NewEnrollmentTokenRepository,
+	NewAlertRuleRepository,
+	NewProbeRepository,
+	NewMaintenanceWindowRepository,
+	NewServerStatusEventRepository,
+	NewServerDependencyRepository,
 )
@@ -79,6 +79,11 @@ func (e *esClient) Exec(ctx context.Context, indexName string, query map[string]
 
 	e.logger.Info("Search response body", zap.ByteString("body", bodyBytes))
 
+	if res.IsError() {
+		e.logger.Error("Error executing search request", zap.String("index", indexName), zap.String("status", res.Status()))
+		return fmt.Errorf("error executing search request: %s", res.Status())
+	}
+
 	if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 		e.logger.Error("Failed to unmarshal response body", zap.Error(err))
 		return fmt.Errorf("failed to unmarshal response body: %w", err)
@@ -86,7 +91,15 @@ func (e *esClient) Exec(ctx context.Context, indexName string, query map[string]
 	return nil
 }
 
+// LoadElasticSearch returns a nil client when no URL is configured. An
+// unreachable Elasticsearch does not prevent the start, the status history in
+// Postgres is used until it answers.
 func LoadElasticSearch(cfg configs.ElasticSearch, logger *zap.Logger) (IESClient, error) {
+	if cfg.URL == "" {
+		logger.Info("Elasticsearch not configured, status history is read from Postgres")
+		return nil, nil
+	}
+
 	esConfig := elasticsearch.Config{
 		Addresses: []string{cfg.URL},
 	}
@@ -94,17 +107,22 @@ func LoadElasticSearch(cfg configs.ElasticSearch, logger *zap.Logger) (IESClient
 	ESClient, err := elasticsearch.NewClient(esConfig)
 	if err != nil {
 		logger.Error("Error creating Elasticsearch client", zap.Error(err))
+		return nil, fmt.Errorf("cannot create ES client: %w", err)
 	}
 
 	req, err := ESClient.Ping()
 	if err != nil {
-		return nil, fmt.Errorf("cannot try connect to ES: %w", err)
+		logger.Warn("Elasticsearch unreachable, status history is read from Postgres until it answers",
+			zap.String("url", cfg.URL),
+			zap.Error(err),
+		)
+	} else {
+		defer req.Body.Close()
+		logger.Info("Elasticsearch connected successfully",
+			zap.String("url", cfg.URL),
+			zap.Int("code", req.StatusCode),
+		)
 	}
-
-	logger.Info("Elasticsearch connected successfully",
-		zap.String("url", cfg.URL),
-		zap.Int("code", req.StatusCode),
-	)
 	return &esClient{
 		es:     ESClient,
 		logger: logger,
@@ -73,7 +73,14 @@ func (e *excelizeService) ParseToServer(row []string) (entity.Server, error) {
 
 	// Validate status
 	if server.Status != "" {
-		validStatuses := []entity.ServerStatus{"ON", "OFF"}
+		validStatuses := []entity.ServerStatus{
+			entity.ServerStatusOn,
+			entity.ServerStatusOff,
+			entity.ServerStatusDegraded,
+			entity.ServerStatusMaintenance,
+			entity.ServerStatusUnreachable,
+		}
+		server.Status = entity.ServerStatus(strings.ToUpper(string(server.Status)))
 		isValid := false
 		for _, status := range validStatuses {
 			if server.Status == status {
@@ -82,7 +89,7 @@ func (e *excelizeService) ParseToServer(row []string) (entity.Server, error) {
 			}
 		}
 		if !isValid {
-			return entity.Server{}, fmt.Errorf("invalid status: %s (must be ON, OFF, or MAINTENANCE)", server.Status)
+			return entity.Server{}, fmt.Errorf("invalid status: %s (must be ON, OFF, DEGRADED, MAINTENANCE, or UNREACHABLE)", server.Status)
 		}
 	} else {
 		server.Status = "OFF" // default status
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/domain/services"
+	"github.com/th1enq/server_management_system/internal/dto"
+)
+
+const (
+	defaultScrapeTimeout      = 10 * time.Second
+	defaultScrapeMaxBodyBytes = 16 << 20
+)
+
+var errMalformedExposition = errors.New("malformed metrics exposition")
+
+type httpMetricsScraper struct {
+	client       *http.Client
+	maxBodyBytes int64
+}
+
+func NewHTTPMetricsScraper(cfg configs.Scrape, targetPolicy services.TargetPolicy) services.MetricsScraper {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultScrapeTimeout
+	}
+	maxBodyBytes := cfg.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultScrapeMaxBodyBytes
+	}
+	return &httpMetricsScraper{
+		client: &http.Client{
+			Timeout: timeout,
+			// No proxy, the policy has to see the address of the target
+			Transport: &http.Transport{
+				DialContext:         newTargetDialer(targetPolicy).DialContext,
+				TLSHandshakeTimeout: timeout,
+			},
+		},
+		maxBodyBytes: maxBodyBytes,
+	}
+}
+
+func (s *httpMetricsScraper) Scrape(ctx context.Context, url string) ([]dto.PrometheusTimeSeries, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scrape request: %w", err)
+	}
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+
+	timestamp := time.Now().UnixMilli()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrape %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to scrape %s: unexpected status %d", url, resp.StatusCode)
+	}
+
+	body := io.LimitReader(resp.Body, s.maxBodyBytes+1)
+	series, read, err := parseExposition(body, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scrape of %s: %w", url, err)
+	}
+	if read > s.maxBodyBytes {
+		return nil, fmt.Errorf("failed to scrape %s: body larger than %d bytes", url, s.maxBodyBytes)
+	}
+	return series, nil
+}
+
+// parseExposition parses the Prometheus text format. Comments, HELP and TYPE
+// lines are skipped and the exposed timestamps are replaced with timestamp.
+func parseExposition(r io.Reader, timestamp int64) ([]dto.PrometheusTimeSeries, int64, error) {
+	var (
+		series []dto.PrometheusTimeSeries
+		read   int64
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		read += int64(len(line)) + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		labels, rest, err := parseSeries(line)
+		if err != nil {
+			return nil, read, err
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, read, errMalformedExposition
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, read, errMalformedExposition
+		}
+
+		series = append(series, dto.PrometheusTimeSeries{
+			Labels:  labels,
+			Samples: []dto.PrometheusSample{{Value: value, Timestamp: timestamp}},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, read, err
+	}
+	return series, read, nil
+}
+
+// parseSeries parses `name{label="value",...}` and returns what follows it
+func parseSeries(line string) (map[string]string, string, error) {
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return nil, "", errMalformedExposition
+	}
+	labels := map[string]string{"__name__": line[:end]}
+	if line[end] != '{' {
+		return labels, line[end:], nil
+	}
+
+	i := end + 1
+	for {
+		for i < len(line) && (line[i] == ' ' || line[i] == ',') {
+			i++
+		}
+		if i >= len(line) {
+			return nil, "", errMalformedExposition
+		}
+		if line[i] == '}' {
+			return labels, line[i+1:], nil
+		}
+
+		eq := strings.IndexByte(line[i:], '=')
+		if eq <= 0 || i+eq+1 >= len(line) || line[i+eq+1] != '"' {
+			return nil, "", errMalformedExposition
+		}
+		name := strings.TrimSpace(line[i : i+eq])
+		i += eq + 2
+
+		var value strings.Builder
+		for ; i < len(line) && line[i] != '"'; i++ {
+			if line[i] == '\\' && i+1 < len(line) {
+				i++
+				switch line[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(line[i])
+				}
+				continue
+			}
+			value.WriteByte(line[i])
+		}
+		if i >= len(line) {
+			return nil, "", errMalformedExposition
+		}
+		labels[name] = value.String()
+		i++
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/services"
+)
+
+const (
+	defaultProbeTimeout = 5 * time.Second
+	// Only the beginning of the body is matched
+	maxProbeBodyBytes = 1 << 20
+)
+
+type netProber struct {
+	dialer *net.Dialer
+	client *http.Client
+}
+
+func NewNetProber(targetPolicy services.TargetPolicy) services.Prober {
+	dialer := newTargetDialer(targetPolicy)
+	return &netProber{
+		dialer: dialer,
+		client: &http.Client{
+			// The probe timeout is set on each request. No proxy, the
+			// policy has to see the address of the target.
+			Transport: &http.Transport{
+				DialContext:       dialer.DialContext,
+				DisableKeepAlives: true,
+			},
+		},
+	}
+}
+
+func (p *netProber) Probe(ctx context.Context, probe *entity.Probe, server *entity.Server) (time.Duration, error) {
+	timeout := probe.Timeout
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch probe.Type {
+	case entity.ProbeTypeTCP:
+		return p.probeTCP(ctx, net.JoinHostPort(server.IPv4, strconv.Itoa(probe.Port)))
+	case entity.ProbeTypeHTTP:
+		return p.probeHTTP(ctx, probe)
+	default:
+		return 0, fmt.Errorf("unknown probe type %q", probe.Type)
+	}
+}
+
+func (p *netProber) probeTCP(ctx context.Context, address string) (time.Duration, error) {
+	start := time.Now()
+	conn, err := p.dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return latency, nil
+}
+
+// probeHTTP measures the latency up to the response headers
+func (p *netProber) probeHTTP(ctx context.Context, probe *entity.Probe) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	defer resp.Body.Close()
+
+	expectedStatus := probe.ExpectedStatus
+	if expectedStatus == 0 {
+		expectedStatus = http.StatusOK
+	}
+	if resp.StatusCode != expectedStatus {
+		return latency, fmt.Errorf("%w: unexpected status %d, expected %d", services.ErrProbeCheckFailed, resp.StatusCode, expectedStatus)
+	}
+
+	if probe.BodyMatch != "" {
+		pattern, err := regexp.Compile(probe.BodyMatch)
+		if err != nil {
+			return latency, fmt.Errorf("%w: invalid body match", services.ErrProbeCheckFailed)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBodyBytes))
+		if err != nil {
+			return latency, fmt.Errorf("failed to read body: %w", err)
+		}
+		if !pattern.Match(body) {
+			return latency, fmt.Errorf("%w: body does not match %q", services.ErrProbeCheckFailed, probe.BodyMatch)
+		}
+	}
+	return latency, nil
+}
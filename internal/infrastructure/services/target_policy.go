@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/domain/services"
+)
+
+const targetDialTimeout = 30 * time.Second
+
+type targetPolicy struct {
+	allowed []*net.IPNet
+	denied  []*net.IPNet
+}
+
+func NewTargetPolicy(cfg configs.TargetPolicy) (services.TargetPolicy, error) {
+	allowed, err := parseNetworks(cfg.AllowedNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target_policy.allowed_networks: %w", err)
+	}
+	denied, err := parseNetworks(cfg.DeniedNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target_policy.denied_networks: %w", err)
+	}
+	return &targetPolicy{allowed: allowed, denied: denied}, nil
+}
+
+func parseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func (p *targetPolicy) Allows(ip net.IP) bool {
+	if contains(p.denied, ip) {
+		return false
+	}
+	if contains(p.allowed, ip) {
+		return true
+	}
+	internal := ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+	return !internal
+}
+
+func (p *targetPolicy) CheckHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !p.Allows(ip) {
+			return fmt.Errorf("%w: %s", domainerrors.ErrTargetNotAllowed, host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !p.Allows(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", domainerrors.ErrTargetNotAllowed, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+func contains(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// newTargetDialer returns a dialer refusing the addresses the policy does not
+// allow. The check runs on the resolved address of every connection,
+// redirects included, so a host name validated earlier cannot be pointed at
+// an internal address later.
+func newTargetDialer(policy services.TargetPolicy) *net.Dialer {
+	return &net.Dialer{
+		Timeout: targetDialTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !policy.Allows(ip) {
+				return fmt.Errorf("%w: %s", domainerrors.ErrTargetNotAllowed, host)
+			}
+			return nil
+		},
+	}
+}
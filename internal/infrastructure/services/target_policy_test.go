@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+)
+
+func TestTargetPolicyAllows(t *testing.T) {
+	policy, err := NewTargetPolicy(configs.TargetPolicy{
+		AllowedNetworks: []string{"10.0.0.0/8"},
+		DeniedNetworks:  []string{"10.0.99.0/24", "203.0.113.0/24"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1::1", true},
+		{"10.0.0.12", true},
+		{"10.0.99.1", false},
+		{"203.0.113.7", false},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"0.0.0.0", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.Allows(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestNewTargetPolicyInvalidNetwork(t *testing.T) {
+	_, err := NewTargetPolicy(configs.TargetPolicy{AllowedNetworks: []string{"10.0.0.0"}})
+	assert.Error(t, err)
+}
+
+func TestTargetPolicyCheckHost(t *testing.T) {
+	policy, err := NewTargetPolicy(configs.TargetPolicy{})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, policy.CheckHost(context.Background(), "127.0.0.1"), domainerrors.ErrTargetNotAllowed)
+	assert.ErrorIs(t, policy.CheckHost(context.Background(), "localhost"), domainerrors.ErrTargetNotAllowed)
+	assert.NoError(t, policy.CheckHost(context.Background(), "93.184.216.34"))
+}
+
+// The policy is enforced when connecting, whatever was validated before
+func TestTargetDialerRefusesInternalAddresses(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("node_load1 0.5\n"))
+	}))
+	defer internal.Close()
+
+	denyAll, err := NewTargetPolicy(configs.TargetPolicy{})
+	require.NoError(t, err)
+	allowLoopback, err := NewTargetPolicy(configs.TargetPolicy{AllowedNetworks: []string{"127.0.0.0/8"}})
+	require.NoError(t, err)
+
+	_, err = NewHTTPMetricsScraper(configs.Scrape{}, denyAll).Scrape(context.Background(), internal.URL)
+	assert.ErrorIs(t, err, domainerrors.ErrTargetNotAllowed)
+
+	series, err := NewHTTPMetricsScraper(configs.Scrape{}, allowLoopback).Scrape(context.Background(), internal.URL)
+	require.NoError(t, err)
+	assert.Len(t, series, 1)
+
+	server := &entity.Server{IPv4: "127.0.0.1"}
+	port := internal.Listener.Addr().(*net.TCPAddr).Port
+	probes := []*entity.Probe{
+		{Type: entity.ProbeTypeHTTP, URL: internal.URL},
+		{Type: entity.ProbeTypeTCP, Port: port},
+	}
+	for _, probe := range probes {
+		_, err := NewNetProber(denyAll).Probe(context.Background(), probe, server)
+		assert.ErrorIs(t, err, domainerrors.ErrTargetNotAllowed, string(probe.Type))
+		_, err = NewNetProber(allowLoopback).Probe(context.Background(), probe, server)
+		assert.NoError(t, err, string(probe.Type))
+	}
+}
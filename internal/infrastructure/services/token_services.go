@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/th1enq/server_management_system/internal/configs"
 	"github.com/th1enq/server_management_system/internal/domain/entity"
 	"github.com/th1enq/server_management_system/internal/domain/scope"
@@ -29,6 +30,7 @@ func (j *jwtService) GenerateServerAccessToken(ctx context.Context, server *enti
 		ServerName: server.ServerName,
 		TokenType:  "server_access",
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.jwtConfig.Expiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -47,6 +49,7 @@ func (j *jwtService) GenerateServerRefreshToken(ctx context.Context, server *ent
 		ServerName: server.ServerName,
 		TokenType:  "server_refresh",
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.jwtConfig.Expiration * 7)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -122,3 +125,22 @@ func (j *jwtService) ValidateToken(tokenString string) (*dto.Claims, error) {
 
 	return nil, fmt.Errorf("invalid token claims")
 }
+
+func (j *jwtService) ValidateServerToken(tokenString string) (*dto.ServerClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &dto.ServerClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(j.jwtConfig.Secret), nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	if claims, ok := token.Claims.(*dto.ServerClaims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, fmt.Errorf("invalid token claims")
+}
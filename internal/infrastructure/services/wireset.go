@@ -6,4 +6,7 @@ var WireSet = wire.NewSet(
 	NewExcelizeService,
 	NewBcryptService,
 	NewJWTService,
+	NewHTTPMetricsScraper,
+	NewNetProber,
+	NewTargetPolicy,
 )
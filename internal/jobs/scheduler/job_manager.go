@@ -12,26 +12,42 @@ type JobManager interface {
 	Start(ctx context.Context) error
 	Stop() error
 	GetScheduler() JobScheduler
+	GetLeaderElector() LeaderElector
 }
 
 type jobManager struct {
-	scheduler        JobScheduler
-	dailyReportTask  tasks.DailyReportTask
-	updateStatusTask tasks.UpdateStatusTask
-	logger           *zap.Logger
+	scheduler         JobScheduler
+	elector           LeaderElector
+	dailyReportTask   tasks.DailyReportTask
+	updateStatusTask  tasks.UpdateStatusTask
+	scrapeMetricsTask tasks.ScrapeMetricsTask
+	probeServersTask  tasks.ProbeServersTask
+	maintenanceTask   tasks.ApplyMaintenanceWindowsTask
+	logger            *zap.Logger
+
+	stopElection context.CancelFunc
+	electionDone chan struct{}
 }
 
 func NewJobManager(
 	scheduler JobScheduler,
+	elector LeaderElector,
 	dailyReportTask tasks.DailyReportTask,
 	updateStatusTask tasks.UpdateStatusTask,
+	scrapeMetricsTask tasks.ScrapeMetricsTask,
+	probeServersTask tasks.ProbeServersTask,
+	maintenanceTask tasks.ApplyMaintenanceWindowsTask,
 	logger *zap.Logger,
 ) JobManager {
 	return &jobManager{
-		scheduler:        scheduler,
-		dailyReportTask:  dailyReportTask,
-		updateStatusTask: updateStatusTask,
-		logger:           logger,
+		scheduler:         scheduler,
+		elector:           elector,
+		dailyReportTask:   dailyReportTask,
+		updateStatusTask:  updateStatusTask,
+		scrapeMetricsTask: scrapeMetricsTask,
+		probeServersTask:  probeServersTask,
+		maintenanceTask:   maintenanceTask,
+		logger:            logger,
 	}
 }
 
@@ -41,6 +57,9 @@ func (jm *jobManager) Initialize(ctx context.Context) error {
 	taskList := []Task{
 		jm.dailyReportTask,
 		jm.updateStatusTask,
+		jm.scrapeMetricsTask,
+		jm.probeServersTask,
+		jm.maintenanceTask,
 	}
 
 	for _, task := range taskList {
@@ -69,6 +88,14 @@ func (jm *jobManager) Start(ctx context.Context) error {
 		return err
 	}
 
+	electionCtx, cancel := context.WithCancel(ctx)
+	jm.stopElection = cancel
+	jm.electionDone = make(chan struct{})
+	go func() {
+		defer close(jm.electionDone)
+		jm.elector.Run(electionCtx)
+	}()
+
 	jm.logger.Info("Job manager started successfully")
 	return nil
 }
@@ -81,6 +108,16 @@ func (jm *jobManager) Stop() error {
 		return err
 	}
 
+	// Hand the lease over right away instead of letting it expire. A renewal
+	// still in flight would take the lease back, so Run has to return first.
+	if jm.stopElection != nil {
+		jm.stopElection()
+		<-jm.electionDone
+	}
+	if err := jm.elector.Resign(context.Background()); err != nil {
+		jm.logger.Warn("Failed to resign scheduler leadership", zap.Error(err))
+	}
+
 	jm.logger.Info("Job manager stopped successfully")
 	return nil
 }
@@ -88,3 +125,7 @@ func (jm *jobManager) Stop() error {
 func (jm *jobManager) GetScheduler() JobScheduler {
 	return jm.scheduler
 }
+
+func (jm *jobManager) GetLeaderElector() LeaderElector {
+	return jm.elector
+}
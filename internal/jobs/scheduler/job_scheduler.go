@@ -33,12 +33,18 @@ type TaskInfo struct {
 }
 
 type jobScheduler struct {
-	cron   *cron.Cron
-	tasks  map[string]taskEntry
-	logger *zap.Logger
-	mutex  sync.RWMutex
-	ctx    context.Context
-	cancel context.CancelFunc
+	cron    *cron.Cron
+	tasks   map[string]taskEntry
+	elector LeaderElector
+	logger  *zap.Logger
+	mutex   sync.RWMutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	// taskCtx is ctx cut short by the leadership term it was derived from
+	term        context.Context
+	taskCtx     context.Context
+	stopTaskCtx context.CancelFunc
 }
 
 type taskEntry struct {
@@ -48,11 +54,12 @@ type taskEntry struct {
 	status  string
 }
 
-func NewJobScheduler(logger *zap.Logger) JobScheduler {
+func NewJobScheduler(elector LeaderElector, logger *zap.Logger) JobScheduler {
 	return &jobScheduler{
-		cron:   cron.New(cron.WithSeconds()),
-		tasks:  make(map[string]taskEntry),
-		logger: logger,
+		cron:    cron.New(cron.WithSeconds()),
+		tasks:   make(map[string]taskEntry),
+		elector: elector,
+		logger:  logger,
 	}
 }
 
@@ -74,6 +81,10 @@ func (js *jobScheduler) Stop() error {
 	if js.cancel != nil {
 		js.cancel()
 	}
+	if js.stopTaskCtx != nil {
+		js.stopTaskCtx()
+		js.term, js.taskCtx, js.stopTaskCtx = nil, nil, nil
+	}
 
 	stopCtx := js.cron.Stop()
 	<-stopCtx.Done()
@@ -170,12 +181,21 @@ func (js *jobScheduler) wrapTask(task Task) func() {
 	return func() {
 		taskName := task.GetName()
 
+		// Every replica schedules the tasks, only the leader runs them, and
+		// they are cancelled as soon as the lease is lost
+		ctx := js.leaderContext()
+		if ctx.Err() != nil {
+			js.logger.Debug("Skipping task execution, not the scheduler leader", zap.String("task", taskName))
+			js.updateTaskStatus(taskName, "standby")
+			return
+		}
+
 		js.logger.Info("Starting task execution", zap.String("task", taskName))
 
 		js.updateTaskStatus(taskName, "running")
 
 		start := time.Now()
-		err := task.Execute(js.ctx)
+		err := task.Execute(ctx)
 		duration := time.Since(start)
 
 		js.updateTaskLastRun(taskName, start)
@@ -195,6 +215,34 @@ func (js *jobScheduler) wrapTask(task Task) func() {
 	}
 }
 
+// leaderContext returns the scheduler context cancelled when the current
+// leadership term ends. It is shared by the runs of a term rather than
+// cancelled after each one, since tasks may hand work to pools that outlive
+// Execute.
+func (js *jobScheduler) leaderContext() context.Context {
+	term := js.elector.Term()
+
+	js.mutex.Lock()
+	defer js.mutex.Unlock()
+
+	if js.ctx == nil || term.Err() != nil {
+		return endedTerm
+	}
+	if js.term != term || js.taskCtx.Err() != nil {
+		if js.stopTaskCtx != nil {
+			js.stopTaskCtx()
+		}
+		ctx, cancel := context.WithCancel(js.ctx)
+		stop := context.AfterFunc(term, cancel)
+		js.term, js.taskCtx = term, ctx
+		js.stopTaskCtx = func() {
+			stop()
+			cancel()
+		}
+	}
+	return js.taskCtx
+}
+
 func (js *jobScheduler) updateTaskStatus(taskName, status string) {
 	js.mutex.Lock()
 	defer js.mutex.Unlock()
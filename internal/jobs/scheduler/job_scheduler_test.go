@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// blockingTask runs until its context is cancelled
+type blockingTask struct {
+	started chan struct{}
+	err     chan error
+}
+
+func (t *blockingTask) Execute(ctx context.Context) error {
+	close(t.started)
+	<-ctx.Done()
+	t.err <- ctx.Err()
+	return ctx.Err()
+}
+
+func (t *blockingTask) GetName() string     { return "blocking" }
+func (t *blockingTask) GetSchedule() string { return "@every 1h" }
+
+func TestTaskCancelledWhenLeadershipIsLost(t *testing.T) {
+	ctx := context.Background()
+	c := &leaseCache{}
+	elector := newTestElector(c, time.Minute)
+	js := NewJobScheduler(elector, zap.NewNop()).(*jobScheduler)
+	require.NoError(t, js.Start(ctx))
+	defer js.Stop()
+
+	task := &blockingTask{started: make(chan struct{}), err: make(chan error, 1)}
+	require.NoError(t, js.AddTask(task))
+
+	// Not leading yet, the task is skipped
+	js.wrapTask(task)()
+	assert.Equal(t, "standby", js.tasks[task.GetName()].status)
+
+	elector.campaign(ctx)
+	go js.wrapTask(task)()
+	<-task.started
+
+	// Another instance takes the lease in the middle of the run
+	c.take("instance-b")
+	elector.campaign(ctx)
+
+	select {
+	case err := <-task.err:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("task kept running after leadership was lost")
+	}
+}
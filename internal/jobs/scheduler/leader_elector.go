@@ -0,0 +1,250 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultLeaderKey           = "scheduler_leader"
+	defaultLeaderLeaseDuration = 15 * time.Second
+)
+
+// LeaderElector decides which instance runs the scheduled tasks when several
+// replicas share the database
+type LeaderElector interface {
+	// Run campaigns for the lease and renews it until ctx is done
+	Run(ctx context.Context)
+	// IsLeader reports whether this instance holds an unexpired lease
+	IsLeader() bool
+	// Term returns a context cancelled as soon as this instance stops leading,
+	// already cancelled when it does not lead
+	Term() context.Context
+	// Resign releases the lease if this instance holds it
+	Resign(ctx context.Context) error
+	Status(ctx context.Context) LeaderStatus
+}
+
+type LeaderStatus struct {
+	InstanceID string `json:"instance_id"`
+	IsLeader   bool   `json:"is_leader"`
+	// Leader is the instance holding the lease, empty when none does
+	Leader         string     `json:"leader"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+}
+
+// redisLeaderElector holds a lease on a Redis key set with SET NX and renews
+// it while it is still the value of the key. The local lease is counted from
+// before each request, so an instance that cannot reach Redis stops leading
+// before another one can take over.
+type redisLeaderElector struct {
+	cache         cache.CacheClient
+	key           string
+	instanceID    string
+	leaseDuration time.Duration
+	renewInterval time.Duration
+	logger        *zap.Logger
+
+	mutex          sync.RWMutex
+	leaseExpiresAt time.Time
+	// term lasts as long as the lease, expiry ends it if no renewal lands in
+	// time
+	term    context.Context
+	endTerm context.CancelFunc
+	expiry  *time.Timer
+}
+
+// endedTerm is handed out while this instance does not lead
+var endedTerm = func() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}()
+
+func NewRedisLeaderElector(cacheClient cache.CacheClient, cfg configs.LeaderElection, logger *zap.Logger) LeaderElector {
+	elector := &redisLeaderElector{
+		cache:         cacheClient,
+		key:           cfg.Key,
+		instanceID:    cfg.InstanceID,
+		leaseDuration: cfg.LeaseDuration,
+		renewInterval: cfg.RenewInterval,
+		logger:        logger,
+	}
+	if elector.key == "" {
+		elector.key = defaultLeaderKey
+	}
+	if elector.instanceID == "" {
+		elector.instanceID = defaultInstanceID()
+	}
+	if elector.leaseDuration <= 0 {
+		elector.leaseDuration = defaultLeaderLeaseDuration
+	}
+	// Renewing less often than the lease lasts would drop leadership between
+	// two renewals
+	if elector.renewInterval <= 0 || elector.renewInterval >= elector.leaseDuration {
+		elector.renewInterval = elector.leaseDuration / 3
+	}
+	return elector
+}
+
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+func (e *redisLeaderElector) Run(ctx context.Context) {
+	e.logger.Info("Starting leader election",
+		zap.String("instance_id", e.instanceID),
+		zap.String("key", e.key),
+		zap.Duration("lease_duration", e.leaseDuration))
+
+	ticker := time.NewTicker(e.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		e.campaign(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign renews the lease held by this instance, or tries to take it when
+// it is free
+func (e *redisLeaderElector) campaign(ctx context.Context) {
+	start := time.Now()
+	wasLeader := e.IsLeader()
+
+	acquired := false
+	var err error
+	if e.holdsLease() {
+		acquired, err = e.cache.ExpireIfEqual(ctx, e.key, e.instanceID, e.leaseDuration)
+	}
+	if err == nil && !acquired {
+		acquired, err = e.cache.SetNX(ctx, e.key, e.instanceID, e.leaseDuration)
+	}
+	if err != nil {
+		// The current lease, if any, runs out on its own
+		e.logger.Warn("Failed to renew leader lease",
+			zap.String("instance_id", e.instanceID),
+			zap.Error(err))
+		return
+	}
+
+	e.mutex.Lock()
+	if acquired {
+		e.leaseExpiresAt = start.Add(e.leaseDuration)
+		if e.term == nil {
+			e.term, e.endTerm = context.WithCancel(context.Background())
+		}
+		if e.expiry != nil {
+			e.expiry.Stop()
+		}
+		e.expiry = time.AfterFunc(time.Until(e.leaseExpiresAt), e.expireTerm)
+	} else {
+		e.leaseExpiresAt = time.Time{}
+		e.stopTerm()
+	}
+	e.mutex.Unlock()
+
+	switch {
+	case acquired && !wasLeader:
+		e.logger.Info("Became scheduler leader", zap.String("instance_id", e.instanceID))
+	case !acquired && wasLeader:
+		e.logger.Warn("Lost scheduler leadership", zap.String("instance_id", e.instanceID))
+	}
+}
+
+// expireTerm ends the term when the lease ran out without being renewed
+func (e *redisLeaderElector) expireTerm() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if !time.Now().Before(e.leaseExpiresAt) {
+		e.stopTerm()
+	}
+}
+
+// stopTerm must be called with the mutex held
+func (e *redisLeaderElector) stopTerm() {
+	if e.expiry != nil {
+		e.expiry.Stop()
+		e.expiry = nil
+	}
+	if e.endTerm != nil {
+		e.endTerm()
+		e.term, e.endTerm = nil, nil
+	}
+}
+
+func (e *redisLeaderElector) holdsLease() bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return !e.leaseExpiresAt.IsZero()
+}
+
+func (e *redisLeaderElector) IsLeader() bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return time.Now().Before(e.leaseExpiresAt)
+}
+
+func (e *redisLeaderElector) Term() context.Context {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	if e.term == nil || !time.Now().Before(e.leaseExpiresAt) {
+		return endedTerm
+	}
+	return e.term
+}
+
+func (e *redisLeaderElector) Resign(ctx context.Context) error {
+	if !e.holdsLease() {
+		return nil
+	}
+
+	e.mutex.Lock()
+	e.leaseExpiresAt = time.Time{}
+	e.stopTerm()
+	e.mutex.Unlock()
+
+	if _, err := e.cache.DelIfEqual(ctx, e.key, e.instanceID); err != nil {
+		return fmt.Errorf("failed to release leader lease: %w", err)
+	}
+	e.logger.Info("Resigned scheduler leadership", zap.String("instance_id", e.instanceID))
+	return nil
+}
+
+func (e *redisLeaderElector) Status(ctx context.Context) LeaderStatus {
+	status := LeaderStatus{
+		InstanceID: e.instanceID,
+		IsLeader:   e.IsLeader(),
+	}
+
+	var leader string
+	if err := e.cache.Get(ctx, e.key, &leader); err != nil && !errors.Is(err, cache.ErrCacheMiss) {
+		e.logger.Warn("Failed to get scheduler leader", zap.Error(err))
+	}
+	status.Leader = leader
+
+	if status.IsLeader {
+		e.mutex.RLock()
+		expiresAt := e.leaseExpiresAt
+		e.mutex.RUnlock()
+		status.LeaseExpiresAt = &expiresAt
+	}
+	return status
+}
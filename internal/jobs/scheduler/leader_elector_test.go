@@ -0,0 +1,193 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+// leaseCache keeps the leader key in memory, without expiry, and fails every
+// call while down is set
+type leaseCache struct {
+	cache.CacheClient
+
+	mutex  sync.Mutex
+	holder string
+	down   bool
+}
+
+func (c *leaseCache) SetNX(ctx context.Context, key string, data any, ttl time.Duration) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.down {
+		return false, errors.New("connection refused")
+	}
+	if c.holder != "" {
+		return false, nil
+	}
+	c.holder = data.(string)
+	return true, nil
+}
+
+func (c *leaseCache) ExpireIfEqual(ctx context.Context, key string, data any, ttl time.Duration) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.down {
+		return false, errors.New("connection refused")
+	}
+	return c.holder == data.(string), nil
+}
+
+func (c *leaseCache) DelIfEqual(ctx context.Context, key string, data any) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.holder != data.(string) {
+		return false, nil
+	}
+	c.holder = ""
+	return true, nil
+}
+
+func (c *leaseCache) take(holder string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.holder = holder
+}
+
+func (c *leaseCache) setDown(down bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.down = down
+}
+
+func newTestElector(c *leaseCache, lease time.Duration) *redisLeaderElector {
+	return NewRedisLeaderElector(c, configs.LeaderElection{
+		InstanceID:    "instance-a",
+		LeaseDuration: lease,
+	}, zap.NewNop()).(*redisLeaderElector)
+}
+
+func TestLeaderTerm(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// lose ends the leadership taken by the first campaign
+		lose func(e *redisLeaderElector, c *leaseCache)
+	}{
+		{
+			name: "lease taken by another instance",
+			lose: func(e *redisLeaderElector, c *leaseCache) {
+				c.take("instance-b")
+				e.campaign(ctx)
+			},
+		},
+		{
+			name: "lease expires while redis is unreachable",
+			lose: func(e *redisLeaderElector, c *leaseCache) {
+				c.setDown(true)
+				e.campaign(ctx)
+			},
+		},
+		{
+			name: "resign",
+			lose: func(e *redisLeaderElector, c *leaseCache) {
+				require.NoError(t, e.Resign(ctx))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &leaseCache{}
+			e := newTestElector(c, 50*time.Millisecond)
+
+			assert.Error(t, e.Term().Err(), "no term before the first campaign")
+
+			e.campaign(ctx)
+			require.True(t, e.IsLeader())
+			term := e.Term()
+			require.NoError(t, term.Err())
+
+			// A renewal keeps the same term
+			e.campaign(ctx)
+			assert.Equal(t, term, e.Term())
+
+			tt.lose(e, c)
+			select {
+			case <-term.Done():
+			case <-time.After(time.Second):
+				t.Fatal("term not cancelled after leadership was lost")
+			}
+			assert.False(t, e.IsLeader())
+			assert.Error(t, e.Term().Err())
+		})
+	}
+}
+
+func TestLeaderTermOutlivesRenewals(t *testing.T) {
+	ctx := context.Background()
+	c := &leaseCache{}
+	e := newTestElector(c, 60*time.Millisecond)
+
+	e.campaign(ctx)
+	term := e.Term()
+	// Renewing before each expiry keeps the term going past the first lease
+	for range 4 {
+		time.Sleep(20 * time.Millisecond)
+		e.campaign(ctx)
+	}
+	assert.NoError(t, term.Err())
+}
+
+// orderedElector records the order in which the job manager drives it
+type orderedElector struct {
+	LeaderElector
+
+	mutex  sync.Mutex
+	events []string
+}
+
+func (e *orderedElector) record(event string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *orderedElector) Run(ctx context.Context) {
+	<-ctx.Done()
+	// A renewal still in flight when the election is stopped
+	time.Sleep(20 * time.Millisecond)
+	e.record("run returned")
+}
+
+func (e *orderedElector) Resign(ctx context.Context) error {
+	e.record("resigned")
+	return nil
+}
+
+type idleScheduler struct {
+	JobScheduler
+}
+
+func (idleScheduler) Start(ctx context.Context) error { return nil }
+func (idleScheduler) Stop() error                     { return nil }
+func (idleScheduler) AddTask(task Task) error         { return nil }
+
+func TestJobManagerStopWaitsForElection(t *testing.T) {
+	elector := &orderedElector{}
+	jm := NewJobManager(idleScheduler{}, elector, nil, nil, nil, nil, nil, zap.NewNop())
+
+	require.NoError(t, jm.Start(context.Background()))
+	require.NoError(t, jm.Stop())
+
+	assert.Equal(t, []string{"run returned", "resigned"}, elector.events)
+}
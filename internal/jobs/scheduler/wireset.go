@@ -4,6 +4,7 @@ import "github.com/google/wire"
 
 // WireSet provides dependency injection for scheduler
 var WireSet = wire.NewSet(
+	NewRedisLeaderElector,
 	NewJobScheduler,
 	NewJobManager,
 )
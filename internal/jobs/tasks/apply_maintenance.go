@@ -0,0 +1,52 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+type ApplyMaintenanceWindowsTask interface {
+	Execute(ctx context.Context) error
+	GetName() string
+	GetSchedule() string
+}
+
+type applyMaintenanceWindowsTask struct {
+	maintenanceWindowUseCase usecases.MaintenanceWindowUseCase
+	cronConfig               configs.Cron
+	logger                   *zap.Logger
+}
+
+func NewApplyMaintenanceWindowsTask(
+	maintenanceWindowUseCase usecases.MaintenanceWindowUseCase,
+	cronConfig configs.Cron,
+	logger *zap.Logger,
+) ApplyMaintenanceWindowsTask {
+	return &applyMaintenanceWindowsTask{
+		maintenanceWindowUseCase: maintenanceWindowUseCase,
+		cronConfig:               cronConfig,
+		logger:                   logger,
+	}
+}
+
+// Execute moves servers in and out of MAINTENANCE, windows start and end
+// within one schedule interval of their planned time
+func (t *applyMaintenanceWindowsTask) Execute(ctx context.Context) error {
+	if err := t.maintenanceWindowUseCase.Apply(ctx); err != nil {
+		t.logger.Error("Apply maintenance windows task failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (t *applyMaintenanceWindowsTask) GetName() string {
+	return t.cronConfig.ApplyMaintenanceWindows.Name
+}
+
+func (t *applyMaintenanceWindowsTask) GetSchedule() string {
+	return t.cronConfig.ApplyMaintenanceWindows.Schedule
+}
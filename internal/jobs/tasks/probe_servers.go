@@ -0,0 +1,52 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+type ProbeServersTask interface {
+	Execute(ctx context.Context) error
+	GetName() string
+	GetSchedule() string
+}
+
+type probeServersTask struct {
+	probeUseCase usecases.ProbeUseCase
+	cronConfig   configs.Cron
+	logger       *zap.Logger
+}
+
+func NewProbeServersTask(
+	probeUseCase usecases.ProbeUseCase,
+	cronConfig configs.Cron,
+	logger *zap.Logger,
+) ProbeServersTask {
+	return &probeServersTask{
+		probeUseCase: probeUseCase,
+		cronConfig:   cronConfig,
+		logger:       logger,
+	}
+}
+
+// Execute only starts the probes that are due, so the schedule is the
+// resolution of the per server intervals rather than the probe interval
+func (t *probeServersTask) Execute(ctx context.Context) error {
+	if err := t.probeUseCase.RunDue(ctx); err != nil {
+		t.logger.Error("Probe servers task failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (t *probeServersTask) GetName() string {
+	return t.cronConfig.ProbeServers.Name
+}
+
+func (t *probeServersTask) GetSchedule() string {
+	return t.cronConfig.ProbeServers.Schedule
+}
@@ -0,0 +1,52 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/usecases"
+	"go.uber.org/zap"
+)
+
+type ScrapeMetricsTask interface {
+	Execute(ctx context.Context) error
+	GetName() string
+	GetSchedule() string
+}
+
+type scrapeMetricsTask struct {
+	scrapeUseCase usecases.ScrapeUseCase
+	cronConfig    configs.Cron
+	logger        *zap.Logger
+}
+
+func NewScrapeMetricsTask(
+	scrapeUseCase usecases.ScrapeUseCase,
+	cronConfig configs.Cron,
+	logger *zap.Logger,
+) ScrapeMetricsTask {
+	return &scrapeMetricsTask{
+		scrapeUseCase: scrapeUseCase,
+		cronConfig:    cronConfig,
+		logger:        logger,
+	}
+}
+
+// Execute only starts the scrapes that are due, so the schedule is the
+// resolution of the per server intervals rather than the scrape interval
+func (t *scrapeMetricsTask) Execute(ctx context.Context) error {
+	if err := t.scrapeUseCase.ScrapeDue(ctx); err != nil {
+		t.logger.Error("Scrape metrics task failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (t *scrapeMetricsTask) GetName() string {
+	return t.cronConfig.ScrapeMetrics.Name
+}
+
+func (t *scrapeMetricsTask) GetSchedule() string {
+	return t.cronConfig.ScrapeMetrics.Schedule
+}
@@ -5,4 +5,7 @@ import "github.com/google/wire"
 var WireSet = wire.NewSet(
 	NewDailyReportTask,
 	NewUpdateStatusTask,
+	NewScrapeMetricsTask,
+	NewProbeServersTask,
+	NewApplyMaintenanceWindowsTask,
 )
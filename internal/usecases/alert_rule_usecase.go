@@ -0,0 +1,412 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+const alertRulesCacheKey = "alert_rules"
+
+type AlertRuleUseCase interface {
+	CreateRule(ctx context.Context, req dto.CreateAlertRuleRequest, createdBy uint) (*entity.AlertRule, error)
+	ListRules(ctx context.Context) ([]*entity.AlertRule, error)
+	GetRule(ctx context.Context, id uint) (*entity.AlertRule, error)
+	UpdateRule(ctx context.Context, id uint, req dto.UpdateAlertRuleRequest) (*entity.AlertRule, error)
+	DeleteRule(ctx context.Context, id uint) error
+	ListStates(ctx context.Context, id uint) ([]*entity.AlertRuleState, error)
+	// Evaluate runs the enabled rules against a live sample and reports
+	// whether an alert of the server fired or resolved
+	Evaluate(ctx context.Context, metrics *entity.ServerMetrics) (bool, error)
+	// HasFiring reports whether an alert of the server is firing
+	HasFiring(ctx context.Context, serverID string) (bool, error)
+}
+
+type alertRuleUseCase struct {
+	alertRuleRepo repository.AlertRuleRepository
+	serverRepo    repository.ServerRepository
+	inMemoryCache cache.InMemoryCache
+	logger        *zap.Logger
+}
+
+func NewAlertRuleUseCase(alertRuleRepo repository.AlertRuleRepository, serverRepo repository.ServerRepository, inMemoryCache cache.InMemoryCache, logger *zap.Logger) AlertRuleUseCase {
+	return &alertRuleUseCase{
+		alertRuleRepo: alertRuleRepo,
+		serverRepo:    serverRepo,
+		inMemoryCache: inMemoryCache,
+		logger:        logger,
+	}
+}
+
+func (a *alertRuleUseCase) CreateRule(ctx context.Context, req dto.CreateAlertRuleRequest, createdBy uint) (*entity.AlertRule, error) {
+	rule := &entity.AlertRule{
+		Name:      req.Name,
+		Metric:    req.Metric,
+		Operator:  req.Operator,
+		Threshold: req.Threshold,
+		Location:  req.Location,
+		Tags:      req.Tags,
+		Severity:  entity.AlertSeverity(req.Severity),
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		CreatedBy: createdBy,
+	}
+	if rule.Severity == "" {
+		rule.Severity = entity.AlertSeverityWarning
+	}
+	if req.For != "" {
+		duration, err := parseAlertDuration(req.For)
+		if err != nil {
+			return nil, err
+		}
+		rule.For = duration
+	}
+	if err := validateAlertMetric(rule.Metric); err != nil {
+		return nil, err
+	}
+
+	if err := a.alertRuleRepo.Create(ctx, rule); err != nil {
+		a.logger.Error("Failed to create alert rule",
+			zap.String("name", rule.Name),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	a.inMemoryCache.Delete(alertRulesCacheKey)
+
+	a.logger.Info("Alert rule created",
+		zap.Uint("id", rule.ID),
+		zap.String("name", rule.Name),
+		zap.Uint("created_by", createdBy),
+	)
+	return rule, nil
+}
+
+func (a *alertRuleUseCase) ListRules(ctx context.Context) ([]*entity.AlertRule, error) {
+	rules, err := a.alertRuleRepo.List(ctx)
+	if err != nil {
+		a.logger.Error("Failed to list alert rules", zap.Error(err))
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (a *alertRuleUseCase) GetRule(ctx context.Context, id uint) (*entity.AlertRule, error) {
+	rule, err := a.alertRuleRepo.GetByID(ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to get alert rule by ID",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return nil, domainerrors.ErrAlertRuleNotFound
+	}
+	return rule, nil
+}
+
+func (a *alertRuleUseCase) UpdateRule(ctx context.Context, id uint, req dto.UpdateAlertRuleRequest) (*entity.AlertRule, error) {
+	rule, err := a.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previous := *rule
+
+	if req.Name != "" {
+		rule.Name = req.Name
+	}
+	if req.Metric != "" {
+		if err := validateAlertMetric(req.Metric); err != nil {
+			return nil, err
+		}
+		rule.Metric = req.Metric
+	}
+	if req.Operator != "" {
+		rule.Operator = req.Operator
+	}
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+	if req.For != nil {
+		duration, err := parseAlertDuration(*req.For)
+		if err != nil {
+			return nil, err
+		}
+		rule.For = duration
+	}
+	if req.Location != nil {
+		rule.Location = *req.Location
+	}
+	if req.Tags != nil {
+		rule.Tags = req.Tags
+	}
+	if req.Severity != "" {
+		rule.Severity = entity.AlertSeverity(req.Severity)
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := a.alertRuleRepo.Update(ctx, rule); err != nil {
+		a.logger.Error("Failed to update alert rule",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	a.inMemoryCache.Delete(alertRulesCacheKey)
+
+	// Alerts raised under the old condition no longer mean anything
+	if previous.Enabled && (!rule.Enabled || conditionChanged(&previous, rule)) {
+		a.resolveRule(ctx, &previous)
+	}
+
+	a.logger.Info("Alert rule updated", zap.Uint("id", rule.ID))
+	return rule, nil
+}
+
+func (a *alertRuleUseCase) DeleteRule(ctx context.Context, id uint) error {
+	rule, err := a.GetRule(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	a.resolveRule(ctx, rule)
+	if err := a.alertRuleRepo.Delete(ctx, id); err != nil {
+		a.logger.Error("Failed to delete alert rule",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	a.inMemoryCache.Delete(alertRulesCacheKey)
+
+	a.logger.Info("Alert rule deleted", zap.Uint("id", id))
+	return nil
+}
+
+func (a *alertRuleUseCase) ListStates(ctx context.Context, id uint) ([]*entity.AlertRuleState, error) {
+	if _, err := a.GetRule(ctx, id); err != nil {
+		return nil, err
+	}
+	states, err := a.alertRuleRepo.ListStates(ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to list alert states",
+			zap.Uint("rule_id", id),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list alert states: %w", err)
+	}
+	return states, nil
+}
+
+func (a *alertRuleUseCase) Evaluate(ctx context.Context, metrics *entity.ServerMetrics) (bool, error) {
+	rules, err := a.enabledRules(ctx)
+	if err != nil || len(rules) == 0 {
+		return false, err
+	}
+
+	var server *entity.Server
+	matching := make([]*entity.AlertRule, 0, len(rules))
+	ruleIDs := make([]uint, 0, len(rules))
+	for _, rule := range rules {
+		if _, ok := metrics.Value(rule.Metric); !ok {
+			continue
+		}
+		if rule.HasSelector() {
+			if server == nil {
+				if server, err = a.serverRepo.GetByServerID(ctx, metrics.ServerID); err != nil {
+					return false, fmt.Errorf("failed to get server: %w", err)
+				}
+			}
+			if !rule.Matches(server) {
+				continue
+			}
+		}
+		matching = append(matching, rule)
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	if len(matching) == 0 {
+		return false, nil
+	}
+
+	states, err := a.alertRuleRepo.GetStates(ctx, metrics.ServerID, ruleIDs)
+	if err != nil {
+		return false, fmt.Errorf("failed to get alert states: %w", err)
+	}
+	stateByRule := make(map[uint]*entity.AlertRuleState, len(states))
+	for _, state := range states {
+		stateByRule[state.RuleID] = state
+	}
+
+	changed := false
+	for _, rule := range matching {
+		value, _ := metrics.Value(rule.Metric)
+		notified, err := a.transition(ctx, rule, stateByRule[rule.ID], metrics.ServerID, value, metrics.Timestamp)
+		changed = changed || notified
+		if err != nil {
+			a.logger.Error("Failed to update alert state",
+				zap.Uint("rule_id", rule.ID),
+				zap.String("server_id", metrics.ServerID),
+				zap.Error(err),
+			)
+		}
+	}
+	return changed, nil
+}
+
+func (a *alertRuleUseCase) HasFiring(ctx context.Context, serverID string) (bool, error) {
+	count, err := a.alertRuleRepo.CountFiring(ctx, serverID)
+	if err != nil {
+		return false, fmt.Errorf("failed to count firing alerts: %w", err)
+	}
+	return count > 0, nil
+}
+
+// transition moves the rule state of a server on a new sample:
+// a breach starts pending, a breach lasting the For duration fires,
+// a recovery drops pending and resolves firing. Firing and resolving are
+// published, pending is only tracked. It reports whether the alert fired or
+// resolved.
+func (a *alertRuleUseCase) transition(ctx context.Context, rule *entity.AlertRule, current *entity.AlertRuleState, serverID string, value float64, at time.Time) (bool, error) {
+	// Late samples must not undo a newer decision
+	if current != nil && at.Before(current.UpdatedAt) {
+		return false, nil
+	}
+
+	breached := rule.Breached(value)
+	active := current != nil && current.State != entity.AlertStateResolved
+	switch {
+	case breached && !active:
+		state := &entity.AlertRuleState{
+			RuleID:       rule.ID,
+			ServerID:     serverID,
+			State:        entity.AlertStatePending,
+			Value:        value,
+			PendingSince: at,
+			UpdatedAt:    at,
+		}
+		if rule.For <= 0 {
+			return true, a.fire(ctx, rule, state, value, at)
+		}
+		return false, a.alertRuleRepo.SaveState(ctx, rule, state, false)
+	case breached && current.State == entity.AlertStatePending:
+		if at.Sub(current.PendingSince) < rule.For {
+			return false, nil
+		}
+		return true, a.fire(ctx, rule, current, value, at)
+	case !breached && current != nil && current.State == entity.AlertStatePending:
+		return false, a.alertRuleRepo.DeleteState(ctx, rule.ID, serverID)
+	case !breached && current != nil && current.State == entity.AlertStateFiring:
+		return true, a.resolve(ctx, rule, current, value, at)
+	}
+	return false, nil
+}
+
+func (a *alertRuleUseCase) fire(ctx context.Context, rule *entity.AlertRule, state *entity.AlertRuleState, value float64, at time.Time) error {
+	state.State = entity.AlertStateFiring
+	state.Value = value
+	state.FiredAt = &at
+	state.ResolvedAt = nil
+	state.UpdatedAt = at
+	if err := a.alertRuleRepo.SaveState(ctx, rule, state, true); err != nil {
+		return err
+	}
+	a.logger.Warn("Alert firing",
+		zap.Uint("rule_id", rule.ID),
+		zap.String("rule_name", rule.Name),
+		zap.String("server_id", state.ServerID),
+		zap.Float64("value", value),
+	)
+	return nil
+}
+
+func (a *alertRuleUseCase) resolve(ctx context.Context, rule *entity.AlertRule, state *entity.AlertRuleState, value float64, at time.Time) error {
+	state.State = entity.AlertStateResolved
+	state.Value = value
+	state.ResolvedAt = &at
+	state.UpdatedAt = at
+	if err := a.alertRuleRepo.SaveState(ctx, rule, state, true); err != nil {
+		return err
+	}
+	a.logger.Info("Alert resolved",
+		zap.Uint("rule_id", rule.ID),
+		zap.String("rule_name", rule.Name),
+		zap.String("server_id", state.ServerID),
+		zap.Float64("value", value),
+	)
+	return nil
+}
+
+// resolveRule resolves the firing alerts of a rule and drops its pending ones
+func (a *alertRuleUseCase) resolveRule(ctx context.Context, rule *entity.AlertRule) {
+	states, err := a.alertRuleRepo.ListStates(ctx, rule.ID)
+	if err != nil {
+		a.logger.Error("Failed to list alert states", zap.Uint("rule_id", rule.ID), zap.Error(err))
+		return
+	}
+	now := time.Now()
+	for _, state := range states {
+		switch state.State {
+		case entity.AlertStateFiring:
+			err = a.resolve(ctx, rule, state, state.Value, now)
+		case entity.AlertStatePending:
+			err = a.alertRuleRepo.DeleteState(ctx, rule.ID, state.ServerID)
+		default:
+			continue
+		}
+		if err != nil {
+			a.logger.Error("Failed to resolve alert state",
+				zap.Uint("rule_id", rule.ID),
+				zap.String("server_id", state.ServerID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func (a *alertRuleUseCase) enabledRules(ctx context.Context) ([]*entity.AlertRule, error) {
+	if cached, err := a.inMemoryCache.Get(alertRulesCacheKey); err == nil {
+		if rules, ok := cached.([]*entity.AlertRule); ok {
+			return rules, nil
+		}
+	}
+	rules, err := a.alertRuleRepo.ListEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	a.inMemoryCache.Set(alertRulesCacheKey, rules)
+	return rules, nil
+}
+
+func validateAlertMetric(metric string) error {
+	if !entity.IsAlertMetric(metric) {
+		return fmt.Errorf("%w: unknown metric %q", domainerrors.ErrInvalidInput, metric)
+	}
+	if name, ok := strings.CutPrefix(metric, entity.CustomMetricPrefix); ok && !gaugeNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: invalid custom gauge name %q", domainerrors.ErrInvalidInput, name)
+	}
+	return nil
+}
+
+func parseAlertDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("%w: invalid for duration %q", domainerrors.ErrInvalidInput, value)
+	}
+	return duration.Truncate(time.Second), nil
+}
+
+func conditionChanged(a, b *entity.AlertRule) bool {
+	return a.Metric != b.Metric || a.Operator != b.Operator || a.Threshold != b.Threshold ||
+		a.For != b.For || a.Location != b.Location || !slices.Equal(a.Tags, b.Tags)
+}
@@ -16,6 +16,7 @@ type AuthUseCase interface {
 	Register(ctx context.Context, req dto.RegisterRequest) (*dto.AuthResponse, error)
 	RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (*dto.AuthResponse, error)
 	ValidateToken(ctx context.Context, tokenString string) (*dto.Claims, error)
+	ValidateServerToken(ctx context.Context, tokenString string) (*dto.ServerClaims, error)
 	Logout(ctx context.Context, userID uint) error
 }
 
@@ -153,6 +154,15 @@ func (a *authUseCase) ValidateToken(ctx context.Context, tokenString string) (*d
 	return a.tokenServices.ValidateToken(tokenString)
 }
 
+func (a *authUseCase) ValidateServerToken(ctx context.Context, tokenString string) (*dto.ServerClaims, error) {
+	// Check if token is whitelisted
+	if !a.tokenRepository.IsTokenWhitelisted(ctx, tokenString) {
+		a.logger.Warn("Server token is not whitelisted")
+		return nil, fmt.Errorf("token is not whitelisted")
+	}
+	return a.tokenServices.ValidateServerToken(tokenString)
+}
+
 // RefreshToken implements authUseCase.
 func (a *authUseCase) RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
 	refreshToken := req.RefreshToken
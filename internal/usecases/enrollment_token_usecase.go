@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"go.uber.org/zap"
+)
+
+const (
+	enrollmentTokenPrefix = "enr_"
+	enrollmentTokenBytes  = 32
+)
+
+type EnrollmentTokenUseCase interface {
+	CreateToken(ctx context.Context, req dto.CreateEnrollmentTokenRequest, createdBy uint) (*dto.CreateEnrollmentTokenResponse, error)
+	ListTokens(ctx context.Context) ([]*entity.EnrollmentToken, error)
+	RevokeToken(ctx context.Context, id uint) error
+}
+
+type enrollmentTokenUseCase struct {
+	enrollmentTokenRepo repository.EnrollmentTokenRepository
+	logger              *zap.Logger
+}
+
+func NewEnrollmentTokenUseCase(enrollmentTokenRepo repository.EnrollmentTokenRepository, logger *zap.Logger) EnrollmentTokenUseCase {
+	return &enrollmentTokenUseCase{
+		enrollmentTokenRepo: enrollmentTokenRepo,
+		logger:              logger,
+	}
+}
+
+func (e *enrollmentTokenUseCase) CreateToken(ctx context.Context, req dto.CreateEnrollmentTokenRequest, createdBy uint) (*dto.CreateEnrollmentTokenResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", domainerrors.ErrInvalidInput)
+	}
+
+	plain, err := generateEnrollmentToken()
+	if err != nil {
+		e.logger.Error("Failed to generate enrollment token", zap.Error(err))
+		return nil, fmt.Errorf("failed to generate enrollment token: %w", err)
+	}
+
+	token := &entity.EnrollmentToken{
+		Name:            req.Name,
+		TokenHash:       hashEnrollmentToken(plain),
+		Location:        req.Location,
+		Tags:            req.Tags,
+		MaxUses:         req.MaxUses,
+		RequireApproval: req.RequireApproval,
+		ExpiresAt:       req.ExpiresAt,
+		CreatedBy:       createdBy,
+	}
+	if token.MaxUses == 0 {
+		token.MaxUses = 1
+	}
+
+	if err := e.enrollmentTokenRepo.Create(ctx, token); err != nil {
+		e.logger.Error("Failed to create enrollment token",
+			zap.String("name", req.Name),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to create enrollment token: %w", err)
+	}
+
+	e.logger.Info("Enrollment token created",
+		zap.Uint("id", token.ID),
+		zap.String("name", token.Name),
+		zap.Int("max_uses", token.MaxUses),
+		zap.Uint("created_by", createdBy),
+	)
+
+	return &dto.CreateEnrollmentTokenResponse{
+		EnrollmentTokenResponse: dto.FromEntityToEnrollmentTokenResponse(token),
+		Token:                   plain,
+	}, nil
+}
+
+func (e *enrollmentTokenUseCase) ListTokens(ctx context.Context) ([]*entity.EnrollmentToken, error) {
+	tokens, err := e.enrollmentTokenRepo.List(ctx)
+	if err != nil {
+		e.logger.Error("Failed to list enrollment tokens", zap.Error(err))
+		return nil, fmt.Errorf("failed to list enrollment tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (e *enrollmentTokenUseCase) RevokeToken(ctx context.Context, id uint) error {
+	if _, err := e.enrollmentTokenRepo.GetByID(ctx, id); err != nil {
+		e.logger.Error("Failed to get enrollment token by ID",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return domainerrors.ErrEnrollmentTokenNotFound
+	}
+
+	if err := e.enrollmentTokenRepo.Revoke(ctx, id); err != nil {
+		e.logger.Error("Failed to revoke enrollment token",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to revoke enrollment token: %w", err)
+	}
+
+	e.logger.Info("Enrollment token revoked", zap.Uint("id", id))
+	return nil
+}
+
+func generateEnrollmentToken() (string, error) {
+	buf := make([]byte, enrollmentTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return enrollmentTokenPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashEnrollmentToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
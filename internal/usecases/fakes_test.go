@@ -0,0 +1,233 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+// memCache keeps the keys, sets and sorted sets used by the status tracking
+type memCache struct {
+	cache.CacheClient
+	values map[string][]byte
+	sets   map[string]map[string]bool
+	zsets  map[string]map[string]float64
+}
+
+func newMemCache() *memCache {
+	return &memCache{
+		values: make(map[string][]byte),
+		sets:   make(map[string]map[string]bool),
+		zsets:  make(map[string]map[string]float64),
+	}
+}
+
+func (c *memCache) Set(ctx context.Context, key string, data any, ttl time.Duration) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	c.values[key] = b
+	return nil
+}
+
+func (c *memCache) Get(ctx context.Context, key string, dest any) error {
+	b, ok := c.values[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(b, dest)
+}
+
+func (c *memCache) SADD(ctx context.Context, key string, members ...string) error {
+	if c.sets[key] == nil {
+		c.sets[key] = make(map[string]bool)
+	}
+	for _, member := range members {
+		c.sets[key][member] = true
+	}
+	return nil
+}
+
+func (c *memCache) SMEMBERS(ctx context.Context, key string) ([]string, error) {
+	members := make([]string, 0, len(c.sets[key]))
+	for member := range c.sets[key] {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (c *memCache) SISMEMBER(ctx context.Context, key string, member string) (bool, error) {
+	return c.sets[key][member], nil
+}
+
+func (c *memCache) SREM(ctx context.Context, key string, members ...string) error {
+	for _, member := range members {
+		delete(c.sets[key], member)
+	}
+	return nil
+}
+
+func (c *memCache) ZADDNX(ctx context.Context, key string, score float64, member string) error {
+	if c.zsets[key] == nil {
+		c.zsets[key] = make(map[string]float64)
+	}
+	if _, ok := c.zsets[key][member]; !ok {
+		c.zsets[key][member] = score
+	}
+	return nil
+}
+
+func (c *memCache) ZCOUNT(ctx context.Context, key string, min float64, max float64) (int64, error) {
+	var count int64
+	for _, score := range c.zsets[key] {
+		if score >= min && score <= max {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (c *memCache) ZAddWindow(ctx context.Context, key string, score float64, member string, window time.Duration) (int64, error) {
+	if c.zsets[key] == nil {
+		c.zsets[key] = make(map[string]float64)
+	}
+	c.zsets[key][member] = score
+	for m, s := range c.zsets[key] {
+		if s < score-float64(window.Milliseconds()) {
+			delete(c.zsets[key], m)
+		}
+	}
+	return int64(len(c.zsets[key])), nil
+}
+
+// addFlips records flips of a server at the given ages
+func (c *memCache) addFlips(serverID string, now time.Time, ages ...time.Duration) {
+	key := fmt.Sprintf("status_flips:%s", serverID)
+	if c.zsets[key] == nil {
+		c.zsets[key] = make(map[string]float64)
+	}
+	for i, age := range ages {
+		c.zsets[key][fmt.Sprintf("flip-%d", i)] = float64(now.Add(-age).UnixMilli())
+	}
+}
+
+// statusRepo holds servers by ID and applies the status updates
+type statusRepo struct {
+	repository.ServerRepository
+	servers    map[string]*entity.Server
+	resolved   map[string]entity.ServerStatus
+	rootCauses map[string]string
+}
+
+func newStatusRepo(servers ...*entity.Server) *statusRepo {
+	r := &statusRepo{servers: make(map[string]*entity.Server), resolved: make(map[string]entity.ServerStatus)}
+	for _, server := range servers {
+		r.servers[server.ServerID] = server
+	}
+	return r
+}
+
+func (r *statusRepo) GetByServerID(ctx context.Context, serverID string) (*entity.Server, error) {
+	server, ok := r.servers[serverID]
+	if !ok {
+		return nil, fmt.Errorf("server %s not found", serverID)
+	}
+	copied := *server
+	return &copied, nil
+}
+
+func (r *statusRepo) UpdateStatus(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) (bool, error) {
+	server := r.servers[serverID]
+	if server == nil || !server.Status.CanTransitionTo(status) || server.Status == entity.ServerStatusMaintenance || server.Status == entity.ServerStatusFlapping {
+		return false, nil
+	}
+	server.Status = status
+	return true, nil
+}
+
+func (r *statusRepo) ResolveFlapping(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) (bool, error) {
+	server := r.servers[serverID]
+	if server == nil || server.Status != entity.ServerStatusFlapping {
+		return false, nil
+	}
+	server.Status = status
+	r.resolved[serverID] = status
+	return true, nil
+}
+
+func (r *statusRepo) MarkUnreachable(ctx context.Context, serverID string, rootCause string, timestamp time.Time) (bool, error) {
+	server := r.servers[serverID]
+	if server == nil || !server.Status.CanTransitionTo(entity.ServerStatusUnreachable) || server.Status == entity.ServerStatusMaintenance || server.Status == entity.ServerStatusFlapping {
+		return false, nil
+	}
+	server.Status = entity.ServerStatusUnreachable
+	if r.rootCauses == nil {
+		r.rootCauses = make(map[string]string)
+	}
+	r.rootCauses[serverID] = rootCause
+	return true, nil
+}
+
+type firingAlerts struct {
+	AlertRuleUseCase
+	firing map[string]bool
+}
+
+func (a firingAlerts) HasFiring(ctx context.Context, serverID string) (bool, error) {
+	return a.firing[serverID], nil
+}
+
+// dependencyGraph serves the parents and children of the servers of a
+// statusRepo, with their current status
+type dependencyGraph struct {
+	repository.ServerDependencyRepository
+	repo    *statusRepo
+	parents map[string][]string
+}
+
+func (g dependencyGraph) ListParents(ctx context.Context, serverID string) ([]*entity.Server, error) {
+	parents := make([]*entity.Server, 0)
+	for _, parentID := range g.parents[serverID] {
+		parent, _ := g.repo.GetByServerID(ctx, parentID)
+		parents = append(parents, parent)
+	}
+	return parents, nil
+}
+
+func (g dependencyGraph) ListChildren(ctx context.Context, serverID string) ([]*entity.Server, error) {
+	children := make([]*entity.Server, 0)
+	for _, childID := range sortedKeys(g.parents) {
+		for _, parentID := range g.parents[childID] {
+			if parentID == serverID {
+				child, _ := g.repo.GetByServerID(ctx, childID)
+				children = append(children, child)
+			}
+		}
+	}
+	return children, nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newStatusUseCase(repo *statusRepo, redisCache *memCache, firing map[string]bool) *serverUseCase {
+	logger := zap.NewNop()
+	policy := configs.StatusPolicy{FlapThreshold: 6, FlapWindow: 10 * time.Minute}
+	return NewServerUseCase(repo, nil, nil, benchDependencyRepo{}, nil, nil, firingAlerts{firing: firing}, nil, nil, nil,
+		cache.NewInMemoryCache(logger), redisCache, policy, logger).(*serverUseCase)
+}
@@ -3,12 +3,15 @@ package usecases
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gammazero/workerpool"
 	"github.com/th1enq/server_management_system/internal/domain/entity"
 	"github.com/th1enq/server_management_system/internal/domain/report"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
 	"github.com/th1enq/server_management_system/internal/dto"
 	"github.com/th1enq/server_management_system/internal/infrastructure/search"
 	"github.com/xuri/excelize/v2"
@@ -20,15 +23,20 @@ const (
 )
 
 type HealthCheckUseCase interface {
-	InsertUptime(ctx context.Context) error
+	// InsertUptime indexes a status change in the server_uptime index, under
+	// an ID derived from the change so that indexing it again is a no-op
+	InsertUptime(ctx context.Context, msg dto.ServerStatusMessage) error
 	CalculateAverageUptime(ctx context.Context, startTime, endTime time.Time) (*report.DailyReport, error)
 	ExportReportXLSX(ctx context.Context, report *report.DailyReport) (string, error)
 }
 
 type healthCheckUseCase struct {
-	esClient      search.IESClient
-	serverUseCase ServerUseCase
-	logger        *zap.Logger
+	// esClient is nil when Elasticsearch is not configured
+	esClient                 search.IESClient
+	statusEventRepo          repository.ServerStatusEventRepository
+	serverUseCase            ServerUseCase
+	maintenanceWindowUseCase MaintenanceWindowUseCase
+	logger                   *zap.Logger
 }
 
 func (h *healthCheckUseCase) CalculateAverageUptime(ctx context.Context, startTime time.Time, endTime time.Time) (*report.DailyReport, error) {
@@ -37,11 +45,22 @@ func (h *healthCheckUseCase) CalculateAverageUptime(ctx context.Context, startTi
 		h.logger.Error("Failed to get server IDs", zap.Error(err))
 		return nil, fmt.Errorf("failed to get server IDs: %w", err)
 	}
+	occurrences, err := h.maintenanceWindowUseCase.ListOccurrences(ctx, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+	serverOccurrences, err := h.maintenanceWindowUseCase.ServerOccurrences(ctx, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
 
 	uptimeRateAvg := 0.0
 	totalServers := 0
 	onlineServers := 0
+	maintenanceServers := 0
+	unreachableServers := 0
 	detailUptime := make([]report.ServerUpTime, 0)
+	rootCauses := make(map[string]*report.RootCause)
 
 	workerpool := workerpool.New(15)
 	var mu sync.Mutex
@@ -49,7 +68,12 @@ func (h *healthCheckUseCase) CalculateAverageUptime(ctx context.Context, startTi
 	for _, serverID := range serverIDs {
 		id := serverID
 		workerpool.Submit(func() {
-			status, uptime, err := h.calculateServerUptime(ctx, id, startTime, endTime)
+			policy, err := h.serverUseCase.GetStatusPolicy(ctx, id)
+			if err != nil {
+				h.logger.Error("Failed to get server status policy", zap.String("server_id", id), zap.Error(err))
+				return
+			}
+			status, uptime, unreachable, err := h.calculateServerUptime(ctx, id, startTime, endTime, serverOccurrences[id], policy.OnlineThreshold)
 			if err != nil {
 				h.logger.Error("Failed to calculate server uptime", zap.String("server_id", id), zap.Error(err))
 				return
@@ -58,6 +82,26 @@ func (h *healthCheckUseCase) CalculateAverageUptime(ctx context.Context, startTi
 			mu.Lock()
 			defer mu.Unlock()
 
+			for rootCauseID, duration := range unreachable {
+				rootCause, ok := rootCauses[rootCauseID]
+				if !ok {
+					rootCause = &report.RootCause{ServerID: rootCauseID}
+					rootCauses[rootCauseID] = rootCause
+				}
+				rootCause.AffectedServers++
+				rootCause.UnreachableTime += duration
+			}
+
+			// Servers in maintenance or unreachable all window long have no
+			// uptime to average
+			switch status {
+			case entity.ServerStatusMaintenance:
+				maintenanceServers++
+				return
+			case entity.ServerStatusUnreachable:
+				unreachableServers++
+				return
+			}
 			if status == entity.ServerStatusOn {
 				onlineServers++
 			}
@@ -78,24 +122,72 @@ func (h *healthCheckUseCase) CalculateAverageUptime(ctx context.Context, startTi
 	}
 
 	return &report.DailyReport{
-		StartOfDay:   startTime,
-		EndOfDay:     endTime,
-		TotalServers: int64(totalServers),
-		OnlineCount:  int64(onlineServers),
-		OfflineCount: int64(totalServers - onlineServers),
-		AvgUptime:    avgUptime,
-		Detail:       detailUptime,
+		StartOfDay:       startTime,
+		EndOfDay:         endTime,
+		TotalServers:     int64(totalServers + maintenanceServers + unreachableServers),
+		OnlineCount:      int64(onlineServers),
+		OfflineCount:     int64(totalServers - onlineServers + unreachableServers),
+		MaintenanceCount: int64(maintenanceServers),
+		AvgUptime:        avgUptime,
+		Detail:           detailUptime,
+
+		MaintenanceWindows: toReportMaintenanceWindows(occurrences),
+		RootCauses:         toReportRootCauses(rootCauses),
 	}, nil
 }
 
-func (h *healthCheckUseCase) calculateServerUptime(ctx context.Context, serverID string, startTime, endTime time.Time) (entity.ServerStatus, float64, error) {
-	logs, err := h.GetEsStatus(ctx, serverID, startTime, endTime)
+// toReportRootCauses sorts the root causes by the unreachable time they caused
+func toReportRootCauses(rootCauses map[string]*report.RootCause) []report.RootCause {
+	sorted := make([]report.RootCause, 0, len(rootCauses))
+	for _, rootCause := range rootCauses {
+		rootCause.UnreachableTime = rootCause.UnreachableTime.Round(time.Second)
+		sorted = append(sorted, *rootCause)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].UnreachableTime != sorted[j].UnreachableTime {
+			return sorted[i].UnreachableTime > sorted[j].UnreachableTime
+		}
+		return sorted[i].ServerID < sorted[j].ServerID
+	})
+	return sorted
+}
+
+func toReportMaintenanceWindows(occurrences []entity.MaintenanceOccurrence) []report.MaintenanceWindow {
+	windows := make([]report.MaintenanceWindow, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		scope := make([]string, 0, 3)
+		if occurrence.Window.ServerID != "" {
+			scope = append(scope, "server "+occurrence.Window.ServerID)
+		}
+		if occurrence.Window.Location != "" {
+			scope = append(scope, "location "+occurrence.Window.Location)
+		}
+		if len(occurrence.Window.Tags) > 0 {
+			scope = append(scope, "tags "+strings.Join(occurrence.Window.Tags, ", "))
+		}
+		windows = append(windows, report.MaintenanceWindow{
+			Name:  occurrence.Window.Name,
+			Scope: strings.Join(scope, "; "),
+			Start: occurrence.Start,
+			End:   occurrence.End,
+		})
+	}
+	return windows
+}
+
+// calculateServerUptime replays the status changes of the window. ON and
+// DEGRADED count as up, MAINTENANCE and the runs of the maintenance windows of
+// the server are left out of the measured time and every other status counts
+// as down. UNREACHABLE time is left out as well and returned by root cause,
+// the downtime belongs to the server that caused it. A server in maintenance
+// or unreachable for the whole window is reported with that status, otherwise
+// it is ON when its uptime reaches onlineThreshold.
+func (h *healthCheckUseCase) calculateServerUptime(ctx context.Context, serverID string, startTime, endTime time.Time, occurrences []entity.MaintenanceOccurrence, onlineThreshold float64) (entity.ServerStatus, float64, map[string]time.Duration, error) {
+	last, logs, err := h.getStatusTimeline(ctx, serverID, startTime, endTime)
 	if err != nil {
-		h.logger.Error("Failed to get ES status", zap.Error(err))
-		return entity.ServerStatusUndefined, 0, fmt.Errorf("failed to get ES status: %w", err)
+		h.logger.Error("Failed to get status history", zap.Error(err))
+		return entity.ServerStatusUndefined, 0, nil, fmt.Errorf("failed to get status history: %w", err)
 	}
-	uptime := 0
-	lastStatus, err := h.getLastStatus(ctx, serverID, startTime)
 
 	h.logger.Info("Calculating uptime for server",
 		zap.String("server_id", serverID),
@@ -104,51 +196,96 @@ func (h *healthCheckUseCase) calculateServerUptime(ctx context.Context, serverID
 		zap.Any("logs", logs),
 	)
 
-	if len(logs) == 0 {
-		if err != nil {
-			h.logger.Error("Failed to get last status", zap.Error(err))
-			return entity.ServerStatusUndefined, 0, fmt.Errorf("failed to get last status: %w", err)
-		}
-		if lastStatus == entity.ServerStatusOn {
-			uptime = int(endTime.Sub(startTime).Seconds())
-		} else {
-			uptime = 0
-		}
-	} else {
-		switch logs[0].Status {
-		case entity.ServerStatusOff:
-			if lastStatus == entity.ServerStatusOn {
-				uptime = int(logs[0].Timestamp.Sub(startTime).Seconds())
-			} else {
-				uptime = 0
-			}
-		}
-		for i := 0; i < len(logs)-1; i++ {
-			if logs[i].Status == entity.ServerStatusOn {
-				uptime += int(logs[i+1].Timestamp.Sub(logs[i].Timestamp).Seconds())
+	scheduled := mergeOccurrences(occurrences)
+	var uptime, maintenance, unreachable time.Duration
+	unreachableByRootCause := make(map[string]time.Duration)
+	current, from := last, startTime
+	account := func(until time.Time) {
+		// A window applies its MAINTENANCE status on the next tick, the
+		// time it covers is excluded whatever the recorded status
+		covered := overlap(scheduled, from, until)
+		switch {
+		case current.Status == entity.ServerStatusMaintenance:
+			maintenance += until.Sub(from)
+		case current.Status.IsUp():
+			uptime += until.Sub(from) - covered
+			maintenance += covered
+		case current.Status == entity.ServerStatusUnreachable:
+			unreachable += until.Sub(from) - covered
+			maintenance += covered
+			if current.RootCause != "" {
+				unreachableByRootCause[current.RootCause] += until.Sub(from) - covered
 			}
-		}
-		if logs[len(logs)-1].Status == entity.ServerStatusOn {
-			uptime += int(endTime.Sub(logs[len(logs)-1].Timestamp).Seconds())
+		default:
+			maintenance += covered
 		}
 	}
+	for _, log := range logs {
+		account(log.Timestamp)
+		current, from = log, log.Timestamp
+	}
+	account(endTime)
 
 	h.logger.Info("Calculated uptime for server",
 		zap.String("server_id", serverID),
-		zap.Int("uptime_seconds", uptime),
+		zap.Int("uptime_seconds", int(uptime.Seconds())),
+		zap.Int("maintenance_seconds", int(maintenance.Seconds())),
+		zap.Int("unreachable_seconds", int(unreachable.Seconds())),
 		zap.Time("start_time", startTime),
 		zap.Time("end_time", endTime),
 	)
 
-	status := entity.ServerStatusOff
+	measured := endTime.Sub(startTime) - maintenance - unreachable
+	if measured <= 0 {
+		if unreachable > 0 {
+			return entity.ServerStatusUnreachable, 0, unreachableByRootCause, nil
+		}
+		return entity.ServerStatusMaintenance, 0, unreachableByRootCause, nil
+	}
 
-	uptimeRate := float64(uptime) / float64(endTime.Sub(startTime).Seconds()) * 100
-	if (uptimeRate >= 70.0) && (uptime > 0) {
+	status := entity.ServerStatusOff
+	uptimeRate := uptime.Seconds() / measured.Seconds() * 100
+	if (uptimeRate >= onlineThreshold) && (uptime > 0) {
 		status = entity.ServerStatusOn
 	}
 
-	return status, uptimeRate, nil
+	return status, uptimeRate, unreachableByRootCause, nil
+}
 
+// mergeOccurrences returns the runs sorted by start with overlapping runs joined
+func mergeOccurrences(occurrences []entity.MaintenanceOccurrence) []entity.MaintenanceOccurrence {
+	merged := make([]entity.MaintenanceOccurrence, 0, len(occurrences))
+	sorted := append([]entity.MaintenanceOccurrence(nil), occurrences...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+	for _, occurrence := range sorted {
+		last := len(merged) - 1
+		if last >= 0 && !occurrence.Start.After(merged[last].End) {
+			if occurrence.End.After(merged[last].End) {
+				merged[last].End = occurrence.End
+			}
+			continue
+		}
+		merged = append(merged, occurrence)
+	}
+	return merged
+}
+
+// overlap is the time of [from, until) covered by merged runs
+func overlap(merged []entity.MaintenanceOccurrence, from, until time.Time) time.Duration {
+	var covered time.Duration
+	for _, occurrence := range merged {
+		start, end := occurrence.Start, occurrence.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(until) {
+			end = until
+		}
+		if end.After(start) {
+			covered += end.Sub(start)
+		}
+	}
+	return covered
 }
 
 func (h *healthCheckUseCase) ExportReportXLSX(ctx context.Context, report *report.DailyReport) (string, error) {
@@ -181,32 +318,76 @@ func (h *healthCheckUseCase) ExportReportXLSX(ctx context.Context, report *repor
 	return fileName, nil
 }
 
-func (h *healthCheckUseCase) InsertUptime(ctx context.Context) error {
-	// if msg.OldStatus == msg.NewStatus {
-	// 	h.logger.Info("No status change detected, skipping indexing",
-	// 		zap.String("server_id", msg.ServerID),
-	// 		zap.String("old_status", string(msg.OldStatus)),
-	// 		zap.String("new_status", string(msg.NewStatus)),
-	// 	)
-	// 	return nil
-	// }
-	// newDocument := map[string]interface{}{
-	// 	"server_id": msg.ServerID,
-	// 	"status":    msg.NewStatus,
-	// 	"timestamp": msg.Timestamp.Format(time.RFC3339),
-	// }
-
-	// if err := h.esClient.Insert(ctx,
-	// 	indexName,
-	// 	fmt.Sprintf("%s-%s", msg.ServerID, msg.Timestamp.Format(time.RFC3339)),
-	// 	newDocument,
-	// ); err != nil {
-	// 	return err
-	// }
+func (h *healthCheckUseCase) InsertUptime(ctx context.Context, msg dto.ServerStatusMessage) error {
+	if h.esClient == nil {
+		h.logger.Debug("Elasticsearch not configured, skipping indexing", zap.String("server_id", msg.ServerID))
+		return nil
+	}
+
+	newDocument := map[string]interface{}{
+		"server_id": msg.ServerID,
+		"status":    msg.Status,
+		"timestamp": msg.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+	if msg.RootCause != "" {
+		newDocument["root_cause"] = msg.RootCause
+	}
 
+	// A server changes status at most once at a given instant
+	documentID := fmt.Sprintf("%s-%d", msg.ServerID, msg.Timestamp.UnixNano())
+	if err := h.esClient.Insert(ctx, indexName, documentID, newDocument); err != nil {
+		return fmt.Errorf("failed to index status change: %w", err)
+	}
 	return nil
 }
 
+// getStatusTimeline returns the status of the server at startTime and its
+// changes up to endTime. They are read from Elasticsearch when it is
+// configured and answers, from the status history in Postgres otherwise.
+func (h *healthCheckUseCase) getStatusTimeline(ctx context.Context, serverID string, startTime, endTime time.Time) (dto.EsStatus, []dto.EsStatus, error) {
+	if h.esClient != nil {
+		logs, err := h.GetEsStatus(ctx, serverID, startTime, endTime)
+		if err == nil {
+			var last dto.EsStatus
+			last, err = h.getLastStatus(ctx, serverID, startTime)
+			if err == nil {
+				return last, logs, nil
+			}
+		}
+		h.logger.Warn("Elasticsearch unavailable, reading status history from Postgres",
+			zap.String("server_id", serverID),
+			zap.Error(err),
+		)
+	}
+
+	lastEvent, err := h.statusEventRepo.LastBefore(ctx, serverID, startTime)
+	if err != nil {
+		return dto.EsStatus{}, nil, err
+	}
+	last := dto.EsStatus{Status: entity.ServerStatusOff}
+	if lastEvent != nil {
+		last = toEsStatus(lastEvent)
+	}
+
+	events, err := h.statusEventRepo.ListBetween(ctx, serverID, startTime, endTime)
+	if err != nil {
+		return dto.EsStatus{}, nil, err
+	}
+	logs := make([]dto.EsStatus, 0, len(events))
+	for _, event := range events {
+		logs = append(logs, toEsStatus(event))
+	}
+	return last, logs, nil
+}
+
+func toEsStatus(event *entity.ServerStatusEvent) dto.EsStatus {
+	return dto.EsStatus{
+		Status:    event.Status,
+		Timestamp: event.ChangedAt,
+		RootCause: event.RootCause,
+	}
+}
+
 func (h *healthCheckUseCase) GetEsStatus(ctx context.Context, serverID string, startTime, endTime time.Time) ([]dto.EsStatus, error) {
 	query := map[string]interface{}{
 		"size": 10000,
@@ -234,6 +415,7 @@ func (h *healthCheckUseCase) GetEsStatus(ctx context.Context, serverID string, s
 				Source struct {
 					Status    entity.ServerStatus `json:"status"`
 					Timestamp time.Time           `json:"timestamp"`
+					RootCause string              `json:"root_cause"`
 				} `json:"_source"`
 			} `json:"hits"`
 		} `json:"hits"`
@@ -248,13 +430,14 @@ func (h *healthCheckUseCase) GetEsStatus(ctx context.Context, serverID string, s
 		results = append(results, dto.EsStatus{
 			Status:    hit.Source.Status,
 			Timestamp: hit.Source.Timestamp,
+			RootCause: hit.Source.RootCause,
 		})
 	}
 
 	return results, nil
 }
 
-func (h *healthCheckUseCase) getLastStatus(ctx context.Context, serverID string, startTime time.Time) (entity.ServerStatus, error) {
+func (h *healthCheckUseCase) getLastStatus(ctx context.Context, serverID string, startTime time.Time) (dto.EsStatus, error) {
 	query := map[string]interface{}{
 		"size": 1,
 		"query": map[string]interface{}{
@@ -278,27 +461,31 @@ func (h *healthCheckUseCase) getLastStatus(ctx context.Context, serverID string,
 		Hits struct {
 			Hits []struct {
 				Source struct {
-					Status entity.ServerStatus `json:"status"`
+					Status    entity.ServerStatus `json:"status"`
+					RootCause string              `json:"root_cause"`
 				} `json:"_source"`
 			} `json:"hits"`
 		} `json:"hits"`
 	}
 
 	if err := h.esClient.Exec(ctx, indexName, query, &parsedResult); err != nil {
-		return entity.ServerStatusOff, nil
+		return dto.EsStatus{Status: entity.ServerStatusOff}, err
 	}
 
 	if len(parsedResult.Hits.Hits) == 0 {
 		h.logger.Info("No previous status found for server", zap.String("server_id", serverID))
-		return entity.ServerStatusOff, nil
+		return dto.EsStatus{Status: entity.ServerStatusOff}, nil
 	}
-	return parsedResult.Hits.Hits[0].Source.Status, nil
+	source := parsedResult.Hits.Hits[0].Source
+	return dto.EsStatus{Status: source.Status, RootCause: source.RootCause}, nil
 }
 
-func NewHealthCheckUseCase(esClient search.IESClient, serverUseCase ServerUseCase, logger *zap.Logger) HealthCheckUseCase {
+func NewHealthCheckUseCase(esClient search.IESClient, statusEventRepo repository.ServerStatusEventRepository, serverUseCase ServerUseCase, maintenanceWindowUseCase MaintenanceWindowUseCase, logger *zap.Logger) HealthCheckUseCase {
 	return &healthCheckUseCase{
-		esClient:      esClient,
-		serverUseCase: serverUseCase,
-		logger:        logger,
+		esClient:                 esClient,
+		statusEventRepo:          statusEventRepo,
+		serverUseCase:            serverUseCase,
+		maintenanceWindowUseCase: maintenanceWindowUseCase,
+		logger:                   logger,
 	}
 }
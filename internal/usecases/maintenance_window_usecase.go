@@ -0,0 +1,377 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"go.uber.org/zap"
+)
+
+// Bounds the runs of a recurring window expanded for a report range
+const maxMaintenanceOccurrences = 10000
+
+type MaintenanceWindowUseCase interface {
+	CreateWindow(ctx context.Context, req dto.CreateMaintenanceWindowRequest, createdBy uint) (*entity.MaintenanceWindow, error)
+	ListWindows(ctx context.Context) ([]*entity.MaintenanceWindow, error)
+	GetWindow(ctx context.Context, id uint) (*entity.MaintenanceWindow, error)
+	UpdateWindow(ctx context.Context, id uint, req dto.UpdateMaintenanceWindowRequest) (*entity.MaintenanceWindow, error)
+	DeleteWindow(ctx context.Context, id uint) error
+	// IsActive reports whether an enabled window is running at the given time
+	IsActive(window *entity.MaintenanceWindow, at time.Time) bool
+	// Apply puts the servers in the scope of an active window in MAINTENANCE
+	// and turns OFF the ones no active window holds anymore
+	Apply(ctx context.Context) error
+	// ListOccurrences returns the runs of the enabled windows overlapping
+	// [from, to), ServerOccurrences the same runs grouped by server in scope
+	ListOccurrences(ctx context.Context, from, to time.Time) ([]entity.MaintenanceOccurrence, error)
+	ServerOccurrences(ctx context.Context, from, to time.Time) (map[string][]entity.MaintenanceOccurrence, error)
+}
+
+type maintenanceWindowUseCase struct {
+	windowRepo    repository.MaintenanceWindowRepository
+	serverRepo    repository.ServerRepository
+	serverUseCase ServerUseCase
+	logger        *zap.Logger
+}
+
+func NewMaintenanceWindowUseCase(windowRepo repository.MaintenanceWindowRepository, serverRepo repository.ServerRepository, serverUseCase ServerUseCase, logger *zap.Logger) MaintenanceWindowUseCase {
+	return &maintenanceWindowUseCase{
+		windowRepo:    windowRepo,
+		serverRepo:    serverRepo,
+		serverUseCase: serverUseCase,
+		logger:        logger,
+	}
+}
+
+func (m *maintenanceWindowUseCase) CreateWindow(ctx context.Context, req dto.CreateMaintenanceWindowRequest, createdBy uint) (*entity.MaintenanceWindow, error) {
+	window := &entity.MaintenanceWindow{
+		Name:      req.Name,
+		ServerID:  req.ServerID,
+		Location:  req.Location,
+		Tags:      req.Tags,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Schedule:  req.Schedule,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		CreatedBy: createdBy,
+	}
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid duration: %v", domainerrors.ErrInvalidInput, err)
+		}
+		window.Duration = duration
+	}
+	if err := validateMaintenanceWindow(window); err != nil {
+		return nil, err
+	}
+
+	if err := m.windowRepo.Create(ctx, window); err != nil {
+		m.logger.Error("Failed to create maintenance window",
+			zap.String("name", window.Name),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to create maintenance window: %w", err)
+	}
+
+	m.logger.Info("Maintenance window created",
+		zap.Uint("id", window.ID),
+		zap.String("name", window.Name),
+		zap.Uint("created_by", createdBy),
+	)
+	return window, nil
+}
+
+func (m *maintenanceWindowUseCase) ListWindows(ctx context.Context) ([]*entity.MaintenanceWindow, error) {
+	windows, err := m.windowRepo.List(ctx)
+	if err != nil {
+		m.logger.Error("Failed to list maintenance windows", zap.Error(err))
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+	return windows, nil
+}
+
+func (m *maintenanceWindowUseCase) GetWindow(ctx context.Context, id uint) (*entity.MaintenanceWindow, error) {
+	window, err := m.windowRepo.GetByID(ctx, id)
+	if err != nil {
+		m.logger.Error("Failed to get maintenance window by ID",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return nil, domainerrors.ErrMaintenanceWindowNotFound
+	}
+	return window, nil
+}
+
+func (m *maintenanceWindowUseCase) UpdateWindow(ctx context.Context, id uint, req dto.UpdateMaintenanceWindowRequest) (*entity.MaintenanceWindow, error) {
+	window, err := m.GetWindow(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		window.Name = req.Name
+	}
+	if req.ServerID != nil {
+		window.ServerID = *req.ServerID
+	}
+	if req.Location != nil {
+		window.Location = *req.Location
+	}
+	if req.Tags != nil {
+		window.Tags = req.Tags
+	}
+	if req.StartsAt != nil {
+		window.StartsAt = req.StartsAt
+	}
+	if req.EndsAt != nil {
+		window.EndsAt = req.EndsAt
+	}
+	if req.Schedule != nil {
+		window.Schedule = *req.Schedule
+		if window.Schedule != "" {
+			// Switching to a recurring window drops the one-off range
+			window.StartsAt, window.EndsAt = nil, nil
+		}
+	}
+	if req.Duration != nil {
+		duration, err := time.ParseDuration(*req.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid duration: %v", domainerrors.ErrInvalidInput, err)
+		}
+		window.Duration = duration
+	}
+	if !window.IsRecurring() {
+		window.Duration = 0
+	}
+	if req.Enabled != nil {
+		window.Enabled = *req.Enabled
+	}
+	if err := validateMaintenanceWindow(window); err != nil {
+		return nil, err
+	}
+
+	if err := m.windowRepo.Update(ctx, window); err != nil {
+		m.logger.Error("Failed to update maintenance window", zap.Uint("id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to update maintenance window: %w", err)
+	}
+
+	m.logger.Info("Maintenance window updated", zap.Uint("id", window.ID), zap.String("name", window.Name))
+	return window, nil
+}
+
+func (m *maintenanceWindowUseCase) DeleteWindow(ctx context.Context, id uint) error {
+	if _, err := m.GetWindow(ctx, id); err != nil {
+		return err
+	}
+
+	if err := m.windowRepo.Delete(ctx, id); err != nil {
+		m.logger.Error("Failed to delete maintenance window", zap.Uint("id", id), zap.Error(err))
+		return fmt.Errorf("failed to delete maintenance window: %w", err)
+	}
+
+	m.logger.Info("Maintenance window deleted", zap.Uint("id", id))
+	return nil
+}
+
+func (m *maintenanceWindowUseCase) IsActive(window *entity.MaintenanceWindow, at time.Time) bool {
+	if !window.Enabled {
+		return false
+	}
+	if !window.IsRecurring() {
+		return window.StartsAt != nil && window.EndsAt != nil &&
+			!at.Before(*window.StartsAt) && at.Before(*window.EndsAt)
+	}
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return false
+	}
+	// The last run started in (at - duration, at] is still running
+	return !schedule.Next(at.Add(-window.Duration)).After(at)
+}
+
+func (m *maintenanceWindowUseCase) Apply(ctx context.Context) error {
+	windows, err := m.windowRepo.ListEnabled(ctx)
+	if err != nil {
+		m.logger.Error("Failed to list maintenance windows", zap.Error(err))
+		return fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+
+	now := time.Now()
+	active := make([]*entity.MaintenanceWindow, 0)
+	for _, window := range windows {
+		if m.IsActive(window, now) {
+			active = append(active, window)
+		}
+	}
+
+	// Server ID to the window holding it
+	desired := make(map[string]uint)
+	if len(active) > 0 {
+		servers, err := m.serverRepo.GetAll(ctx)
+		if err != nil {
+			m.logger.Error("Failed to get servers", zap.Error(err))
+			return fmt.Errorf("failed to get servers: %w", err)
+		}
+		for _, server := range servers {
+			if server.Status == entity.ServerStatusPendingApproval {
+				continue
+			}
+			for _, window := range active {
+				if window.Matches(server) {
+					desired[server.ServerID] = window.ID
+					break
+				}
+			}
+		}
+	}
+
+	held, err := m.serverRepo.ListInMaintenanceWindow(ctx)
+	if err != nil {
+		m.logger.Error("Failed to list servers in a maintenance window", zap.Error(err))
+		return fmt.Errorf("failed to list servers in a maintenance window: %w", err)
+	}
+
+	started, ended := 0, 0
+	isHeld := make(map[string]bool, len(held))
+	for _, server := range held {
+		isHeld[server.ServerID] = true
+		if _, ok := desired[server.ServerID]; ok {
+			continue
+		}
+		changed, err := m.serverUseCase.EndMaintenance(ctx, server.ServerID)
+		if err != nil {
+			continue
+		}
+		if changed {
+			ended++
+		}
+	}
+	for serverID, windowID := range desired {
+		if isHeld[serverID] {
+			continue
+		}
+		// Servers put in maintenance by an admin are not taken over
+		changed, err := m.serverUseCase.StartMaintenance(ctx, serverID, windowID)
+		if err != nil {
+			continue
+		}
+		if changed {
+			started++
+		}
+	}
+
+	if started > 0 || ended > 0 {
+		m.logger.Info("Maintenance windows applied",
+			zap.Int("started", started),
+			zap.Int("ended", ended),
+		)
+	}
+	return nil
+}
+
+func (m *maintenanceWindowUseCase) ListOccurrences(ctx context.Context, from, to time.Time) ([]entity.MaintenanceOccurrence, error) {
+	windows, err := m.windowRepo.ListEnabled(ctx)
+	if err != nil {
+		m.logger.Error("Failed to list maintenance windows", zap.Error(err))
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+
+	occurrences := make([]entity.MaintenanceOccurrence, 0)
+	for _, window := range windows {
+		occurrences = append(occurrences, windowOccurrences(window, from, to)...)
+	}
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+	return occurrences, nil
+}
+
+func (m *maintenanceWindowUseCase) ServerOccurrences(ctx context.Context, from, to time.Time) (map[string][]entity.MaintenanceOccurrence, error) {
+	occurrences, err := m.ListOccurrences(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byServer := make(map[string][]entity.MaintenanceOccurrence)
+	if len(occurrences) == 0 {
+		return byServer, nil
+	}
+
+	servers, err := m.serverRepo.GetAll(ctx)
+	if err != nil {
+		m.logger.Error("Failed to get servers", zap.Error(err))
+		return nil, fmt.Errorf("failed to get servers: %w", err)
+	}
+	for _, server := range servers {
+		for _, occurrence := range occurrences {
+			if occurrence.Window.Matches(server) {
+				byServer[server.ServerID] = append(byServer[server.ServerID], occurrence)
+			}
+		}
+	}
+	return byServer, nil
+}
+
+// windowOccurrences expands the runs of a window overlapping [from, to)
+func windowOccurrences(window *entity.MaintenanceWindow, from, to time.Time) []entity.MaintenanceOccurrence {
+	occurrences := make([]entity.MaintenanceOccurrence, 0)
+	if !window.IsRecurring() {
+		if window.StartsAt != nil && window.EndsAt != nil &&
+			window.StartsAt.Before(to) && window.EndsAt.After(from) {
+			occurrences = append(occurrences, entity.MaintenanceOccurrence{
+				Window: window,
+				Start:  *window.StartsAt,
+				End:    *window.EndsAt,
+			})
+		}
+		return occurrences
+	}
+
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return occurrences
+	}
+	for start := schedule.Next(from.Add(-window.Duration)); start.Before(to); start = schedule.Next(start) {
+		if len(occurrences) == maxMaintenanceOccurrences || start.IsZero() {
+			break
+		}
+		occurrences = append(occurrences, entity.MaintenanceOccurrence{
+			Window: window,
+			Start:  start,
+			End:    start.Add(window.Duration),
+		})
+	}
+	return occurrences
+}
+
+func validateMaintenanceWindow(window *entity.MaintenanceWindow) error {
+	if !window.HasScope() {
+		return fmt.Errorf("%w: a server_id, location or tags is required", domainerrors.ErrInvalidInput)
+	}
+	if window.IsRecurring() {
+		if window.StartsAt != nil || window.EndsAt != nil {
+			return fmt.Errorf("%w: a recurring window cannot have starts_at or ends_at", domainerrors.ErrInvalidInput)
+		}
+		if _, err := cron.ParseStandard(window.Schedule); err != nil {
+			return fmt.Errorf("%w: invalid schedule: %v", domainerrors.ErrInvalidInput, err)
+		}
+		if window.Duration <= 0 {
+			return fmt.Errorf("%w: a recurring window requires a positive duration", domainerrors.ErrInvalidInput)
+		}
+		return nil
+	}
+	if window.StartsAt == nil || window.EndsAt == nil {
+		return fmt.Errorf("%w: starts_at and ends_at are required without a schedule", domainerrors.ErrInvalidInput)
+	}
+	if !window.StartsAt.Before(*window.EndsAt) {
+		return fmt.Errorf("%w: starts_at must be before ends_at", domainerrors.ErrInvalidInput)
+	}
+	return nil
+}
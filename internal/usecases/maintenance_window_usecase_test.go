@@ -0,0 +1,156 @@
+package usecases
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"go.uber.org/zap"
+)
+
+func date(day, hour, minute int) time.Time {
+	return time.Date(2025, time.March, day, hour, minute, 0, 0, time.UTC)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestMaintenanceWindowIsActive(t *testing.T) {
+	oneOff := &entity.MaintenanceWindow{
+		StartsAt: timePtr(date(10, 2, 0)),
+		EndsAt:   timePtr(date(10, 4, 0)),
+		Enabled:  true,
+	}
+	// Every day from 02:00 to 03:30
+	nightly := &entity.MaintenanceWindow{Schedule: "0 2 * * *", Duration: 90 * time.Minute, Enabled: true}
+	// Sundays (2025-03-09 and 16) from 23:00 to 01:00, across midnight
+	weekly := &entity.MaintenanceWindow{Schedule: "0 23 * * 0", Duration: 2 * time.Hour, Enabled: true}
+
+	tests := []struct {
+		name   string
+		window *entity.MaintenanceWindow
+		at     time.Time
+		want   bool
+	}{
+		{"one-off before", oneOff, date(10, 1, 59), false},
+		{"one-off at start", oneOff, date(10, 2, 0), true},
+		{"one-off inside", oneOff, date(10, 3, 0), true},
+		{"one-off at end", oneOff, date(10, 4, 0), false},
+		{"one-off disabled", &entity.MaintenanceWindow{StartsAt: oneOff.StartsAt, EndsAt: oneOff.EndsAt}, date(10, 3, 0), false},
+		{"one-off without end", &entity.MaintenanceWindow{StartsAt: oneOff.StartsAt, Enabled: true}, date(10, 3, 0), false},
+		{"recurring before a run", nightly, date(10, 1, 59), false},
+		{"recurring at start", nightly, date(10, 2, 0), true},
+		{"recurring inside", nightly, date(10, 3, 29), true},
+		{"recurring at end", nightly, date(10, 3, 30), false},
+		{"recurring between runs", nightly, date(10, 12, 0), false},
+		{"recurring across midnight, before", weekly, date(9, 22, 59), false},
+		{"recurring across midnight, same day", weekly, date(9, 23, 30), true},
+		{"recurring across midnight, next day", weekly, date(10, 0, 59), true},
+		{"recurring across midnight, after", weekly, date(10, 1, 0), false},
+		{"recurring on another day", weekly, date(11, 23, 30), false},
+		{"recurring disabled", &entity.MaintenanceWindow{Schedule: "0 2 * * *", Duration: time.Hour}, date(10, 2, 30), false},
+		{"invalid schedule", &entity.MaintenanceWindow{Schedule: "not a schedule", Duration: time.Hour, Enabled: true}, date(10, 2, 30), false},
+	}
+
+	m := NewMaintenanceWindowUseCase(nil, nil, nil, zap.NewNop())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, m.IsActive(tt.window, tt.at))
+		})
+	}
+}
+
+func TestWindowOccurrences(t *testing.T) {
+	oneOff := &entity.MaintenanceWindow{StartsAt: timePtr(date(10, 2, 0)), EndsAt: timePtr(date(10, 4, 0))}
+	nightly := &entity.MaintenanceWindow{Schedule: "0 2 * * *", Duration: 90 * time.Minute}
+	everyMinute := &entity.MaintenanceWindow{Schedule: "* * * * *", Duration: time.Minute}
+
+	type span struct{ start, end time.Time }
+	tests := []struct {
+		name   string
+		window *entity.MaintenanceWindow
+		from   time.Time
+		to     time.Time
+		want   []span
+	}{
+		{
+			name:   "one-off inside the range",
+			window: oneOff,
+			from:   date(10, 0, 0),
+			to:     date(11, 0, 0),
+			want:   []span{{date(10, 2, 0), date(10, 4, 0)}},
+		},
+		{
+			// Clipping is up to the caller
+			name:   "one-off overlapping the start",
+			window: oneOff,
+			from:   date(10, 3, 0),
+			to:     date(11, 0, 0),
+			want:   []span{{date(10, 2, 0), date(10, 4, 0)}},
+		},
+		{
+			name:   "one-off ending at the start",
+			window: oneOff,
+			from:   date(10, 4, 0),
+			to:     date(11, 0, 0),
+		},
+		{
+			name:   "one-off starting at the end",
+			window: oneOff,
+			from:   date(9, 0, 0),
+			to:     date(10, 2, 0),
+		},
+		{
+			name:   "recurring over three days",
+			window: nightly,
+			from:   date(10, 0, 0),
+			to:     date(13, 0, 0),
+			want: []span{
+				{date(10, 2, 0), date(10, 3, 30)},
+				{date(11, 2, 0), date(11, 3, 30)},
+				{date(12, 2, 0), date(12, 3, 30)},
+			},
+		},
+		{
+			name:   "recurring run in progress at the start",
+			window: nightly,
+			from:   date(10, 3, 0),
+			to:     date(11, 0, 0),
+			want:   []span{{date(10, 2, 0), date(10, 3, 30)}},
+		},
+		{
+			name:   "recurring run starting at the end",
+			window: nightly,
+			from:   date(10, 3, 30),
+			to:     date(11, 2, 0),
+		},
+		{
+			name:   "invalid schedule",
+			window: &entity.MaintenanceWindow{Schedule: "61 * * * *", Duration: time.Hour},
+			from:   date(10, 0, 0),
+			to:     date(11, 0, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := windowOccurrences(tt.window, tt.from, tt.to)
+			spans := make([]span, 0, len(got))
+			for _, occurrence := range got {
+				assert.Same(t, tt.window, occurrence.Window)
+				spans = append(spans, span{occurrence.Start, occurrence.End})
+			}
+			if tt.want == nil {
+				tt.want = []span{}
+			}
+			assert.Equal(t, tt.want, spans)
+		})
+	}
+
+	t.Run("bounded", func(t *testing.T) {
+		got := windowOccurrences(everyMinute, date(1, 0, 0), date(31, 0, 0))
+		assert.Len(t, got, maxMaintenanceOccurrences)
+	})
+}
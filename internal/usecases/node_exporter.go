@@ -0,0 +1,320 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+const (
+	// Counters are kept between scrapes to turn them into rates
+	nodeCountersTTL = time.Hour
+	maxNodeMounts   = 64
+)
+
+// Filesystems that are not reported as mounts
+var pseudoFilesystems = map[string]bool{
+	"tmpfs": true, "devtmpfs": true, "overlay": true, "squashfs": true,
+	"proc": true, "sysfs": true, "cgroup": true, "cgroup2": true, "nsfs": true,
+	"autofs": true, "fuse.lxcfs": true, "ramfs": true,
+}
+
+// nodeScrape collects the node_exporter values of one server at one timestamp
+type nodeScrape struct {
+	timestamp  int64
+	cpu        map[string]*cpuCounters
+	memTotal   *float64
+	memAvail   *float64
+	load       [3]*float64
+	filesystem map[string]*filesystemUsage
+	rx, tx     *float64
+	pids       *float64
+	bootTime   *float64
+	nodeTime   *float64
+}
+
+type cpuCounters struct {
+	Idle  float64 `json:"idle"`
+	Total float64 `json:"total"`
+}
+
+type filesystemUsage struct {
+	size, free, avail float64
+}
+
+// nodeCounters are the counters of the previous scrape of a server
+type nodeCounters struct {
+	Timestamp int64                   `json:"timestamp"`
+	CPU       map[string]*cpuCounters `json:"cpu"`
+	Rx        *float64                `json:"rx,omitempty"`
+	Tx        *float64                `json:"tx,omitempty"`
+}
+
+// nodeScrapes are the scrapes of one server by timestamp in milliseconds
+type nodeScrapes map[int64]*nodeScrape
+
+// usable returns the scrapes that carry enough to become a sample
+func (n nodeScrapes) usable() []*nodeScrape {
+	scrapes := make([]*nodeScrape, 0, len(n))
+	for _, scrape := range n {
+		if len(scrape.cpu) > 0 || scrape.memTotal != nil {
+			scrapes = append(scrapes, scrape)
+		}
+	}
+	return scrapes
+}
+
+// add records the samples of a node_exporter series, other series are ignored
+func (n nodeScrapes) add(series dto.PrometheusTimeSeries) {
+	if !strings.HasPrefix(series.Labels["__name__"], "node_") {
+		return
+	}
+	for _, sample := range series.Samples {
+		if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+			continue
+		}
+		scrape := n[sample.Timestamp]
+		if scrape == nil {
+			scrape = &nodeScrape{
+				timestamp:  sample.Timestamp,
+				cpu:        make(map[string]*cpuCounters),
+				filesystem: make(map[string]*filesystemUsage),
+			}
+			n[sample.Timestamp] = scrape
+		}
+		scrape.add(series.Labels, sample.Value)
+	}
+}
+
+// nodeExporterMapper turns node_exporter series into metrics samples
+type nodeExporterMapper struct {
+	redisCache cache.CacheClient
+	logger     *zap.Logger
+}
+
+func newNodeExporterMapper(redisCache cache.CacheClient, logger *zap.Logger) *nodeExporterMapper {
+	return &nodeExporterMapper{
+		redisCache: redisCache,
+		logger:     logger,
+	}
+}
+
+func (s *nodeScrape) add(labels map[string]string, value float64) {
+	v := value
+	switch labels["__name__"] {
+	case "node_cpu_seconds_total":
+		cpu := s.cpu[labels["cpu"]]
+		if cpu == nil {
+			cpu = &cpuCounters{}
+			s.cpu[labels["cpu"]] = cpu
+		}
+		// guest time is already counted in user
+		switch labels["mode"] {
+		case "idle", "iowait":
+			cpu.Idle += value
+			cpu.Total += value
+		case "guest", "guest_nice":
+		default:
+			cpu.Total += value
+		}
+	case "node_memory_MemTotal_bytes":
+		s.memTotal = &v
+	case "node_memory_MemAvailable_bytes":
+		s.memAvail = &v
+	case "node_load1":
+		s.load[0] = &v
+	case "node_load5":
+		s.load[1] = &v
+	case "node_load15":
+		s.load[2] = &v
+	case "node_filesystem_size_bytes", "node_filesystem_free_bytes", "node_filesystem_avail_bytes":
+		if pseudoFilesystems[labels["fstype"]] {
+			return
+		}
+		fs := s.filesystem[labels["mountpoint"]]
+		if fs == nil {
+			fs = &filesystemUsage{}
+			s.filesystem[labels["mountpoint"]] = fs
+		}
+		switch labels["__name__"] {
+		case "node_filesystem_size_bytes":
+			fs.size = value
+		case "node_filesystem_free_bytes":
+			fs.free = value
+		default:
+			fs.avail = value
+		}
+	case "node_network_receive_bytes_total":
+		if labels["device"] != "lo" {
+			s.rx = addValue(s.rx, value)
+		}
+	case "node_network_transmit_bytes_total":
+		if labels["device"] != "lo" {
+			s.tx = addValue(s.tx, value)
+		}
+	case "node_processes_pids":
+		s.pids = &v
+	case "node_boot_time_seconds":
+		s.bootTime = &v
+	case "node_time_seconds":
+		s.nodeTime = &v
+	}
+}
+
+// toMetrics turns the scrapes of a server into samples, oldest first. CPU and
+// network counters become rates against the previous scrape; without one the
+// CPU usage is measured since boot, like the agent does on its first sample.
+func (m *nodeExporterMapper) toMetrics(ctx context.Context, serverID string, byTimestamp nodeScrapes) []dto.MetricsRequest {
+	scrapes := byTimestamp.usable()
+	if len(scrapes) == 0 {
+		return nil
+	}
+	sort.Slice(scrapes, func(i, j int) bool { return scrapes[i].timestamp < scrapes[j].timestamp })
+
+	cacheKey := fmt.Sprintf("node_exporter:counters:%s", serverID)
+	var prev nodeCounters
+	if err := m.redisCache.Get(ctx, cacheKey, &prev); err != nil {
+		prev = nodeCounters{}
+	}
+
+	samples := make([]dto.MetricsRequest, 0, len(scrapes))
+	for _, scrape := range scrapes {
+		// Late scrapes cannot be turned into rates against a newer one
+		previous := prev
+		if scrape.timestamp <= prev.Timestamp {
+			previous = nodeCounters{}
+		}
+		samples = append(samples, scrape.toMetrics(serverID, previous))
+		if scrape.timestamp > prev.Timestamp {
+			prev = nodeCounters{Timestamp: scrape.timestamp, CPU: scrape.cpu, Rx: scrape.rx, Tx: scrape.tx}
+		}
+	}
+
+	if err := m.redisCache.Set(ctx, cacheKey, prev, nodeCountersTTL); err != nil {
+		m.logger.Warn("Failed to save node_exporter counters",
+			zap.String("server_id", serverID),
+			zap.Error(err),
+		)
+	}
+	return samples
+}
+
+func (s *nodeScrape) toMetrics(serverID string, prev nodeCounters) dto.MetricsRequest {
+	metrics := dto.MetricsRequest{
+		SchemaVersion: dto.MetricsSchemaV2,
+		ServerID:      serverID,
+		Timestamp:     time.UnixMilli(s.timestamp).UTC(),
+	}
+
+	if len(s.cpu) > 0 {
+		cpus := make([]string, 0, len(s.cpu))
+		for cpu := range s.cpu {
+			cpus = append(cpus, cpu)
+		}
+		sort.Slice(cpus, func(i, j int) bool { return naturalLess(cpus[i], cpus[j]) })
+
+		var overall, previous cpuCounters
+		for _, cpu := range cpus {
+			cur := *s.cpu[cpu]
+			before := cpuCounters{}
+			if p := prev.CPU[cpu]; p != nil && p.Total <= cur.Total && p.Idle <= cur.Idle {
+				before = *p
+			}
+			metrics.CPUCores = append(metrics.CPUCores, math.Round(busy(before, cur)*10)/10)
+			overall.Idle += cur.Idle
+			overall.Total += cur.Total
+			previous.Idle += before.Idle
+			previous.Total += before.Total
+		}
+		metrics.CPU = clampPercent(busy(previous, overall))
+	}
+
+	if s.memTotal != nil && s.memAvail != nil && *s.memTotal > 0 {
+		metrics.RAM = clampPercent((*s.memTotal - *s.memAvail) / *s.memTotal * 100)
+	}
+
+	mountpoints := make([]string, 0, len(s.filesystem))
+	for mountpoint := range s.filesystem {
+		mountpoints = append(mountpoints, mountpoint)
+	}
+	sort.Strings(mountpoints)
+	for _, mountpoint := range mountpoints {
+		fs := s.filesystem[mountpoint]
+		// Same as df: used over what is usable by unprivileged users
+		used := fs.size - fs.free
+		if fs.size <= 0 || used < 0 || used+fs.avail <= 0 {
+			continue
+		}
+		usage := used / (used + fs.avail) * 100
+		if mountpoint == "/" {
+			metrics.Disk = clampPercent(usage)
+		}
+		if len(metrics.Mounts) < maxNodeMounts {
+			metrics.Mounts = append(metrics.Mounts, dto.MountUsage{
+				Path:       mountpoint,
+				UsedBytes:  int64(used),
+				TotalBytes: int64(used + fs.avail),
+				Percent:    math.Round(usage*10) / 10,
+			})
+		}
+	}
+
+	if s.load[0] != nil && s.load[1] != nil && s.load[2] != nil {
+		metrics.Load = &dto.LoadAverage{Load1: *s.load[0], Load5: *s.load[1], Load15: *s.load[2]}
+	}
+
+	if s.rx != nil && s.tx != nil && prev.Rx != nil && prev.Tx != nil && prev.Timestamp > 0 &&
+		*s.rx >= *prev.Rx && *s.tx >= *prev.Tx {
+		elapsed := float64(s.timestamp-prev.Timestamp) / 1000
+		metrics.Network = &dto.NetworkUsage{
+			RxBytesPerSec: math.Round((*s.rx - *prev.Rx) / elapsed),
+			TxBytesPerSec: math.Round((*s.tx - *prev.Tx) / elapsed),
+		}
+	}
+
+	if s.pids != nil {
+		pids := int(*s.pids)
+		metrics.Processes = &pids
+	}
+	if s.bootTime != nil && s.nodeTime != nil && *s.nodeTime >= *s.bootTime {
+		uptime := int64(*s.nodeTime - *s.bootTime)
+		metrics.UptimeSeconds = &uptime
+	}
+
+	return metrics
+}
+
+func busy(prev, cur cpuCounters) float64 {
+	deltaTotal := cur.Total - prev.Total
+	if deltaTotal <= 0 {
+		return 0
+	}
+	return (deltaTotal - (cur.Idle - prev.Idle)) / deltaTotal * 100
+}
+
+func clampPercent(value float64) int {
+	return int(math.Round(math.Max(0, math.Min(100, value))))
+}
+
+func addValue(total *float64, value float64) *float64 {
+	if total == nil {
+		return &value
+	}
+	sum := *total + value
+	return &sum
+}
+
+// naturalLess orders numeric cpu labels numerically
+func naturalLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
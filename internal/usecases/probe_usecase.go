@@ -0,0 +1,352 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gammazero/workerpool"
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/domain/services"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultProbeWorkers = 32
+	defaultProbeTimeout = 5 * time.Second
+	maxProbeTimeout     = time.Minute
+)
+
+type ProbeUseCase interface {
+	CreateProbe(ctx context.Context, serverID uint, req dto.CreateProbeRequest) (*entity.Probe, error)
+	ListProbes(ctx context.Context, serverID uint) ([]*entity.Probe, error)
+	UpdateProbe(ctx context.Context, serverID uint, probeID uint, req dto.UpdateProbeRequest) (*entity.Probe, error)
+	DeleteProbe(ctx context.Context, serverID uint, probeID uint) error
+	// RunDue starts the probes of every server whose interval elapsed since
+	// its last run. It does not wait for the probes.
+	RunDue(ctx context.Context) error
+}
+
+type probeUseCase struct {
+	probeRepo     repository.ProbeRepository
+	serverRepo    repository.ServerRepository
+	serverUseCase ServerUseCase
+	prober        services.Prober
+	targetPolicy  services.TargetPolicy
+	redisCache    cache.CacheClient
+	pool          *workerpool.WorkerPool
+	logger        *zap.Logger
+
+	mu       sync.Mutex
+	inFlight map[uint]bool
+}
+
+func NewProbeUseCase(probeRepo repository.ProbeRepository, serverRepo repository.ServerRepository, serverUseCase ServerUseCase, prober services.Prober, targetPolicy services.TargetPolicy, redisCache cache.CacheClient, config configs.Probe, logger *zap.Logger) ProbeUseCase {
+	workers := config.Workers
+	if workers <= 0 {
+		workers = defaultProbeWorkers
+	}
+	return &probeUseCase{
+		probeRepo:     probeRepo,
+		serverRepo:    serverRepo,
+		serverUseCase: serverUseCase,
+		prober:        prober,
+		targetPolicy:  targetPolicy,
+		redisCache:    redisCache,
+		pool:          workerpool.New(workers),
+		logger:        logger,
+		inFlight:      make(map[uint]bool),
+	}
+}
+
+func (p *probeUseCase) CreateProbe(ctx context.Context, serverID uint, req dto.CreateProbeRequest) (*entity.Probe, error) {
+	server, err := p.serverRepo.GetByID(ctx, serverID)
+	if err != nil {
+		return nil, domainerrors.ErrServerNotFound
+	}
+
+	probe := &entity.Probe{
+		ServerID:       serverID,
+		Type:           entity.ProbeType(req.Type),
+		Port:           req.Port,
+		URL:            req.URL,
+		ExpectedStatus: req.ExpectedStatus,
+		BodyMatch:      req.BodyMatch,
+		Timeout:        defaultProbeTimeout,
+		Enabled:        req.Enabled == nil || *req.Enabled,
+	}
+	if req.Timeout != "" {
+		timeout, err := parseProbeTimeout(req.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		probe.Timeout = timeout
+	}
+	if err := validateProbe(probe); err != nil {
+		return nil, err
+	}
+	if err := p.checkTarget(ctx, probe, server); err != nil {
+		return nil, err
+	}
+
+	if err := p.probeRepo.Create(ctx, probe); err != nil {
+		p.logger.Error("Failed to create probe",
+			zap.Uint("server_id", serverID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to create probe: %w", err)
+	}
+
+	p.logger.Info("Probe created",
+		zap.Uint("id", probe.ID),
+		zap.Uint("server_id", serverID),
+		zap.String("type", string(probe.Type)),
+	)
+	return probe, nil
+}
+
+func (p *probeUseCase) ListProbes(ctx context.Context, serverID uint) ([]*entity.Probe, error) {
+	if _, err := p.serverRepo.GetByID(ctx, serverID); err != nil {
+		return nil, domainerrors.ErrServerNotFound
+	}
+
+	probes, err := p.probeRepo.ListByServer(ctx, serverID)
+	if err != nil {
+		p.logger.Error("Failed to list probes", zap.Uint("server_id", serverID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list probes: %w", err)
+	}
+	return probes, nil
+}
+
+func (p *probeUseCase) UpdateProbe(ctx context.Context, serverID uint, probeID uint, req dto.UpdateProbeRequest) (*entity.Probe, error) {
+	probe, err := p.getProbe(ctx, serverID, probeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Port != nil {
+		probe.Port = *req.Port
+	}
+	if req.URL != nil {
+		probe.URL = *req.URL
+	}
+	if req.ExpectedStatus != nil {
+		probe.ExpectedStatus = *req.ExpectedStatus
+	}
+	if req.BodyMatch != nil {
+		probe.BodyMatch = *req.BodyMatch
+	}
+	if req.Timeout != nil {
+		timeout, err := parseProbeTimeout(*req.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		probe.Timeout = timeout
+	}
+	if req.Enabled != nil {
+		probe.Enabled = *req.Enabled
+	}
+	if err := validateProbe(probe); err != nil {
+		return nil, err
+	}
+	server, err := p.serverRepo.GetByID(ctx, serverID)
+	if err != nil {
+		return nil, domainerrors.ErrServerNotFound
+	}
+	if err := p.checkTarget(ctx, probe, server); err != nil {
+		return nil, err
+	}
+
+	if err := p.probeRepo.Update(ctx, probe); err != nil {
+		p.logger.Error("Failed to update probe", zap.Uint("id", probeID), zap.Error(err))
+		return nil, fmt.Errorf("failed to update probe: %w", err)
+	}
+
+	p.logger.Info("Probe updated", zap.Uint("id", probe.ID), zap.Uint("server_id", serverID))
+	return probe, nil
+}
+
+func (p *probeUseCase) DeleteProbe(ctx context.Context, serverID uint, probeID uint) error {
+	if _, err := p.getProbe(ctx, serverID, probeID); err != nil {
+		return err
+	}
+
+	if err := p.probeRepo.Delete(ctx, probeID); err != nil {
+		p.logger.Error("Failed to delete probe", zap.Uint("id", probeID), zap.Error(err))
+		return fmt.Errorf("failed to delete probe: %w", err)
+	}
+
+	p.logger.Info("Probe deleted", zap.Uint("id", probeID), zap.Uint("server_id", serverID))
+	return nil
+}
+
+func (p *probeUseCase) getProbe(ctx context.Context, serverID uint, probeID uint) (*entity.Probe, error) {
+	probe, err := p.probeRepo.GetByID(ctx, probeID)
+	if err != nil || probe.ServerID != serverID {
+		return nil, domainerrors.ErrProbeNotFound
+	}
+	return probe, nil
+}
+
+func (p *probeUseCase) RunDue(ctx context.Context) error {
+	targets, err := p.probeRepo.ListTargets(ctx)
+	if err != nil {
+		p.logger.Error("Failed to list probe targets", zap.Error(err))
+		return fmt.Errorf("failed to list probe targets: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, target := range targets {
+		id := target.Server.ID
+		if p.inFlight[id] {
+			continue
+		}
+		key := fmt.Sprintf("probe:last:%s", target.Server.ServerID)
+		due, err := claimInterval(ctx, p.redisCache, key, target.Server.IntervalTime)
+		if err != nil {
+			p.logger.Error("Failed to check last probe run", zap.String("server_id", target.Server.ServerID), zap.Error(err))
+			return fmt.Errorf("failed to check last probe run: %w", err)
+		}
+		if !due {
+			continue
+		}
+
+		p.inFlight[id] = true
+		target := target
+		p.pool.Submit(func() {
+			p.run(ctx, target)
+
+			p.mu.Lock()
+			delete(p.inFlight, id)
+			p.mu.Unlock()
+		})
+	}
+
+	return nil
+}
+
+// run probes a server. The server is up when every probe succeeds: that
+// counts as a heartbeat, so the status history and uptime are recorded like
+// for an agent. Otherwise it is turned OFF right away.
+func (p *probeUseCase) run(ctx context.Context, target *entity.ProbeTarget) {
+	server := target.Server
+	checkedAt := time.Now()
+
+	up := true
+	for _, probe := range target.Probes {
+		latency, err := p.prober.Probe(ctx, probe, server)
+		probe.LastCheckedAt = &checkedAt
+		probe.LastSuccess = err == nil
+		probe.LastLatency = latency
+		probe.LastError = ""
+		if err != nil {
+			up = false
+			probe.LastError = probeFailure(err)
+			p.logger.Debug("Probe failed",
+				zap.String("server_id", server.ServerID),
+				zap.Uint("probe_id", probe.ID),
+				zap.Error(err),
+			)
+		}
+		if err := p.probeRepo.SaveResult(ctx, probe); err != nil {
+			p.logger.Error("Failed to save probe result",
+				zap.Uint("probe_id", probe.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	if up {
+		if err := p.serverUseCase.Heartbeat(ctx, server.ServerID, checkedAt); err != nil {
+			p.logger.Error("Failed to record probe heartbeat",
+				zap.String("server_id", server.ServerID),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+
+	if server.Status == entity.ServerStatusOff {
+		return
+	}
+	if err := p.serverUseCase.MarkOffline(ctx, server.ServerID, checkedAt); err != nil {
+		p.logger.Error("Failed to mark probed server offline",
+			zap.String("server_id", server.ServerID),
+			zap.Error(err),
+		)
+	}
+}
+
+// checkTarget refuses probes of addresses the target policy does not allow
+func (p *probeUseCase) checkTarget(ctx context.Context, probe *entity.Probe, server *entity.Server) error {
+	if probe.Type == entity.ProbeTypeHTTP {
+		return checkTargetURL(ctx, p.targetPolicy, probe.URL)
+	}
+	if err := p.targetPolicy.CheckHost(ctx, server.IPv4); err != nil {
+		return fmt.Errorf("%w: %v", domainerrors.ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// probeFailure is the error shown to users. Connection errors are reduced to
+// their kind so probes cannot be used to read what internal hosts answer.
+func probeFailure(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, services.ErrProbeCheckFailed):
+		return err.Error()
+	case errors.Is(err, domainerrors.ErrTargetNotAllowed):
+		return domainerrors.ErrTargetNotAllowed.Error()
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused"
+	case errors.As(err, &dnsErr):
+		return "host not found"
+	default:
+		return "connection failed"
+	}
+}
+
+func parseProbeTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 || timeout > maxProbeTimeout {
+		return 0, fmt.Errorf("%w: invalid timeout %q", domainerrors.ErrInvalidInput, value)
+	}
+	return timeout, nil
+}
+
+func validateProbe(probe *entity.Probe) error {
+	switch probe.Type {
+	case entity.ProbeTypeTCP:
+		if probe.Port == 0 {
+			return fmt.Errorf("%w: tcp probes require a port", domainerrors.ErrInvalidInput)
+		}
+	case entity.ProbeTypeHTTP:
+		if probe.URL == "" {
+			return fmt.Errorf("%w: http probes require a url", domainerrors.ErrInvalidInput)
+		}
+		if probe.BodyMatch != "" {
+			if _, err := regexp.Compile(probe.BodyMatch); err != nil {
+				return fmt.Errorf("%w: invalid body_match: %v", domainerrors.ErrInvalidInput, err)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown probe type %q", domainerrors.ErrInvalidInput, probe.Type)
+	}
+	return nil
+}
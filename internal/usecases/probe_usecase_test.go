@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/domain/services"
+)
+
+func TestProbeFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "check failure",
+			err:  fmt.Errorf("%w: unexpected status 500, expected 200", services.ErrProbeCheckFailed),
+			want: "probe check failed: unexpected status 500, expected 200",
+		},
+		{
+			name: "refused by the policy",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("%w: 169.254.169.254", domainerrors.ErrTargetNotAllowed)},
+			want: "target address not allowed",
+		},
+		{
+			name: "deadline",
+			err:  fmt.Errorf("Get \"http://10.0.0.12/healthz\": %w", context.DeadlineExceeded),
+			want: "timeout",
+		},
+		{
+			name: "connection refused",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			want: "connection refused",
+		},
+		{
+			name: "unknown host",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "db.internal"}},
+			want: "host not found",
+		},
+		{
+			name: "anything else",
+			err:  errors.New("read tcp 10.0.0.5:41234->10.0.0.12:80: connection reset by peer"),
+			want: "connection failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, probeFailure(tt.err))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+const defaultRemoteWriteServerLabel = "instance"
+
+type RemoteWriteUseCase interface {
+	// Ingest maps node_exporter series to samples of the registered servers.
+	// A non empty serverID restricts the request to that server.
+	Ingest(ctx context.Context, series []dto.PrometheusTimeSeries, serverID string) (*dto.RemoteWriteResult, error)
+}
+
+type remoteWriteUseCase struct {
+	serverUseCase ServerUseCase
+	mapper        *nodeExporterMapper
+	config        configs.RemoteWrite
+	logger        *zap.Logger
+}
+
+func NewRemoteWriteUseCase(serverUseCase ServerUseCase, redisCache cache.CacheClient, config configs.RemoteWrite, logger *zap.Logger) RemoteWriteUseCase {
+	if config.ServerLabel == "" {
+		config.ServerLabel = defaultRemoteWriteServerLabel
+	}
+	return &remoteWriteUseCase{
+		serverUseCase: serverUseCase,
+		mapper:        newNodeExporterMapper(redisCache, logger),
+		config:        config,
+		logger:        logger,
+	}
+}
+
+func (r *remoteWriteUseCase) Ingest(ctx context.Context, series []dto.PrometheusTimeSeries, serverID string) (*dto.RemoteWriteResult, error) {
+	result := &dto.RemoteWriteResult{Series: len(series)}
+
+	// Accepted and Rejected count metrics samples, one per server and
+	// timestamp, so series of other servers are grouped the same way
+	scrapes := make(map[string]nodeScrapes)
+	foreign := make(map[string]nodeScrapes)
+	for _, ts := range series {
+		result.Samples += len(ts.Samples)
+		target := r.serverID(ts.Labels)
+		if target == "" {
+			continue
+		}
+		group := scrapes
+		if serverID != "" && target != serverID {
+			group = foreign
+		}
+		if group[target] == nil {
+			group[target] = make(nodeScrapes)
+		}
+		group[target].add(ts)
+	}
+	for _, byTimestamp := range foreign {
+		result.Rejected += len(byTimestamp.usable())
+	}
+
+	var samples []dto.MetricsRequest
+	for target, byTimestamp := range scrapes {
+		samples = append(samples, r.mapper.toMetrics(ctx, target, byTimestamp)...)
+	}
+	if len(samples) == 0 {
+		return result, nil
+	}
+
+	unknown := make(map[string]bool)
+	var retryable error
+	for i, err := range r.serverUseCase.ProcessMetricsBatch(ctx, samples) {
+		switch {
+		case err == nil:
+			result.Accepted++
+		case errors.Is(err, domainerrors.ErrServerNotFound):
+			result.Rejected++
+			unknown[samples[i].ServerID] = true
+		case errors.Is(err, domainerrors.ErrServerPendingApproval),
+			errors.Is(err, domainerrors.ErrInvalidMetrics),
+			errors.Is(err, domainerrors.ErrFutureTimestamp):
+			result.Rejected++
+		default:
+			result.Rejected++
+			retryable = err
+		}
+	}
+	for serverID := range unknown {
+		result.UnknownServers = append(result.UnknownServers, serverID)
+	}
+	sort.Strings(result.UnknownServers)
+
+	if retryable != nil {
+		return result, fmt.Errorf("failed to process remote write samples: %w", retryable)
+	}
+	return result, nil
+}
+
+// serverID returns the server a series belongs to, empty when it has no server label
+func (r *remoteWriteUseCase) serverID(labels map[string]string) string {
+	value := labels[r.config.ServerLabel]
+	if r.config.StripPort {
+		if host, _, err := net.SplitHostPort(value); err == nil {
+			return host
+		}
+	}
+	return value
+}
@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gammazero/workerpool"
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/domain/services"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+const defaultScrapeWorkers = 32
+
+type ScrapeUseCase interface {
+	// ScrapeDue starts a scrape of every pull mode server whose interval
+	// elapsed since its last scrape. It does not wait for the scrapes.
+	ScrapeDue(ctx context.Context) error
+}
+
+type scrapeUseCase struct {
+	serverRepo    repository.ServerRepository
+	serverUseCase ServerUseCase
+	scraper       services.MetricsScraper
+	mapper        *nodeExporterMapper
+	redisCache    cache.CacheClient
+	pool          *workerpool.WorkerPool
+	logger        *zap.Logger
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+func NewScrapeUseCase(serverRepo repository.ServerRepository, serverUseCase ServerUseCase, scraper services.MetricsScraper, redisCache cache.CacheClient, config configs.Scrape, logger *zap.Logger) ScrapeUseCase {
+	workers := config.Workers
+	if workers <= 0 {
+		workers = defaultScrapeWorkers
+	}
+	return &scrapeUseCase{
+		serverRepo:    serverRepo,
+		serverUseCase: serverUseCase,
+		scraper:       scraper,
+		mapper:        newNodeExporterMapper(redisCache, logger),
+		redisCache:    redisCache,
+		pool:          workerpool.New(workers),
+		logger:        logger,
+		inFlight:      make(map[string]bool),
+	}
+}
+
+func (s *scrapeUseCase) ScrapeDue(ctx context.Context) error {
+	servers, err := s.serverRepo.ListScrapeTargets(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list scrape targets", zap.Error(err))
+		return fmt.Errorf("failed to list scrape targets: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, server := range servers {
+		if s.inFlight[server.ServerID] {
+			continue
+		}
+		key := fmt.Sprintf("scrape:last:%s", server.ServerID)
+		due, err := claimInterval(ctx, s.redisCache, key, server.IntervalTime)
+		if err != nil {
+			s.logger.Error("Failed to check last scrape", zap.String("server_id", server.ServerID), zap.Error(err))
+			return fmt.Errorf("failed to check last scrape: %w", err)
+		}
+		if !due {
+			continue
+		}
+
+		s.inFlight[server.ServerID] = true
+		server := server
+		s.pool.Submit(func() {
+			s.scrape(ctx, server)
+
+			s.mu.Lock()
+			delete(s.inFlight, server.ServerID)
+			s.mu.Unlock()
+		})
+	}
+
+	return nil
+}
+
+// claimInterval reports whether the interval of a server elapsed since the
+// last claim of key, and claims it if so. The claim is kept in Redis so a
+// new leader does not run again what the previous one just ran.
+func claimInterval(ctx context.Context, redisCache cache.CacheClient, key string, intervalSeconds int64) (bool, error) {
+	interval := time.Duration(intervalSeconds) * time.Second
+	if interval <= 0 {
+		return true, nil
+	}
+	return redisCache.SetNX(ctx, key, time.Now().Unix(), interval)
+}
+
+// checkTargetURL returns ErrInvalidInput if the host of a scrape or probe URL
+// is refused by the target policy
+func checkTargetURL(ctx context.Context, policy services.TargetPolicy, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: invalid url %q", domainerrors.ErrInvalidInput, rawURL)
+	}
+	if err := policy.CheckHost(ctx, parsed.Hostname()); err != nil {
+		return fmt.Errorf("%w: %v", domainerrors.ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// scrape feeds one scrape to the heartbeat path. A failed scrape is not
+// reported anywhere, like a heartbeat the server did not send: the status
+// turns OFF once the heartbeat window passes without a successful scrape.
+func (s *scrapeUseCase) scrape(ctx context.Context, server *entity.Server) {
+	series, err := s.scraper.Scrape(ctx, server.ScrapeURL)
+	if err != nil {
+		s.logger.Warn("Failed to scrape server",
+			zap.String("server_id", server.ServerID),
+			zap.String("scrape_url", server.ScrapeURL),
+			zap.Error(err),
+		)
+		return
+	}
+
+	scrapes := make(nodeScrapes)
+	for _, ts := range series {
+		scrapes.add(ts)
+	}
+	samples := s.mapper.toMetrics(ctx, server.ServerID, scrapes)
+	if len(samples) == 0 {
+		s.logger.Warn("Scrape returned no node_exporter metrics",
+			zap.String("server_id", server.ServerID),
+			zap.String("scrape_url", server.ScrapeURL),
+		)
+		return
+	}
+
+	for _, sample := range samples {
+		if err := s.serverUseCase.ProcessMetrics(ctx, sample); err != nil {
+			s.logger.Error("Failed to process scraped metrics",
+				zap.String("server_id", server.ServerID),
+				zap.Error(err),
+			)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+func TestRecordFlip(t *testing.T) {
+	relaxed := &entity.StatusPolicy{FlapThreshold: 20}
+
+	tests := []struct {
+		name         string
+		status       entity.ServerStatus
+		override     *entity.StatusPolicy
+		earlierFlips []time.Duration
+		want         entity.ServerStatus
+	}{
+		{
+			name:         "below the threshold",
+			status:       entity.ServerStatusOn,
+			earlierFlips: []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute},
+			want:         entity.ServerStatusOn,
+		},
+		{
+			name:         "reaching the threshold",
+			status:       entity.ServerStatusOn,
+			earlierFlips: []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute, 5 * time.Minute},
+			want:         entity.ServerStatusFlapping,
+		},
+		{
+			name:         "flips outside the window",
+			status:       entity.ServerStatusOff,
+			earlierFlips: []time.Duration{time.Minute, 2 * time.Minute, 11 * time.Minute, 12 * time.Minute, 13 * time.Minute},
+			want:         entity.ServerStatusOff,
+		},
+		{
+			name:         "in maintenance",
+			status:       entity.ServerStatusMaintenance,
+			earlierFlips: []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute, 5 * time.Minute},
+			want:         entity.ServerStatusMaintenance,
+		},
+		{
+			name:         "higher threshold for the server",
+			status:       entity.ServerStatusOn,
+			override:     relaxed,
+			earlierFlips: []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute, 5 * time.Minute},
+			want:         entity.ServerStatusOn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := &entity.Server{ServerID: "server-01", Status: tt.status, StatusPolicy: tt.override}
+			repo := newStatusRepo(server)
+			redisCache := newMemCache()
+			redisCache.addFlips(server.ServerID, time.Now(), tt.earlierFlips...)
+			s := newStatusUseCase(repo, redisCache, nil)
+
+			s.recordFlip(ctx, server, s.policyFor(server))
+
+			assert.Equal(t, tt.want, repo.servers["server-01"].Status)
+			assert.Equal(t, tt.want == entity.ServerStatusFlapping, redisCache.sets[flappingServersKey]["server-01"])
+		})
+	}
+}
+
+// A server enters FLAPPING at the threshold (6 changes in 10 minutes) and only
+// leaves it once the changes drop below half of it
+func TestSettleFlapping(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    entity.ServerStatus
+		flips     []time.Duration
+		heartbeat bool
+		firing    bool
+		override  *entity.StatusPolicy
+		want      entity.ServerStatus
+		inSet     bool
+	}{
+		{
+			name:   "still at the threshold",
+			status: entity.ServerStatusFlapping,
+			flips:  []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute, 5 * time.Minute, 6 * time.Minute},
+			want:   entity.ServerStatusFlapping,
+			inSet:  true,
+		},
+		{
+			name:   "half the threshold",
+			status: entity.ServerStatusFlapping,
+			flips:  []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute},
+			want:   entity.ServerStatusFlapping,
+			inSet:  true,
+		},
+		{
+			name:   "below half the threshold without heartbeat",
+			status: entity.ServerStatusFlapping,
+			flips:  []time.Duration{time.Minute, 2 * time.Minute, 11 * time.Minute, 12 * time.Minute},
+			want:   entity.ServerStatusOff,
+		},
+		{
+			name:      "below half the threshold with a heartbeat",
+			status:    entity.ServerStatusFlapping,
+			flips:     []time.Duration{time.Minute},
+			heartbeat: true,
+			want:      entity.ServerStatusOn,
+		},
+		{
+			name:      "below half the threshold with a firing alert",
+			status:    entity.ServerStatusFlapping,
+			heartbeat: true,
+			firing:    true,
+			want:      entity.ServerStatusDegraded,
+		},
+		{
+			name:     "threshold of the server raised since",
+			status:   entity.ServerStatusFlapping,
+			flips:    []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute, 5 * time.Minute, 6 * time.Minute},
+			override: &entity.StatusPolicy{FlapThreshold: 20},
+			want:     entity.ServerStatusOff,
+		},
+		{
+			name:   "no longer flapping",
+			status: entity.ServerStatusMaintenance,
+			want:   entity.ServerStatusMaintenance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := &entity.Server{ServerID: "server-01", Status: tt.status, StatusPolicy: tt.override}
+			repo := newStatusRepo(server)
+			redisCache := newMemCache()
+			redisCache.addFlips(server.ServerID, time.Now(), tt.flips...)
+			redisCache.SADD(ctx, flappingServersKey, server.ServerID)
+			if tt.heartbeat {
+				redisCache.Set(ctx, "heartbeat:server-01", heartbeatState{}, time.Minute)
+			}
+			s := newStatusUseCase(repo, redisCache, map[string]bool{"server-01": tt.firing})
+
+			require.NoError(t, s.settleFlapping(ctx))
+
+			assert.Equal(t, tt.want, repo.servers["server-01"].Status)
+			assert.Equal(t, tt.inSet, redisCache.sets[flappingServersKey]["server-01"])
+		})
+	}
+}
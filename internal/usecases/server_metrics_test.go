@@ -0,0 +1,111 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+// historyRepo records the backfilled status events of its only server
+type historyRepo struct {
+	repository.ServerRepository
+	server *entity.Server
+	events []entity.ServerStatus
+}
+
+func (r *historyRepo) GetByServerID(ctx context.Context, serverID string) (*entity.Server, error) {
+	if serverID != r.server.ServerID {
+		return nil, domainerrors.ErrServerNotFound
+	}
+	return r.server, nil
+}
+
+func (r *historyRepo) RecordStatusEvent(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) error {
+	r.events = append(r.events, status)
+	return nil
+}
+
+type metricsStore struct {
+	repository.MetricsRepository
+	inserted []*entity.ServerMetrics
+}
+
+func (m *metricsStore) BatchInsert(ctx context.Context, metrics []*entity.ServerMetrics) error {
+	m.inserted = append(m.inserted, metrics...)
+	return nil
+}
+
+// closedWindowCache has no heartbeat state, any write to it panics
+type closedWindowCache struct {
+	cache.CacheClient
+}
+
+func (closedWindowCache) Get(ctx context.Context, key string, dest any) error {
+	return cache.ErrCacheMiss
+}
+
+// A single sample goes through the same checks as a batch: it cannot be
+// ahead of the server clock, and an old one is backfilled without touching
+// the heartbeat window
+func TestProcessMetricsOutsideTheWindow(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		timestamp    time.Time
+		wantErr      error
+		wantInserted int
+		wantEvents   []entity.ServerStatus
+	}{
+		{
+			name:      "ahead of the server clock",
+			timestamp: now.Add(10 * time.Minute),
+			wantErr:   domainerrors.ErrFutureTimestamp,
+		},
+		{
+			name:         "older than the heartbeat window",
+			timestamp:    now.Add(-time.Hour),
+			wantInserted: 1,
+			wantEvents:   []entity.ServerStatus{entity.ServerStatusOn, entity.ServerStatusOff},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &historyRepo{server: &entity.Server{
+				ServerID:     "server-01",
+				Status:       entity.ServerStatusOff,
+				IntervalTime: 60,
+			}}
+			metrics := &metricsStore{}
+			s := &serverUseCase{
+				serverRepo:   repo,
+				metricsRepo:  metrics,
+				redisCache:   closedWindowCache{},
+				statusPolicy: newStatusPolicy(configs.StatusPolicy{}),
+				logger:       zap.NewNop(),
+			}
+
+			err := s.ProcessMetrics(context.Background(), dto.MetricsRequest{
+				ServerID:  "server-01",
+				Timestamp: tt.timestamp,
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, metrics.inserted, tt.wantInserted)
+			assert.Equal(t, tt.wantEvents, repo.events)
+		})
+	}
+}
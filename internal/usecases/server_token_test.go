@@ -0,0 +1,179 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/infrastructure/services"
+	"go.uber.org/zap"
+)
+
+// tokenStore keeps the whitelist and the used refresh tokens of one server
+type tokenStore struct {
+	repository.TokenRepository
+	whitelist map[string]time.Duration
+	used      map[string]bool
+	revoked   bool
+}
+
+func newTokenStore() *tokenStore {
+	return &tokenStore{
+		whitelist: make(map[string]time.Duration),
+		used:      make(map[string]bool),
+	}
+}
+
+func (s *tokenStore) AddServerTokenToWhitelist(ctx context.Context, token string, serverID string, expiration time.Duration) error {
+	s.whitelist[token] = expiration
+	return nil
+}
+
+func (s *tokenStore) IsTokenWhitelisted(ctx context.Context, token string) bool {
+	_, ok := s.whitelist[token]
+	return ok
+}
+
+func (s *tokenStore) RemoveServerTokensFromWhitelist(ctx context.Context, serverID string) error {
+	s.whitelist = make(map[string]time.Duration)
+	s.revoked = true
+	return nil
+}
+
+func (s *tokenStore) RemoveServerTokenFromWhitelist(ctx context.Context, serverID string, token string) error {
+	delete(s.whitelist, token)
+	return nil
+}
+
+func (s *tokenStore) MarkServerTokenUsed(ctx context.Context, token string, expiration time.Duration) (bool, error) {
+	if s.used[token] {
+		return false, nil
+	}
+	s.used[token] = true
+	return true, nil
+}
+
+func (s *tokenStore) IsServerTokenUsed(ctx context.Context, token string) (bool, error) {
+	return s.used[token], nil
+}
+
+func (s *tokenStore) UnmarkServerTokenUsed(ctx context.Context, token string) error {
+	delete(s.used, token)
+	return nil
+}
+
+type tokenServerRepo struct {
+	repository.ServerRepository
+	server *entity.Server
+}
+
+func (r tokenServerRepo) GetByServerID(ctx context.Context, serverID string) (*entity.Server, error) {
+	if serverID != r.server.ServerID {
+		return nil, domainerrors.ErrServerNotFound
+	}
+	return r.server, nil
+}
+
+func newTokenUseCase(store *tokenStore, expiration time.Duration) *serverUseCase {
+	server := &entity.Server{ServerID: "server-01", ServerName: "web", HeartbeatKey: "key"}
+	tokenServices := services.NewJWTService(configs.JWT{Secret: "secret", Expiration: expiration})
+	return &serverUseCase{
+		serverRepo:      tokenServerRepo{server: server},
+		tokenRepository: store,
+		tokenServices:   tokenServices,
+		logger:          zap.NewNop(),
+	}
+}
+
+func TestIssueTokensWhitelistsUntilExpiry(t *testing.T) {
+	store := newTokenStore()
+	s := newTokenUseCase(store, 2*time.Hour)
+
+	response, err := s.issueTokens(context.Background(), s.serverRepo.(tokenServerRepo).server)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 2*time.Hour, store.whitelist[response.AccessToken], float64(time.Minute))
+	assert.InDelta(t, 14*time.Hour, store.whitelist[response.RefreshToken], float64(time.Minute))
+}
+
+func TestRefreshToken(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// setup returns the refresh token presented by the agent
+		setup       func(t *testing.T, s *serverUseCase, store *tokenStore) string
+		wantErr     error
+		wantRevoked bool
+	}{
+		{
+			name: "whitelisted token",
+			setup: func(t *testing.T, s *serverUseCase, store *tokenStore) string {
+				response, err := s.issueTokens(ctx, s.serverRepo.(tokenServerRepo).server)
+				require.NoError(t, err)
+				return response.RefreshToken
+			},
+		},
+		{
+			name: "token used twice",
+			setup: func(t *testing.T, s *serverUseCase, store *tokenStore) string {
+				response, err := s.issueTokens(ctx, s.serverRepo.(tokenServerRepo).server)
+				require.NoError(t, err)
+				_, err = s.RefreshToken(ctx, dto.RefreshTokenRequest{RefreshToken: response.RefreshToken})
+				require.NoError(t, err)
+				return response.RefreshToken
+			},
+			wantErr:     domainerrors.ErrRefreshTokenReused,
+			wantRevoked: true,
+		},
+		{
+			// A token dropped from the whitelist without being used, e.g. by a
+			// previous revocation, does not log the agent out again
+			name: "token not whitelisted",
+			setup: func(t *testing.T, s *serverUseCase, store *tokenStore) string {
+				response, err := s.issueTokens(ctx, s.serverRepo.(tokenServerRepo).server)
+				require.NoError(t, err)
+				delete(store.whitelist, response.RefreshToken)
+				return response.RefreshToken
+			},
+			wantErr: domainerrors.ErrInvalidRefreshToken,
+		},
+		{
+			name: "access token",
+			setup: func(t *testing.T, s *serverUseCase, store *tokenStore) string {
+				response, err := s.issueTokens(ctx, s.serverRepo.(tokenServerRepo).server)
+				require.NoError(t, err)
+				return response.AccessToken
+			},
+			wantErr: domainerrors.ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTokenStore()
+			s := newTokenUseCase(store, time.Hour)
+			token := tt.setup(t, s, store)
+
+			response, err := s.RefreshToken(ctx, dto.RefreshTokenRequest{RefreshToken: token})
+			assert.Equal(t, tt.wantRevoked, store.revoked)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				if tt.wantErr == domainerrors.ErrInvalidRefreshToken {
+					assert.False(t, store.used[token], "a rejected token is not marked used")
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, store.IsTokenWhitelisted(ctx, response.RefreshToken))
+			assert.False(t, store.IsTokenWhitelisted(ctx, token), "the presented token is rotated out")
+		})
+	}
+}
@@ -2,12 +2,22 @@ package usecases
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gammazero/workerpool"
+	"github.com/th1enq/server_management_system/internal/configs"
 	"github.com/th1enq/server_management_system/internal/domain/entity"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
 	"github.com/th1enq/server_management_system/internal/domain/query"
 	"github.com/th1enq/server_management_system/internal/domain/repository"
 	"github.com/th1enq/server_management_system/internal/domain/services"
@@ -19,170 +29,1413 @@ import (
 
 type ServerUseCase interface {
 	ProcessMetrics(ctx context.Context, metrics dto.MetricsRequest) error
+	ProcessMetricsBatch(ctx context.Context, samples []dto.MetricsRequest) []error
+	Heartbeat(ctx context.Context, serverID string, timestamp time.Time) error
+	MarkOffline(ctx context.Context, serverID string, timestamp time.Time) error
 	RefreshStatus(ctx context.Context) error
-	Register(ctx context.Context, req dto.CreateServerRequest) (*dto.AuthResponse, error)
+	Register(ctx context.Context, enrollmentToken string, req dto.CreateServerRequest) (*dto.AuthResponse, error)
+	ApproveServer(ctx context.Context, id uint) (*entity.Server, error)
+	RejectServer(ctx context.Context, id uint) error
+	// SetStatus puts a server in MAINTENANCE or takes it out to OFF, until its
+	// next heartbeat
+	SetStatus(ctx context.Context, id uint, status entity.ServerStatus) (*entity.Server, error)
+	// StartMaintenance puts a server in MAINTENANCE on behalf of a window,
+	// EndMaintenance turns it OFF once no window holds it anymore. Servers put
+	// in maintenance by an admin are left alone by both.
+	StartMaintenance(ctx context.Context, serverID string, windowID uint) (bool, error)
+	EndMaintenance(ctx context.Context, serverID string) (bool, error)
 	CreateServer(ctx context.Context, req dto.CreateServerRequest) (*entity.Server, error)
 	GetServerByID(ctx context.Context, serverID string) (*entity.Server, error)
 	GetServer(ctx context.Context, id uint) (*entity.Server, error)
 	ListServers(ctx context.Context, filter dto.ServerFilter, pagination dto.Pagination) ([]*entity.Server, error)
 	UpdateServer(ctx context.Context, id uint, updates dto.UpdateServerRequest) (*entity.Server, error)
 	DeleteServer(ctx context.Context, id uint) error
+	RevokeCredentials(ctx context.Context, id uint) error
+	RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (*dto.AuthResponse, error)
 	ImportServers(ctx context.Context, filePath string) (*dto.ImportResult, error)
 	ExportServers(ctx context.Context, filter dto.ServerFilter, pagination dto.Pagination) (string, error)
 	GetServerStats(ctx context.Context) (dto.ServerStatusResponse, error)
 	GetServerIDs(ctx context.Context) ([]string, error)
+	GetServerMetrics(ctx context.Context, id uint, metricsQuery dto.MetricsQuery) (*dto.MetricsSeriesResponse, error)
+	GetStatusHistory(ctx context.Context, id uint, historyQuery dto.StatusHistoryQuery) (*dto.StatusHistoryResponse, error)
+	GetDependencies(ctx context.Context, id uint) (*dto.ServerDependenciesResponse, error)
+	// SetDependencies replaces the parents of a server, refusing cycles
+	SetDependencies(ctx context.Context, id uint, req dto.UpdateDependenciesRequest) (*dto.ServerDependenciesResponse, error)
+	GetTopology(ctx context.Context) (*dto.TopologyResponse, error)
+	GetAgentConfig(ctx context.Context, serverID string) (*dto.AgentConfig, error)
+	GetAgentConfigByID(ctx context.Context, id uint) (*dto.AgentConfig, error)
+	UpdateAgentConfig(ctx context.Context, id uint, req dto.UpdateAgentConfigRequest) (*dto.AgentConfig, error)
+	GetHeartbeatKey(ctx context.Context, serverID string) (string, error)
+	// GetStatusPolicy returns the global status policy with the overrides of
+	// the server applied
+	GetStatusPolicy(ctx context.Context, serverID string) (entity.StatusPolicy, error)
 }
 
+const (
+	defaultMetricsWindow = time.Hour
+	defaultMetricsStep   = time.Minute
+	maxMetricsPoints     = 10000
+
+	defaultStatusHistoryWindow   = 24 * time.Hour
+	defaultStatusHistoryPageSize = 50
+
+	// Samples slightly ahead of the server clock are tolerated
+	maxMetricsClockSkew = time.Minute
+
+	agentConfigCacheTTL = time.Hour
+
+	heartbeatKeyBytes    = 32
+	heartbeatKeyCacheTTL = 10 * time.Minute
+
+	// Sorted set of server IDs scored by the unix milliseconds at which
+	// their heartbeat window lapses
+	heartbeatDeadlinesKey  = "heartbeat_deadlines"
+	refreshStatusBatchSize = 500
+
+	// Heartbeats only move last_seen_at forward once per interval, the
+	// heartbeat window already tracks liveness precisely
+	lastSeenWriteInterval = time.Minute
+
+	// Set of the server IDs in MAINTENANCE, their alert rules are not
+	// evaluated
+	maintenanceServersKey = "maintenance_servers"
+	// Set of the server IDs in FLAPPING, checked on every status refresh
+	flappingServersKey = "flapping_servers"
+)
+
+// heartbeatState is stored under heartbeat:<server_id> while the heartbeat
+// window of a server is open
+type heartbeatState struct {
+	Window time.Duration `json:"window"`
+}
+
+var gaugeNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.:]{0,63}$`)
+
 type serverUseCase struct {
 	logger           *zap.Logger
 	serverRepo       repository.ServerRepository
+	metricsRepo      repository.MetricsRepository
+	statusEventRepo  repository.ServerStatusEventRepository
+	dependencyRepo   repository.ServerDependencyRepository
+	tokenRepository  repository.TokenRepository
+	enrollmentRepo   repository.EnrollmentTokenRepository
+	alertRuleUseCase AlertRuleUseCase
 	tokenServices    services.TokenServices
 	excelizeServices services.ExcelizeService
+	targetPolicy     services.TargetPolicy
 	inMemoryCache    cache.InMemoryCache
 	redisCache       cache.CacheClient
+	statusPolicy     entity.StatusPolicy
+
+	deadlinesSeeded atomic.Bool
+}
+
+func NewServerUseCase(serverRepo repository.ServerRepository, metricsRepo repository.MetricsRepository, statusEventRepo repository.ServerStatusEventRepository, dependencyRepo repository.ServerDependencyRepository, tokenRepository repository.TokenRepository, enrollmentRepo repository.EnrollmentTokenRepository, alertRuleUseCase AlertRuleUseCase, tokenServices services.TokenServices, excelizeServices services.ExcelizeService, targetPolicy services.TargetPolicy, inMemoryCache cache.InMemoryCache, redisCache cache.CacheClient, statusPolicy configs.StatusPolicy, logger *zap.Logger) ServerUseCase {
+	return &serverUseCase{
+		serverRepo:       serverRepo,
+		metricsRepo:      metricsRepo,
+		statusEventRepo:  statusEventRepo,
+		dependencyRepo:   dependencyRepo,
+		tokenRepository:  tokenRepository,
+		enrollmentRepo:   enrollmentRepo,
+		alertRuleUseCase: alertRuleUseCase,
+		tokenServices:    tokenServices,
+		excelizeServices: excelizeServices,
+		targetPolicy:     targetPolicy,
+		inMemoryCache:    inMemoryCache,
+		redisCache:       redisCache,
+		statusPolicy:     newStatusPolicy(statusPolicy),
+		logger:           logger,
+	}
+}
+
+// newStatusPolicy fills the fields missing from the configuration with the
+// historical behaviour: OFF after 1.5 intervals, ON on the first heartbeat,
+// online from 70% uptime, no flap detection
+func newStatusPolicy(config configs.StatusPolicy) entity.StatusPolicy {
+	policy := entity.StatusPolicy{
+		MissedIntervals:    config.MissedIntervals,
+		GraceMultiplier:    config.GraceMultiplier,
+		RecoveryHeartbeats: config.RecoveryHeartbeats,
+		OnlineThreshold:    config.OnlineThreshold,
+		FlapThreshold:      config.FlapThreshold,
+		FlapWindow:         config.FlapWindow,
+	}
+	if policy.MissedIntervals <= 0 {
+		policy.MissedIntervals = 1
+	}
+	if policy.GraceMultiplier <= 0 {
+		policy.GraceMultiplier = 1.5
+	}
+	if policy.RecoveryHeartbeats <= 0 {
+		policy.RecoveryHeartbeats = 1
+	}
+	if policy.OnlineThreshold <= 0 {
+		policy.OnlineThreshold = 70
+	}
+	return policy
+}
+
+// ProcessMetrics ingests a single sample with the same rules as
+// ProcessMetricsBatch: a sample older than the heartbeat window is backfilled
+// and never changes the current status.
+func (s *serverUseCase) ProcessMetrics(ctx context.Context, metrics dto.MetricsRequest) error {
+	return s.ProcessMetricsBatch(ctx, []dto.MetricsRequest{metrics})[0]
+}
+
+// processFreshMetrics stores a sample inside the heartbeat window and extends
+// the window
+func (s *serverUseCase) processFreshMetrics(ctx context.Context, metrics dto.MetricsRequest) error {
+	if err := s.Heartbeat(ctx, metrics.ServerID, metrics.Timestamp); err != nil {
+		return err
+	}
+
+	return s.storeMetrics(ctx, metrics)
+}
+
+// Heartbeat extends the heartbeat window of a server. A server whose window
+// had lapsed is turned ON once it sent the recovery heartbeats of its policy.
+func (s *serverUseCase) Heartbeat(ctx context.Context, serverID string, timestamp time.Time) error {
+	cacheKey := fmt.Sprintf("heartbeat:%s", serverID)
+	var state heartbeatState
+	if err := s.redisCache.Get(ctx, cacheKey, &state); err == nil && state.Window > 0 {
+		s.redisCache.Expire(ctx, cacheKey, state.Window)
+		s.touchLastSeen(ctx, serverID, timestamp)
+		return s.setHeartbeatDeadline(ctx, serverID, state.Window)
+	}
+	server, err := s.GetServerByID(ctx, serverID)
+	if err != nil {
+		s.logger.Error("Failed to get server by ID", zap.String("server_id", serverID), zap.Error(err))
+		if errors.Is(err, domainerrors.ErrServerNotFound) {
+			return domainerrors.ErrServerNotFound
+		}
+		return err
+	}
+	if server.Status == entity.ServerStatusPendingApproval {
+		return domainerrors.ErrServerPendingApproval
+	}
+
+	policy := s.policyFor(server)
+	window := policy.HeartbeatWindow(server.IntervalTime)
+	down := !server.Status.IsUp() && server.Status != entity.ServerStatusMaintenance
+	if down && policy.RecoveryHeartbeats > 1 {
+		// Each heartbeat must come within the window of the previous one
+		streakKey := fmt.Sprintf("heartbeat_streak:%s", serverID)
+		streak, err := s.redisCache.Incr(ctx, streakKey, window)
+		if err == nil && streak < int64(policy.RecoveryHeartbeats) {
+			s.touchLastSeen(ctx, serverID, timestamp)
+			return nil
+		}
+		s.redisCache.Del(ctx, streakKey)
+	}
+
+	s.redisCache.Set(ctx, cacheKey, heartbeatState{Window: window}, window)
+	if err := s.setHeartbeatDeadline(ctx, serverID, window); err != nil {
+		return err
+	}
+	if down {
+		s.recordFlip(ctx, server, policy)
+	}
+
+	loc, _ := time.LoadLocation("Asia/Ho_Chi_Minh")
+	timestamp = timestamp.In(loc)
+
+	status := s.upStatus(ctx, serverID)
+	changed, err := s.serverRepo.UpdateStatus(ctx, serverID, status, timestamp)
+	if err != nil {
+		s.logger.Error("Failed to update server status", zap.String("server_id", serverID), zap.String("status", string(status)), zap.Error(err))
+		return fmt.Errorf("failed to update server status: %w", err)
+	}
+	if changed {
+		s.logger.Info("Server is back online", zap.String("server_id", serverID), zap.String("status", string(status)))
+		s.parentRecovered(ctx, serverID)
+	}
+
+	// The window had lapsed, write the last seen time right away
+	s.redisCache.Del(ctx, fmt.Sprintf("last_seen:%s", serverID))
+	s.touchLastSeen(ctx, serverID, timestamp)
+
+	return nil
+}
+
+// upStatus is the status of a server sending heartbeats: DEGRADED while one
+// of its alerts is firing, ON otherwise
+func (s *serverUseCase) upStatus(ctx context.Context, serverID string) entity.ServerStatus {
+	firing, err := s.alertRuleUseCase.HasFiring(ctx, serverID)
+	if err != nil {
+		s.logger.Warn("Failed to check firing alerts", zap.String("server_id", serverID), zap.Error(err))
+	}
+	if firing {
+		return entity.ServerStatusDegraded
+	}
+	return entity.ServerStatusOn
+}
+
+// touchLastSeen stores the time of a heartbeat, at most once per
+// lastSeenWriteInterval for each server
+func (s *serverUseCase) touchLastSeen(ctx context.Context, serverID string, timestamp time.Time) {
+	acquired, err := s.redisCache.SetNX(ctx, fmt.Sprintf("last_seen:%s", serverID), timestamp.Unix(), lastSeenWriteInterval)
+	if err != nil || !acquired {
+		return
+	}
+	if err := s.serverRepo.TouchLastSeen(ctx, serverID, timestamp); err != nil {
+		s.logger.Warn("Failed to update last seen time", zap.String("server_id", serverID), zap.Error(err))
+	}
+}
+
+// MarkOffline ends the heartbeat window of a server and turns it OFF, or
+// UNREACHABLE, without waiting for the window to lapse
+func (s *serverUseCase) MarkOffline(ctx context.Context, serverID string, timestamp time.Time) error {
+	cacheKey := fmt.Sprintf("heartbeat:%s", serverID)
+	var state heartbeatState
+	wasUp := s.redisCache.Get(ctx, cacheKey, &state) == nil
+	if err := s.redisCache.Del(ctx, cacheKey); err != nil {
+		s.logger.Error("Failed to delete heartbeat", zap.String("server_id", serverID), zap.Error(err))
+		return fmt.Errorf("failed to delete heartbeat: %w", err)
+	}
+	if err := s.redisCache.ZREM(ctx, heartbeatDeadlinesKey, serverID); err != nil {
+		s.logger.Error("Failed to delete heartbeat deadline", zap.String("server_id", serverID), zap.Error(err))
+		return fmt.Errorf("failed to delete heartbeat deadline: %w", err)
+	}
+
+	changed, err := s.markDown(ctx, serverID, timestamp)
+	if err != nil {
+		s.logger.Error("Failed to update server status to OFF", zap.String("server_id", serverID), zap.Error(err))
+		return fmt.Errorf("failed to update server status: %w", err)
+	}
+	if changed {
+		s.logger.Info("Server marked offline", zap.String("server_id", serverID))
+	}
+	if changed || wasUp {
+		s.serverWentDown(ctx, serverID, changed)
+	}
+
+	return nil
+}
+
+// heartbeatWindow returns the heartbeat window of a server and whether it is
+// up. While the window is open both come from the heartbeat state, so fresh
+// samples do not hit the database.
+func (s *serverUseCase) heartbeatWindow(ctx context.Context, serverID string) (time.Duration, bool, error) {
+	var state heartbeatState
+	if err := s.redisCache.Get(ctx, fmt.Sprintf("heartbeat:%s", serverID), &state); err == nil && state.Window > 0 {
+		return state.Window, true, nil
+	}
+
+	server, err := s.GetServerByID(ctx, serverID)
+	if err != nil {
+		s.logger.Error("Failed to get server by ID", zap.String("server_id", serverID), zap.Error(err))
+		if errors.Is(err, domainerrors.ErrServerNotFound) {
+			return 0, false, domainerrors.ErrServerNotFound
+		}
+		return 0, false, err
+	}
+	if server.Status == entity.ServerStatusPendingApproval {
+		return 0, false, domainerrors.ErrServerPendingApproval
+	}
+	return s.policyFor(server).HeartbeatWindow(server.IntervalTime), server.Status.IsUp(), nil
+}
+
+// ProcessMetricsBatch ingests samples that may have been buffered by the agent.
+// Samples inside the heartbeat window extend the window. Older samples
+// are only added to the metrics and status history, they never change the
+// current status. The returned slice holds the error of each sample.
+func (s *serverUseCase) ProcessMetricsBatch(ctx context.Context, samples []dto.MetricsRequest) []error {
+	errs := make([]error, len(samples))
+	now := time.Now()
+
+	byServer := make(map[string][]int)
+	for i, sample := range samples {
+		if sample.Timestamp.After(now.Add(maxMetricsClockSkew)) {
+			errs[i] = domainerrors.ErrFutureTimestamp
+			continue
+		}
+		if err := validateMetrics(sample); err != nil {
+			errs[i] = err
+			continue
+		}
+		byServer[sample.ServerID] = append(byServer[sample.ServerID], i)
+	}
+
+	for serverID, indexes := range byServer {
+		window, online, err := s.heartbeatWindow(ctx, serverID)
+		if err != nil {
+			setErrors(errs, indexes, err)
+			continue
+		}
+
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return samples[indexes[a]].Timestamp.Before(samples[indexes[b]].Timestamp)
+		})
+
+		var stale, fresh []int
+		for _, i := range indexes {
+			if samples[i].Timestamp.Before(now.Add(-window)) {
+				stale = append(stale, i)
+			} else {
+				fresh = append(fresh, i)
+			}
+		}
+
+		if len(stale) > 0 {
+			if err := s.backfillMetrics(ctx, serverID, samples, stale, window, online || len(fresh) > 0); err != nil {
+				setErrors(errs, stale, err)
+			}
+		}
+		for _, i := range fresh {
+			errs[i] = s.processFreshMetrics(ctx, samples[i])
+		}
+	}
+
+	return errs
+}
+
+// backfillMetrics stores stale samples sorted by timestamp and rebuilds the
+// status history they imply. Each run of samples without a gap larger than the
+// heartbeat window becomes an ON event at its first sample and an OFF event
+// when its heartbeat would have expired. The OFF event of the last run is
+// skipped when the server is online now, the run may have lasted until then.
+func (s *serverUseCase) backfillMetrics(ctx context.Context, serverID string, samples []dto.MetricsRequest, stale []int, window time.Duration, online bool) error {
+	metrics := make([]*entity.ServerMetrics, 0, len(stale))
+	for _, i := range stale {
+		metrics = append(metrics, dto.ToServerMetricsEntity(samples[i]))
+	}
+	if err := s.metricsRepo.BatchInsert(ctx, metrics); err != nil {
+		s.logger.Error("Failed to store backfilled metrics",
+			zap.String("server_id", serverID),
+			zap.Int("samples", len(metrics)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to store server metrics: %w", err)
+	}
+
+	runStart := metrics[0].Timestamp
+	for i := range metrics {
+		last := i == len(metrics)-1
+		if !last && metrics[i+1].Timestamp.Sub(metrics[i].Timestamp) <= window {
+			continue
+		}
+		s.recordStatusEvent(ctx, serverID, entity.ServerStatusOn, runStart)
+		if !last || !online {
+			s.recordStatusEvent(ctx, serverID, entity.ServerStatusOff, metrics[i].Timestamp.Add(window))
+		}
+		if !last {
+			runStart = metrics[i+1].Timestamp
+		}
+	}
+
+	s.logger.Info("Backfilled server metrics",
+		zap.String("server_id", serverID),
+		zap.Int("samples", len(metrics)),
+	)
+
+	return nil
+}
+
+func (s *serverUseCase) recordStatusEvent(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) {
+	if err := s.serverRepo.RecordStatusEvent(ctx, serverID, status, timestamp); err != nil {
+		s.logger.Error("Failed to record backfilled status event",
+			zap.String("server_id", serverID),
+			zap.String("status", string(status)),
+			zap.Time("timestamp", timestamp),
+			zap.Error(err),
+		)
+	}
+}
+
+// storeMetrics saves a live sample and evaluates the alert rules against it,
+// unless the server is in maintenance. Alerting failures are logged, they
+// never reject the sample.
+func (s *serverUseCase) storeMetrics(ctx context.Context, metrics dto.MetricsRequest) error {
+	sample := dto.ToServerMetricsEntity(metrics)
+	if err := s.metricsRepo.Insert(ctx, sample); err != nil {
+		s.logger.Error("Failed to store server metrics", zap.String("server_id", metrics.ServerID), zap.Error(err))
+		return fmt.Errorf("failed to store server metrics: %w", err)
+	}
+	if inMaintenance, _ := s.redisCache.SISMEMBER(ctx, maintenanceServersKey, metrics.ServerID); inMaintenance {
+		return nil
+	}
+	alertChanged, err := s.alertRuleUseCase.Evaluate(ctx, sample)
+	if err != nil {
+		s.logger.Error("Failed to evaluate alert rules", zap.String("server_id", metrics.ServerID), zap.Error(err))
+	}
+	if alertChanged {
+		// An alert fired or resolved, the server may move between ON and DEGRADED
+		status := s.upStatus(ctx, metrics.ServerID)
+		if _, err := s.serverRepo.UpdateStatus(ctx, metrics.ServerID, status, metrics.Timestamp); err != nil {
+			s.logger.Error("Failed to update server status", zap.String("server_id", metrics.ServerID), zap.String("status", string(status)), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *serverUseCase) GetServerMetrics(ctx context.Context, id uint, metricsQuery dto.MetricsQuery) (*dto.MetricsSeriesResponse, error) {
+	server, err := s.serverRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get server by ID",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return nil, domainerrors.ErrServerNotFound
+	}
+
+	to := metricsQuery.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := metricsQuery.From
+	if from.IsZero() {
+		from = to.Add(-defaultMetricsWindow)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", domainerrors.ErrInvalidTimeRange)
+	}
+
+	step := defaultMetricsStep
+	if metricsQuery.Step != "" {
+		step, err = time.ParseDuration(metricsQuery.Step)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", domainerrors.ErrInvalidStep, err.Error())
+		}
+	}
+	if step < time.Second || step%time.Second != 0 {
+		return nil, fmt.Errorf("%w: step must be a whole number of seconds", domainerrors.ErrInvalidStep)
+	}
+
+	// Align the range start on the step so buckets line up with the aggregation query
+	stepSeconds := int64(step / time.Second)
+	from = time.Unix(from.Unix()/stepSeconds*stepSeconds, 0).In(from.Location())
+	if int64(to.Sub(from)/step) > maxMetricsPoints {
+		return nil, fmt.Errorf("%w: range contains more than %d points", domainerrors.ErrInvalidStep, maxMetricsPoints)
+	}
+
+	buckets, err := s.metricsRepo.QuerySeries(ctx, server.ServerID, query.MetricsRange{
+		From:   from,
+		To:     to,
+		Step:   step,
+		Gauges: metricsQuery.Gauges,
+	})
+	if err != nil {
+		s.logger.Error("Failed to query server metrics",
+			zap.String("server_id", server.ServerID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to query server metrics: %w", err)
+	}
+
+	bucketByTime := make(map[int64]entity.MetricsBucket, len(buckets))
+	for _, bucket := range buckets {
+		bucketByTime[bucket.Timestamp.Unix()] = bucket
+	}
+
+	points := make([]dto.MetricsPoint, 0)
+	for t := from; t.Before(to); t = t.Add(step) {
+		bucket, ok := bucketByTime[t.Unix()]
+		if !ok {
+			points = append(points, dto.MetricsPoint{Timestamp: t})
+			continue
+		}
+		point := dto.FromEntityToMetricsPoint(bucket)
+		point.Timestamp = t
+		points = append(points, point)
+	}
+
+	return &dto.MetricsSeriesResponse{
+		ServerID: server.ServerID,
+		From:     from,
+		To:       to,
+		Step:     step.String(),
+		Points:   points,
+	}, nil
+}
+
+func (s *serverUseCase) GetStatusHistory(ctx context.Context, id uint, historyQuery dto.StatusHistoryQuery) (*dto.StatusHistoryResponse, error) {
+	server, err := s.serverRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get server by ID",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return nil, domainerrors.ErrServerNotFound
+	}
+
+	to := historyQuery.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := historyQuery.From
+	if from.IsZero() {
+		from = to.Add(-defaultStatusHistoryWindow)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", domainerrors.ErrInvalidTimeRange)
+	}
+
+	pagination := query.Pagination{
+		Page:     historyQuery.Page,
+		PageSize: historyQuery.PageSize,
+	}
+	if pagination.Page < 1 {
+		pagination.Page = 1
+	}
+	if pagination.PageSize < 1 {
+		pagination.PageSize = defaultStatusHistoryPageSize
+	}
+
+	events, total, err := s.statusEventRepo.List(ctx, server.ServerID, from, to, pagination)
+	if err != nil {
+		s.logger.Error("Failed to list server status events",
+			zap.String("server_id", server.ServerID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list server status events: %w", err)
+	}
+
+	response := make([]dto.StatusEventResponse, 0, len(events))
+	for _, event := range events {
+		response = append(response, dto.FromEntityToStatusEventResponse(event))
+	}
+
+	return &dto.StatusHistoryResponse{
+		ServerID: server.ServerID,
+		From:     from,
+		To:       to,
+		Total:    total,
+		Page:     pagination.Page,
+		Size:     pagination.PageSize,
+		Events:   response,
+	}, nil
+}
+
+func (s *serverUseCase) GetDependencies(ctx context.Context, id uint) (*dto.ServerDependenciesResponse, error) {
+	server, err := s.serverRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, domainerrors.ErrServerNotFound
+	}
+
+	parents, err := s.dependencyRepo.ListParents(ctx, server.ServerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list server parents: %w", err)
+	}
+	children, err := s.dependencyRepo.ListChildren(ctx, server.ServerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list server children: %w", err)
+	}
+
+	return &dto.ServerDependenciesResponse{
+		Server:   dto.FromEntityToServerNode(server),
+		Parents:  dto.FromEntitiesToServerNodes(parents),
+		Children: dto.FromEntitiesToServerNodes(children),
+	}, nil
+}
+
+func (s *serverUseCase) SetDependencies(ctx context.Context, id uint, req dto.UpdateDependenciesRequest) (*dto.ServerDependenciesResponse, error) {
+	if _, err := s.serverRepo.GetByID(ctx, id); err != nil {
+		return nil, domainerrors.ErrServerNotFound
+	}
+
+	parentIDs := make([]uint, 0, len(req.ParentIDs))
+	seen := make(map[uint]bool, len(req.ParentIDs))
+	for _, parentID := range req.ParentIDs {
+		if parentID == id {
+			return nil, domainerrors.ErrDependencyCycle
+		}
+		if seen[parentID] {
+			continue
+		}
+		if _, err := s.serverRepo.GetByID(ctx, parentID); err != nil {
+			return nil, fmt.Errorf("%w: parent %d", domainerrors.ErrServerNotFound, parentID)
+		}
+		seen[parentID] = true
+		parentIDs = append(parentIDs, parentID)
+	}
+
+	if err := s.dependencyRepo.SetParents(ctx, id, parentIDs); err != nil {
+		if errors.Is(err, domainerrors.ErrDependencyCycle) {
+			return nil, domainerrors.ErrDependencyCycle
+		}
+		s.logger.Error("Failed to set server dependencies", zap.Uint("id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to set server dependencies: %w", err)
+	}
+
+	s.logger.Info("Server dependencies updated", zap.Uint("id", id), zap.Any("parent_ids", parentIDs))
+	return s.GetDependencies(ctx, id)
+}
+
+func (s *serverUseCase) GetTopology(ctx context.Context) (*dto.TopologyResponse, error) {
+	dependencies, err := s.dependencyRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list server dependencies: %w", err)
+	}
+
+	edges := make([]dto.TopologyEdge, 0, len(dependencies))
+	inGraph := make(map[uint]bool)
+	for _, dependency := range dependencies {
+		edges = append(edges, dto.TopologyEdge{
+			ServerID: dependency.ServerID,
+			ParentID: dependency.ParentID,
+		})
+		inGraph[dependency.ServerID] = true
+		inGraph[dependency.ParentID] = true
+	}
+
+	nodes := make([]dto.ServerNode, 0, len(inGraph))
+	if len(inGraph) > 0 {
+		servers, err := s.serverRepo.GetAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list servers: %w", err)
+		}
+		for _, server := range servers {
+			if inGraph[server.ID] {
+				nodes = append(nodes, dto.FromEntityToServerNode(server))
+			}
+		}
+	}
+
+	return &dto.TopologyResponse{
+		Nodes: nodes,
+		Edges: edges,
+	}, nil
+}
+
+func (s *serverUseCase) GetServerByID(ctx context.Context, serverID string) (*entity.Server, error) {
+	server, err := s.serverRepo.GetByServerID(ctx, serverID)
+	if err != nil {
+		s.logger.Error("Failed to get server by ID",
+			zap.String("server_id", serverID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to get server by ID: %w", err)
+	}
+	return server, nil
+}
+
+// RefreshStatus turns OFF the servers whose heartbeat deadline has passed.
+// Deadlines live in a sorted set scored by unix milliseconds, so a tick only
+// touches the servers that expired since the previous one.
+func (s *serverUseCase) RefreshStatus(ctx context.Context) error {
+	if !s.deadlinesSeeded.Load() {
+		if err := s.seedHeartbeatDeadlines(ctx); err != nil {
+			return err
+		}
+		s.deadlinesSeeded.Store(true)
+	}
+
+	now := time.Now()
+	expired := 0
+	// Deadlines of the servers that could not be updated, put back once the
+	// loop is done so the next tick retries them without this one popping
+	// them again
+	var failed []string
+	var errs []error
+	for {
+		serverIDs, err := s.redisCache.ZPopByScore(ctx, heartbeatDeadlinesKey, float64(now.UnixMilli()), refreshStatusBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to pop expired heartbeat deadlines", zap.Error(err))
+			errs = append(errs, fmt.Errorf("failed to pop expired heartbeat deadlines: %w", err))
+			break
+		}
+
+		for _, serverID := range serverIDs {
+			if s.heartbeatArmed(ctx, serverID) {
+				continue
+			}
+			cacheKey := fmt.Sprintf("heartbeat:%s", serverID)
+			s.redisCache.Del(ctx, cacheKey)
+			changed, err := s.markDown(ctx, serverID, now)
+			if err != nil {
+				s.logger.Error("Failed to update server status to OFF",
+					zap.String("server_id", serverID),
+					zap.Error(err),
+				)
+				failed = append(failed, serverID)
+				errs = append(errs, fmt.Errorf("failed to update server %s status to OFF: %w", serverID, err))
+				continue
+			}
+			// A heartbeat that raced with the update above saw the server
+			// online, drop its window so the next one turns the server ON
+			var state heartbeatState
+			if err := s.redisCache.Get(ctx, cacheKey, &state); err == nil {
+				s.redisCache.Del(ctx, cacheKey)
+			}
+			if changed {
+				expired++
+			}
+			s.serverWentDown(ctx, serverID, changed)
+		}
+
+		if len(serverIDs) < refreshStatusBatchSize {
+			break
+		}
+	}
+
+	for _, serverID := range failed {
+		if err := s.redisCache.ZADDNX(ctx, heartbeatDeadlinesKey, float64(now.UnixMilli()), serverID); err != nil {
+			s.logger.Error("Failed to restore heartbeat deadline",
+				zap.String("server_id", serverID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	if expired > 0 {
+		s.logger.Info("Servers marked offline", zap.Int("count", expired))
+	}
+	if err := s.settleFlapping(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// markDown turns a server whose heartbeat window closed OFF, or UNREACHABLE
+// when one of its parents is down. The OFF servers depending on it then
+// become UNREACHABLE as well, the ones still up follow when their own window
+// closes.
+func (s *serverUseCase) markDown(ctx context.Context, serverID string, timestamp time.Time) (bool, error) {
+	rootCause := s.rootCause(ctx, serverID)
+	if rootCause == "" {
+		changed, err := s.serverRepo.UpdateStatus(ctx, serverID, entity.ServerStatusOff, timestamp)
+		if changed {
+			s.cascadeUnreachable(ctx, serverID, serverID, timestamp)
+		}
+		return changed, err
+	}
+
+	changed, err := s.serverRepo.MarkUnreachable(ctx, serverID, rootCause, timestamp)
+	if changed {
+		s.logger.Info("Server unreachable",
+			zap.String("server_id", serverID),
+			zap.String("root_cause", rootCause),
+		)
+		s.cascadeUnreachable(ctx, serverID, rootCause, timestamp)
+	}
+	return changed, err
+}
+
+// rootCause returns the down server making serverID unreachable, following
+// UNREACHABLE parents up to the server that went down on its own, or "" when
+// every parent is up
+func (s *serverUseCase) rootCause(ctx context.Context, serverID string) string {
+	parents, err := s.dependencyRepo.ListParents(ctx, serverID)
+	if err != nil {
+		s.logger.Warn("Failed to list server parents", zap.String("server_id", serverID), zap.Error(err))
+		return ""
+	}
+	for _, parent := range parents {
+		switch parent.Status {
+		case entity.ServerStatusOff:
+			return parent.ServerID
+		case entity.ServerStatusUnreachable:
+			// Dependencies are acyclic, the walk ends at the roots
+			if rootCause := s.rootCause(ctx, parent.ServerID); rootCause != "" {
+				return rootCause
+			}
+			return parent.ServerID
+		}
+	}
+	return ""
+}
+
+// cascadeUnreachable moves the OFF servers depending on a server that just
+// went down to UNREACHABLE, their failure most likely came from it
+func (s *serverUseCase) cascadeUnreachable(ctx context.Context, serverID string, rootCause string, timestamp time.Time) {
+	children, err := s.dependencyRepo.ListChildren(ctx, serverID)
+	if err != nil {
+		s.logger.Warn("Failed to list server children", zap.String("server_id", serverID), zap.Error(err))
+		return
+	}
+	for _, child := range children {
+		if child.Status != entity.ServerStatusOff {
+			continue
+		}
+		changed, err := s.serverRepo.MarkUnreachable(ctx, child.ServerID, rootCause, timestamp)
+		if err != nil {
+			s.logger.Error("Failed to update server status to UNREACHABLE",
+				zap.String("server_id", child.ServerID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if changed {
+			s.cascadeUnreachable(ctx, child.ServerID, rootCause, timestamp)
+		}
+	}
+}
+
+// parentRecovered gives the UNREACHABLE servers depending on a server back
+// online a heartbeat window to report, they turn OFF if they do not
+func (s *serverUseCase) parentRecovered(ctx context.Context, serverID string) {
+	children, err := s.dependencyRepo.ListChildren(ctx, serverID)
+	if err != nil {
+		s.logger.Warn("Failed to list server children", zap.String("server_id", serverID), zap.Error(err))
+		return
+	}
+	for _, child := range children {
+		if child.Status != entity.ServerStatusUnreachable {
+			continue
+		}
+		window := s.policyFor(child).HeartbeatWindow(child.IntervalTime)
+		s.redisCache.ZADDNX(ctx, heartbeatDeadlinesKey, float64(time.Now().Add(window).UnixMilli()), child.ServerID)
+	}
+}
+
+// serverWentDown records the heartbeat window of a server closing as a flip.
+// Servers whose status did not change are only counted while FLAPPING, the
+// others were already down or are in maintenance.
+func (s *serverUseCase) serverWentDown(ctx context.Context, serverID string, changed bool) {
+	if !changed {
+		flapping, err := s.redisCache.SISMEMBER(ctx, flappingServersKey, serverID)
+		if err != nil || !flapping {
+			return
+		}
+	}
+	server, err := s.GetServerByID(ctx, serverID)
+	if err != nil {
+		return
+	}
+	s.recordFlip(ctx, server, s.policyFor(server))
+}
+
+// recordFlip counts a change of the server between up and down and makes it
+// FLAPPING once the changes within the flap window reach the threshold
+func (s *serverUseCase) recordFlip(ctx context.Context, server *entity.Server, policy entity.StatusPolicy) {
+	if !policy.DetectsFlapping() || server.Status == entity.ServerStatusMaintenance {
+		return
+	}
+	now := time.Now()
+	flips, err := s.redisCache.ZAddWindow(ctx, fmt.Sprintf("status_flips:%s", server.ServerID),
+		float64(now.UnixMilli()), strconv.FormatInt(now.UnixNano(), 10), policy.FlapWindow)
+	if err != nil || flips < int64(policy.FlapThreshold) {
+		return
+	}
+
+	changed, err := s.serverRepo.UpdateStatus(ctx, server.ServerID, entity.ServerStatusFlapping, now)
+	if err != nil {
+		s.logger.Error("Failed to update server status to FLAPPING", zap.String("server_id", server.ServerID), zap.Error(err))
+		return
+	}
+	if changed {
+		s.redisCache.SADD(ctx, flappingServersKey, server.ServerID)
+		s.logger.Warn("Server is flapping",
+			zap.String("server_id", server.ServerID),
+			zap.Int64("changes", flips),
+			zap.Duration("window", policy.FlapWindow),
+		)
+	}
+}
+
+// settleFlapping gives the FLAPPING servers whose changes within the flap
+// window dropped below half the threshold their current status back. The gap
+// between entering and leaving keeps a server at the threshold from
+// alternating between FLAPPING and its status.
+func (s *serverUseCase) settleFlapping(ctx context.Context) error {
+	serverIDs, err := s.redisCache.SMEMBERS(ctx, flappingServersKey)
+	if err != nil {
+		s.logger.Error("Failed to get flapping servers", zap.Error(err))
+		return fmt.Errorf("failed to get flapping servers: %w", err)
+	}
+
+	now := time.Now()
+	for _, serverID := range serverIDs {
+		server, err := s.GetServerByID(ctx, serverID)
+		if err != nil || server.Status != entity.ServerStatusFlapping {
+			s.redisCache.SREM(ctx, flappingServersKey, serverID)
+			continue
+		}
+
+		policy := s.policyFor(server)
+		if policy.DetectsFlapping() {
+			flips, err := s.redisCache.ZCOUNT(ctx, fmt.Sprintf("status_flips:%s", serverID),
+				float64(now.Add(-policy.FlapWindow).UnixMilli()), math.Inf(1))
+			if err != nil || flips*2 >= int64(policy.FlapThreshold) {
+				continue
+			}
+		}
+
+		status := entity.ServerStatusOff
+		var state heartbeatState
+		if err := s.redisCache.Get(ctx, fmt.Sprintf("heartbeat:%s", serverID), &state); err == nil {
+			status = s.upStatus(ctx, serverID)
+		}
+		if _, err := s.serverRepo.ResolveFlapping(ctx, serverID, status, now); err != nil {
+			s.logger.Error("Failed to resolve flapping server",
+				zap.String("server_id", serverID),
+				zap.String("status", string(status)),
+				zap.Error(err),
+			)
+			continue
+		}
+		s.redisCache.SREM(ctx, flappingServersKey, serverID)
+		s.logger.Info("Server stopped flapping",
+			zap.String("server_id", serverID),
+			zap.String("status", string(status)),
+		)
+	}
+	return nil
+}
+
+// seedHeartbeatDeadlines gives the servers that are up but have no deadline,
+// e.g. after an upgrade or a Redis flush, a full heartbeat window from now.
+// It also restores the sets of servers in maintenance and flapping.
+func (s *serverUseCase) seedHeartbeatDeadlines(ctx context.Context) error {
+	var servers []*entity.Server
+	for _, status := range []entity.ServerStatus{entity.ServerStatusOn, entity.ServerStatusDegraded} {
+		list, err := s.serverRepo.ListByStatus(ctx, status)
+		if err != nil {
+			s.logger.Error("Failed to list online servers", zap.Error(err))
+			return fmt.Errorf("failed to list online servers: %w", err)
+		}
+		servers = append(servers, list...)
+	}
+	now := time.Now()
+	for _, server := range servers {
+		deadline := now.Add(s.policyFor(server).HeartbeatWindow(server.IntervalTime))
+		if err := s.redisCache.ZADDNX(ctx, heartbeatDeadlinesKey, float64(deadline.UnixMilli()), server.ServerID); err != nil {
+			return fmt.Errorf("failed to seed heartbeat deadline: %w", err)
+		}
+	}
+
+	inMaintenance, err := s.serverRepo.ListByStatus(ctx, entity.ServerStatusMaintenance)
+	if err != nil {
+		s.logger.Error("Failed to list servers in maintenance", zap.Error(err))
+		return fmt.Errorf("failed to list servers in maintenance: %w", err)
+	}
+	for _, server := range inMaintenance {
+		if err := s.redisCache.SADD(ctx, maintenanceServersKey, server.ServerID); err != nil {
+			return fmt.Errorf("failed to seed maintenance servers: %w", err)
+		}
+	}
+
+	flapping, err := s.serverRepo.ListByStatus(ctx, entity.ServerStatusFlapping)
+	if err != nil {
+		s.logger.Error("Failed to list flapping servers", zap.Error(err))
+		return fmt.Errorf("failed to list flapping servers: %w", err)
+	}
+	for _, server := range flapping {
+		if err := s.redisCache.SADD(ctx, flappingServersKey, server.ServerID); err != nil {
+			return fmt.Errorf("failed to seed flapping servers: %w", err)
+		}
+	}
+	return nil
+}
+
+// heartbeatArmed reports whether the server has a heartbeat deadline, which
+// after it was popped means a heartbeat arrived in the meantime
+func (s *serverUseCase) heartbeatArmed(ctx context.Context, serverID string) bool {
+	_, err := s.redisCache.ZSCORE(ctx, heartbeatDeadlinesKey, serverID)
+	return err == nil
+}
+
+// setHeartbeatDeadline schedules the OFF transition of a server one heartbeat
+// window from now
+func (s *serverUseCase) setHeartbeatDeadline(ctx context.Context, serverID string, window time.Duration) error {
+	deadline := time.Now().Add(window)
+	if err := s.redisCache.ZADD(ctx, heartbeatDeadlinesKey, float64(deadline.UnixMilli()), serverID); err != nil {
+		s.logger.Error("Failed to set heartbeat deadline", zap.String("server_id", serverID), zap.Error(err))
+		return fmt.Errorf("failed to set heartbeat deadline: %w", err)
+	}
+	return nil
+}
+
+func (s *serverUseCase) Register(ctx context.Context, enrollmentToken string, req dto.CreateServerRequest) (*dto.AuthResponse, error) {
+	token, err := s.enrollmentRepo.Consume(ctx, hashEnrollmentToken(enrollmentToken), time.Now())
+	if err != nil {
+		s.logger.Error("Failed to consume enrollment token",
+			zap.String("server_id", req.ServerID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to consume enrollment token: %w", err)
+	}
+	if token == nil {
+		s.logger.Warn("Invalid enrollment token",
+			zap.String("server_id", req.ServerID),
+			zap.String("ipv4", req.IPv4),
+		)
+		return nil, domainerrors.ErrInvalidEnrollmentToken
+	}
+
+	// Servers enrolled with a scoped token inherit its location and tags
+	if token.Location != "" {
+		req.Location = token.Location
+	}
+	req.Tags = mergeTags(token.Tags, req.Tags)
+
+	status := entity.ServerStatusUndefined
+	if token.RequireApproval {
+		status = entity.ServerStatusPendingApproval
+	}
+
+	server, err := s.createServer(ctx, req, status)
+	if err != nil {
+		if releaseErr := s.enrollmentRepo.Release(ctx, token.ID); releaseErr != nil {
+			s.logger.Error("Failed to release enrollment token",
+				zap.Uint("enrollment_token_id", token.ID),
+				zap.Error(releaseErr),
+			)
+		}
+		return nil, fmt.Errorf("failed to register server: %w", err)
+	}
+
+	s.logger.Info("Server enrolled",
+		zap.String("server_id", server.ServerID),
+		zap.Uint("enrollment_token_id", token.ID),
+		zap.String("status", string(server.Status)),
+	)
+
+	return s.issueTokens(ctx, server)
+}
+
+func (s *serverUseCase) RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
+	refreshToken := req.RefreshToken
+	claims, err := s.tokenServices.ValidateServerToken(refreshToken)
+	if err != nil {
+		s.logger.Warn("Invalid server refresh token", zap.Error(err))
+		return nil, domainerrors.ErrInvalidRefreshToken
+	}
+
+	if claims.TokenType != "server_refresh" || claims.Subject == "" || claims.ExpiresAt == nil {
+		return nil, domainerrors.ErrInvalidRefreshToken
+	}
+
+	// A refresh token can be used only once. Presenting it a second time means
+	// it leaked, so every credential of the server is revoked. Only tokens
+	// still whitelisted are marked, a revoked or unknown token is just
+	// rejected.
+	if !s.tokenRepository.IsTokenWhitelisted(ctx, refreshToken) {
+		used, err := s.tokenRepository.IsServerTokenUsed(ctx, refreshToken)
+		if err != nil {
+			s.logger.Error("Failed to check server refresh token use",
+				zap.String("server_id", claims.Subject),
+				zap.Error(err),
+			)
+			return nil, fmt.Errorf("failed to refresh token: %w", err)
+		}
+		if used {
+			return nil, s.revokeReusedToken(ctx, claims)
+		}
+		s.logger.Warn("Server refresh token is not whitelisted", zap.String("server_id", claims.Subject))
+		return nil, domainerrors.ErrInvalidRefreshToken
+	}
+
+	firstUse, err := s.tokenRepository.MarkServerTokenUsed(ctx, refreshToken, time.Until(claims.ExpiresAt.Time))
+	if err != nil {
+		s.logger.Error("Failed to mark server refresh token as used",
+			zap.String("server_id", claims.Subject),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	// Another request is rotating the same token
+	if !firstUse {
+		return nil, s.revokeReusedToken(ctx, claims)
+	}
+
+	response, err := s.rotateTokens(ctx, claims.Subject, refreshToken)
+	if err != nil {
+		// The agent retries with the same token, which must not look reused
+		if unmarkErr := s.tokenRepository.UnmarkServerTokenUsed(ctx, refreshToken); unmarkErr != nil {
+			s.logger.Error("Failed to release server refresh token",
+				zap.String("server_id", claims.Subject),
+				zap.Error(unmarkErr),
+			)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Server credentials rotated", zap.String("server_id", claims.Subject))
+
+	return response, nil
+}
+
+// revokeReusedToken revokes every credential of the server a consumed
+// refresh token was presented again for
+func (s *serverUseCase) revokeReusedToken(ctx context.Context, claims *dto.ServerClaims) error {
+	s.logger.Warn("Server refresh token reuse detected, revoking credentials",
+		zap.String("server_id", claims.Subject),
+		zap.String("token_id", claims.ID),
+	)
+	if err := s.tokenRepository.RemoveServerTokensFromWhitelist(ctx, claims.Subject); err != nil {
+		s.logger.Error("Failed to revoke server credentials",
+			zap.String("server_id", claims.Subject),
+			zap.Error(err),
+		)
+	}
+	return domainerrors.ErrRefreshTokenReused
+}
+
+// rotateTokens issues new credentials and revokes the refresh token they
+// replace
+func (s *serverUseCase) rotateTokens(ctx context.Context, serverID string, refreshToken string) (*dto.AuthResponse, error) {
+	server, err := s.GetServerByID(ctx, serverID)
+	if err != nil {
+		s.logger.Error("Failed to get server by ID", zap.String("server_id", serverID), zap.Error(err))
+		if errors.Is(err, domainerrors.ErrServerNotFound) {
+			return nil, domainerrors.ErrServerNotFound
+		}
+		return nil, err
+	}
+
+	response, err := s.issueTokens(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove old refresh token from whitelist
+	if err := s.tokenRepository.RemoveServerTokenFromWhitelist(ctx, server.ServerID, refreshToken); err != nil {
+		s.logger.Error("Failed to remove old refresh token from whitelist",
+			zap.String("server_id", server.ServerID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to remove old token from whitelist: %w", err)
+	}
+	return response, nil
+}
+
+func (s *serverUseCase) issueTokens(ctx context.Context, server *entity.Server) (*dto.AuthResponse, error) {
+	// Servers created before heartbeat keys existed get one on their next refresh
+	if server.HeartbeatKey == "" {
+		if err := s.rotateHeartbeatKey(ctx, server); err != nil {
+			return nil, err
+		}
+	}
+
+	accessToken, err := s.tokenServices.GenerateServerAccessToken(ctx, server)
+	if err != nil {
+		s.logger.Error("Failed to generate access token for server",
+			zap.String("server_id", server.ServerID),
+			zap.String("server_name", server.ServerName),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshToken, err := s.tokenServices.GenerateServerRefreshToken(ctx, server)
+	if err != nil {
+		s.logger.Error("Failed to generate refresh token for server",
+			zap.String("server_id", server.ServerID),
+			zap.String("server_name", server.ServerName),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	// Add tokens to Redis whitelist, each for as long as it is valid
+	for _, token := range []string{accessToken, refreshToken} {
+		if err := s.whitelistServerToken(ctx, server.ServerID, token); err != nil {
+			s.logger.Error("Failed to add server token to whitelist", zap.String("server_id", server.ServerID), zap.Error(err))
+			return nil, fmt.Errorf("failed to whitelist token: %w", err)
+		}
+	}
+
+	return &dto.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		HeartbeatKey: server.HeartbeatKey,
+	}, nil
 }
 
-func NewServerUseCase(serverRepo repository.ServerRepository, tokenServices services.TokenServices, excelizeServices services.ExcelizeService, inMemoryCache cache.InMemoryCache, redisCache cache.CacheClient, logger *zap.Logger) ServerUseCase {
-	return &serverUseCase{
-		serverRepo:       serverRepo,
-		tokenServices:    tokenServices,
-		excelizeServices: excelizeServices,
-		inMemoryCache:    inMemoryCache,
-		redisCache:       redisCache,
-		logger:           logger,
+// whitelistServerToken whitelists a token until its exp claim
+func (s *serverUseCase) whitelistServerToken(ctx context.Context, serverID string, token string) error {
+	claims, err := s.tokenServices.ValidateServerToken(token)
+	if err != nil {
+		return err
+	}
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("token has no expiry")
 	}
+	return s.tokenRepository.AddServerTokenToWhitelist(ctx, token, serverID, time.Until(claims.ExpiresAt.Time))
 }
 
-func (s *serverUseCase) ProcessMetrics(ctx context.Context, metrics dto.MetricsRequest) error {
-	cacheKey := fmt.Sprintf("heartbeat:%s", metrics.ServerID)
-	var intervalCheckTime int64
-	if err := s.redisCache.Get(ctx, cacheKey, &intervalCheckTime); err == nil {
-		expireTime := time.Duration(1.5*float64(intervalCheckTime)) * time.Second
-		s.redisCache.Expire(ctx, cacheKey, expireTime)
-		return nil
-	}
-	server, err := s.GetServerByID(ctx, metrics.ServerID)
+func (s *serverUseCase) ApproveServer(ctx context.Context, id uint) (*entity.Server, error) {
+	server, err := s.serverRepo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get server by ID", zap.String("server_id", metrics.ServerID), zap.Error(err))
-		return fmt.Errorf("server not found")
+		s.logger.Error("Failed to get server by ID",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return nil, domainerrors.ErrServerNotFound
+	}
+	if server.Status != entity.ServerStatusPendingApproval {
+		return nil, domainerrors.ErrServerNotPendingApproval
 	}
 
-	intervalCheckTime = server.IntervalTime
-	expireTime := time.Duration(1.5*float64(intervalCheckTime)) * time.Second
-
-	s.redisCache.Set(ctx, cacheKey, intervalCheckTime, expireTime)
+	// The server stays OFF until its first heartbeat
+	if _, err := s.serverRepo.UpdateStatus(ctx, server.ServerID, entity.ServerStatusOff, time.Now()); err != nil {
+		s.logger.Error("Failed to approve server",
+			zap.String("server_id", server.ServerID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to approve server: %w", err)
+	}
+	server.Status = entity.ServerStatusOff
 
-	loc, _ := time.LoadLocation("Asia/Ho_Chi_Minh")
-	metrics.Timestamp = metrics.Timestamp.In(loc)
+	s.inMemoryCache.Delete("list_server_id")
 
-	if err := s.serverRepo.UpdateStatus(ctx, metrics.ServerID, entity.ServerStatusOn, metrics.Timestamp); err != nil {
-		s.logger.Error("Failed to update server status to ON", zap.String("server_id", metrics.ServerID), zap.Error(err))
-		return fmt.Errorf("failed to update server status: %w", err)
-	}
+	s.logger.Info("Server approved",
+		zap.Uint("id", server.ID),
+		zap.String("server_id", server.ServerID),
+	)
 
-	return nil
+	return server, nil
 }
 
-func (s *serverUseCase) GetServerByID(ctx context.Context, serverID string) (*entity.Server, error) {
-	server, err := s.serverRepo.GetByServerID(ctx, serverID)
+func (s *serverUseCase) SetStatus(ctx context.Context, id uint, status entity.ServerStatus) (*entity.Server, error) {
+	server, err := s.serverRepo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Failed to get server by ID",
-			zap.String("server_id", serverID),
+			zap.Uint("id", id),
 			zap.Error(err),
 		)
-		return nil, fmt.Errorf("failed to get server by ID: %w", err)
+		return nil, domainerrors.ErrServerNotFound
+	}
+	if server.Status == status {
+		return server, nil
 	}
-	return server, nil
-}
 
-func (s *serverUseCase) RefreshStatus(ctx context.Context) error {
-	serverIDs, err := s.GetServerIDs(ctx)
+	switch status {
+	case entity.ServerStatusMaintenance:
+	case entity.ServerStatusOff:
+		// Only maintenance is left manually, OFF is otherwise up to heartbeats
+		if server.Status != entity.ServerStatusMaintenance {
+			return nil, fmt.Errorf("%w: %s to %s", domainerrors.ErrInvalidStatusTransition, server.Status, status)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s cannot be set manually", domainerrors.ErrInvalidStatusTransition, status)
+	}
+	if !server.Status.CanTransitionTo(status) {
+		return nil, fmt.Errorf("%w: %s to %s", domainerrors.ErrInvalidStatusTransition, server.Status, status)
+	}
+
+	now := time.Now()
+	var changed bool
+	if status == entity.ServerStatusMaintenance {
+		changed, err = s.serverRepo.EnterMaintenance(ctx, server.ServerID, nil, now)
+	} else {
+		changed, err = s.serverRepo.LeaveMaintenance(ctx, server.ServerID, false, now)
+	}
 	if err != nil {
-		s.logger.Error("Failed to get server IDs for refresh",
+		s.logger.Error("Failed to update server status",
+			zap.String("server_id", server.ServerID),
+			zap.String("status", string(status)),
 			zap.Error(err),
 		)
-		return fmt.Errorf("failed to get server IDs: %w", err)
+		return nil, fmt.Errorf("failed to update server status: %w", err)
 	}
-	if len(serverIDs) == 0 {
-		s.logger.Info("No servers found for status refresh")
-		return nil
+	if !changed {
+		// The status moved since it was read
+		return nil, fmt.Errorf("%w: %s changed concurrently", domainerrors.ErrInvalidStatusTransition, server.ServerID)
 	}
 
-	for _, serverID := range serverIDs {
-		var intervalCheckTime int64
-		cacheKey := fmt.Sprintf("heartbeat:%s", serverID)
-		if err := s.redisCache.Get(ctx, cacheKey, &intervalCheckTime); err != nil {
-			s.logger.Info("Failed to get heartbeat timestamp from cache",
-				zap.String("cache_key", cacheKey),
-			)
-
-			// server, err := s.GetServerByID(ctx, serverID)
-			// if err != nil {
-			// 	s.logger.Error("Failed to get server by ID",
-			// 		zap.String("server_id", serverID),
-			// 		zap.Error(err),
-			// 	)
-			// 	return fmt.Errorf("failed to get server by ID: %w", err)
-			// }
+	if status == entity.ServerStatusMaintenance {
+		s.redisCache.SADD(ctx, maintenanceServersKey, server.ServerID)
+	} else {
+		s.maintenanceEnded(ctx, server.ServerID)
+	}
 
-			// oldStatus := server.Status
+	server.Status = status
+	server.StatusChangedAt = &now
 
-			if err := s.serverRepo.UpdateStatus(ctx, serverID, entity.ServerStatusOff, time.Now()); err != nil {
-				s.logger.Error("Failed to update server status to OFF",
-					zap.String("server_id", serverID),
-					zap.Error(err),
-				)
-				return fmt.Errorf("failed to update server status to OFF: %w", err)
-			}
+	s.logger.Info("Server status set manually",
+		zap.Uint("id", server.ID),
+		zap.String("server_id", server.ServerID),
+		zap.String("status", string(status)),
+	)
 
-			// intervalCheckTime := server.IntervalTime
+	return server, nil
+}
 
-			// s.statusChangeProducer.Produce(ctx, producer.StatusChangeMessage{
-			// 	ServerID:  serverID,
-			// 	OldStatus: oldStatus,
-			// 	NewStatus: entity.ServerStatusOff,
-			// 	Timestamp: time.Now(),
-			// 	Interval:  intervalCheckTime,
-			// })
-		}
+func (s *serverUseCase) StartMaintenance(ctx context.Context, serverID string, windowID uint) (bool, error) {
+	changed, err := s.serverRepo.EnterMaintenance(ctx, serverID, &windowID, time.Now())
+	if err != nil {
+		s.logger.Error("Failed to start server maintenance",
+			zap.String("server_id", serverID),
+			zap.Uint("window_id", windowID),
+			zap.Error(err),
+		)
+		return false, fmt.Errorf("failed to start server maintenance: %w", err)
 	}
-	return nil
+	if changed {
+		s.redisCache.SADD(ctx, maintenanceServersKey, serverID)
+		s.logger.Info("Server maintenance window started",
+			zap.String("server_id", serverID),
+			zap.Uint("window_id", windowID),
+		)
+	}
+	return changed, nil
 }
 
-func (s *serverUseCase) Register(ctx context.Context, req dto.CreateServerRequest) (*dto.AuthResponse, error) {
-	server, err := s.CreateServer(ctx, req)
+func (s *serverUseCase) EndMaintenance(ctx context.Context, serverID string) (bool, error) {
+	changed, err := s.serverRepo.LeaveMaintenance(ctx, serverID, true, time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to register server: %w", err)
+		s.logger.Error("Failed to end server maintenance",
+			zap.String("server_id", serverID),
+			zap.Error(err),
+		)
+		return false, fmt.Errorf("failed to end server maintenance: %w", err)
 	}
+	if changed {
+		s.maintenanceEnded(ctx, serverID)
+		s.logger.Info("Server maintenance window ended", zap.String("server_id", serverID))
+	}
+	return changed, nil
+}
 
-	accessToken, err := s.tokenServices.GenerateServerAccessToken(ctx, server)
+// maintenanceEnded drops the heartbeat window of a server leaving maintenance
+// so its next heartbeat turns it ON instead of only extending the window
+func (s *serverUseCase) maintenanceEnded(ctx context.Context, serverID string) {
+	s.redisCache.SREM(ctx, maintenanceServersKey, serverID)
+	s.redisCache.Del(ctx, fmt.Sprintf("heartbeat:%s", serverID))
+	s.redisCache.ZREM(ctx, heartbeatDeadlinesKey, serverID)
+}
+
+func (s *serverUseCase) RejectServer(ctx context.Context, id uint) error {
+	server, err := s.serverRepo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to generate access token for server",
+		s.logger.Error("Failed to get server by ID",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return domainerrors.ErrServerNotFound
+	}
+	if server.Status != entity.ServerStatusPendingApproval {
+		return domainerrors.ErrServerNotPendingApproval
+	}
+
+	if err := s.tokenRepository.RemoveServerTokensFromWhitelist(ctx, server.ServerID); err != nil {
+		s.logger.Error("Failed to revoke server credentials",
 			zap.String("server_id", server.ServerID),
-			zap.String("server_name", server.ServerName),
 			zap.Error(err),
 		)
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return fmt.Errorf("failed to revoke server credentials: %w", err)
 	}
 
-	refreshToken, err := s.tokenServices.GenerateServerRefreshToken(ctx, server)
-	if err != nil {
-		s.logger.Error("Failed to generate refresh token for server",
+	if err := s.serverRepo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete rejected server",
 			zap.String("server_id", server.ServerID),
-			zap.String("server_name", server.ServerName),
 			zap.Error(err),
 		)
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return fmt.Errorf("failed to reject server: %w", err)
 	}
 
-	return &dto.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-	}, nil
+	s.logger.Info("Server rejected",
+		zap.Uint("id", server.ID),
+		zap.String("server_id", server.ServerID),
+	)
+
+	return nil
 }
 
 func (s *serverUseCase) CreateServer(ctx context.Context, req dto.CreateServerRequest) (*entity.Server, error) {
+	return s.createServer(ctx, req, entity.ServerStatusUndefined)
+}
+
+func (s *serverUseCase) createServer(ctx context.Context, req dto.CreateServerRequest, status entity.ServerStatus) (*entity.Server, error) {
+	if req.ScrapeURL != "" {
+		if err := checkTargetURL(ctx, s.targetPolicy, req.ScrapeURL); err != nil {
+			return nil, err
+		}
+	}
 	if exists, err := s.serverRepo.ExistsByServerIDOrServerName(ctx, req.ServerID, req.ServerName); err != nil {
 		s.logger.Error("Failed to check if server exists",
 			zap.String("server_id", req.ServerID),
@@ -198,12 +1451,21 @@ func (s *serverUseCase) CreateServer(ctx context.Context, req dto.CreateServerRe
 		return nil, fmt.Errorf("server with ID '%s' or name '%s' already exists", req.ServerID, req.ServerName)
 	}
 
+	heartbeatKey, err := generateHeartbeatKey()
+	if err != nil {
+		s.logger.Error("Failed to generate heartbeat key", zap.String("server_id", req.ServerID), zap.Error(err))
+		return nil, fmt.Errorf("failed to generate heartbeat key: %w", err)
+	}
+
 	server := &entity.Server{
 		ServerID:     req.ServerID,
 		ServerName:   req.ServerName,
-		Status:       entity.ServerStatusUndefined,
+		Status:       status,
 		IPv4:         req.IPv4,
+		Tags:         req.Tags,
 		IntervalTime: req.IntervalTime,
+		HeartbeatKey: heartbeatKey,
+		ScrapeURL:    req.ScrapeURL,
 	}
 	if req.Description != "" {
 		server.Description = req.Description
@@ -215,7 +1477,7 @@ func (s *serverUseCase) CreateServer(ctx context.Context, req dto.CreateServerRe
 		server.OS = req.OS
 	}
 
-	err := s.serverRepo.Create(ctx, server)
+	err = s.serverRepo.Create(ctx, server)
 	if err != nil {
 		s.logger.Error("Failed to create server",
 			zap.String("server_id", req.ServerID),
@@ -256,6 +1518,11 @@ func (s *serverUseCase) DeleteServer(ctx context.Context, id uint) error {
 	}
 
 	s.inMemoryCache.Delete("list_server_id")
+	s.redisCache.Del(ctx, fmt.Sprintf("heartbeat_key:%s", server.ServerID))
+	s.redisCache.Del(ctx, fmt.Sprintf("heartbeat:%s", server.ServerID))
+	s.redisCache.ZREM(ctx, heartbeatDeadlinesKey, server.ServerID)
+	s.redisCache.SREM(ctx, maintenanceServersKey, server.ServerID)
+	s.redisCache.SREM(ctx, flappingServersKey, server.ServerID)
 
 	s.logger.Info("Server deleted successfully",
 		zap.Uint("id", server.ID),
@@ -266,6 +1533,37 @@ func (s *serverUseCase) DeleteServer(ctx context.Context, id uint) error {
 	return nil
 }
 
+func (s *serverUseCase) RevokeCredentials(ctx context.Context, id uint) error {
+	server, err := s.serverRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get server by ID",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return domainerrors.ErrServerNotFound
+	}
+
+	if err := s.tokenRepository.RemoveServerTokensFromWhitelist(ctx, server.ServerID); err != nil {
+		s.logger.Error("Failed to revoke server credentials",
+			zap.String("server_id", server.ServerID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to revoke server credentials: %w", err)
+	}
+
+	// The old key must stop signing heartbeats as well
+	if err := s.rotateHeartbeatKey(ctx, server); err != nil {
+		return fmt.Errorf("failed to revoke server credentials: %w", err)
+	}
+
+	s.logger.Info("Server credentials revoked",
+		zap.Uint("id", server.ID),
+		zap.String("server_id", server.ServerID),
+	)
+
+	return nil
+}
+
 func (s *serverUseCase) ImportServers(ctx context.Context, filePath string) (*dto.ImportResult, error) {
 	file, err := excelize.OpenFile(filePath)
 	if err != nil {
@@ -388,6 +1686,15 @@ func (s *serverUseCase) ImportServers(ctx context.Context, filePath string) (*dt
 					successID[server.ServerID] = true
 				}
 				mu.Unlock()
+				// Servers imported as up go OFF unless they start heartbeating
+				for _, server := range successServer {
+					if server.Status.IsUp() {
+						s.setHeartbeatDeadline(ctx, server.ServerID, s.policyFor(server).HeartbeatWindow(server.IntervalTime))
+					}
+					if server.Status == entity.ServerStatusMaintenance {
+						s.redisCache.SADD(ctx, maintenanceServersKey, server.ServerID)
+					}
+				}
 			}
 		})
 	}
@@ -550,10 +1857,15 @@ func (s *serverUseCase) GetServerStats(ctx context.Context) (dto.ServerStatusRes
 
 	// Count by status
 	onlineCount, _ := s.serverRepo.CountByStatus(ctx, entity.ServerStatusOn)
-	stats.OnlineCount = onlineCount
+	stats.DegradedCount, _ = s.serverRepo.CountByStatus(ctx, entity.ServerStatusDegraded)
+	stats.OnlineCount = onlineCount + stats.DegradedCount
 
 	offlineCount, _ := s.serverRepo.CountByStatus(ctx, entity.ServerStatusOff)
-	stats.OfflineCount = offlineCount
+	stats.UnreachableCount, _ = s.serverRepo.CountByStatus(ctx, entity.ServerStatusUnreachable)
+	stats.OfflineCount = offlineCount + stats.UnreachableCount
+
+	stats.MaintenanceCount, _ = s.serverRepo.CountByStatus(ctx, entity.ServerStatusMaintenance)
+	stats.FlappingCount, _ = s.serverRepo.CountByStatus(ctx, entity.ServerStatusFlapping)
 	return stats, nil
 }
 
@@ -565,6 +1877,11 @@ func (s *serverUseCase) ListServers(ctx context.Context, filter dto.ServerFilter
 		IPv4:       filter.IPv4,
 		Location:   filter.Location,
 		Disk:       filter.Disk,
+
+		StatusChangedAfter:  filter.StatusChangedAfter,
+		StatusChangedBefore: filter.StatusChangedBefore,
+		LastSeenAfter:       filter.LastSeenAfter,
+		LastSeenBefore:      filter.LastSeenBefore,
 	}
 
 	queryPagination := query.Pagination{
@@ -632,14 +1949,40 @@ func (s *serverUseCase) UpdateServer(ctx context.Context, id uint, updates dto.U
 	if updates.OS != "" {
 		server.OS = updates.OS
 	}
-	if updates.IntervalTime > 0 {
+	if updates.Tags != nil {
+		server.Tags = updates.Tags
+	}
+	if updates.ScrapeURL != nil {
+		if *updates.ScrapeURL != "" {
+			if err := checkTargetURL(ctx, s.targetPolicy, *updates.ScrapeURL); err != nil {
+				return nil, err
+			}
+		}
+		server.ScrapeURL = *updates.ScrapeURL
+	}
+	intervalChanged := updates.IntervalTime > 0 && updates.IntervalTime != server.IntervalTime
+	if intervalChanged {
 		server.IntervalTime = updates.IntervalTime
+		server.AgentConfigVersion++
+	}
+	if updates.StatusPolicy != nil {
+		policy, err := parseStatusPolicy(*updates.StatusPolicy)
+		if err != nil {
+			return nil, err
+		}
+		server.StatusPolicy = policy
 	}
 
 	if err := s.serverRepo.Update(ctx, server); err != nil {
 		return nil, err
 	}
 
+	if intervalChanged {
+		s.agentConfigChanged(ctx, server)
+	} else if updates.StatusPolicy != nil {
+		s.heartbeatWindowChanged(ctx, server)
+	}
+
 	s.logger.Info("Server updated successfully",
 		zap.Uint("id", server.ID),
 		zap.String("server_id", server.ServerID),
@@ -648,3 +1991,220 @@ func (s *serverUseCase) UpdateServer(ctx context.Context, id uint, updates dto.U
 
 	return server, nil
 }
+
+// GetAgentConfig returns the configuration delivered to the agent on heartbeat
+func (s *serverUseCase) GetAgentConfig(ctx context.Context, serverID string) (*dto.AgentConfig, error) {
+	cacheKey := fmt.Sprintf("agent_config:%s", serverID)
+	var config dto.AgentConfig
+	if err := s.redisCache.Get(ctx, cacheKey, &config); err == nil {
+		return &config, nil
+	}
+
+	server, err := s.GetServerByID(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := dto.FromEntityToAgentConfig(server)
+	if err := s.redisCache.Set(ctx, cacheKey, result, agentConfigCacheTTL); err != nil {
+		s.logger.Warn("Failed to cache agent config",
+			zap.String("server_id", serverID),
+			zap.Error(err),
+		)
+	}
+	return result, nil
+}
+
+func (s *serverUseCase) GetAgentConfigByID(ctx context.Context, id uint) (*dto.AgentConfig, error) {
+	server, err := s.serverRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, domainerrors.ErrServerNotFound
+	}
+	return dto.FromEntityToAgentConfig(server), nil
+}
+
+func (s *serverUseCase) UpdateAgentConfig(ctx context.Context, id uint, req dto.UpdateAgentConfigRequest) (*dto.AgentConfig, error) {
+	server, err := s.serverRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, domainerrors.ErrServerNotFound
+	}
+
+	if req.IntervalTime > 0 {
+		server.IntervalTime = req.IntervalTime
+	}
+	if req.Collectors != nil {
+		server.AgentConfig.Collectors = mergeTags(nil, req.Collectors)
+	}
+	if req.Thresholds != nil {
+		server.AgentConfig.Thresholds = entity.AgentThresholds{
+			CPU:  req.Thresholds.CPU,
+			RAM:  req.Thresholds.RAM,
+			Disk: req.Thresholds.Disk,
+		}
+	}
+	server.AgentConfigVersion++
+
+	if err := s.serverRepo.Update(ctx, server); err != nil {
+		s.logger.Error("Failed to update agent config",
+			zap.Uint("id", id),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to update agent config: %w", err)
+	}
+
+	s.agentConfigChanged(ctx, server)
+
+	s.logger.Info("Agent config updated successfully",
+		zap.String("server_id", server.ServerID),
+		zap.Int64("version", server.AgentConfigVersion),
+	)
+
+	return dto.FromEntityToAgentConfig(server), nil
+}
+
+// agentConfigChanged drops the cached config and moves the expiry of a live
+// heartbeat to the new interval, so the server is not marked offline early
+// or kept online too long
+func (s *serverUseCase) agentConfigChanged(ctx context.Context, server *entity.Server) {
+	if err := s.redisCache.Del(ctx, fmt.Sprintf("agent_config:%s", server.ServerID)); err != nil {
+		s.logger.Warn("Failed to delete cached agent config",
+			zap.String("server_id", server.ServerID),
+			zap.Error(err),
+		)
+	}
+
+	s.heartbeatWindowChanged(ctx, server)
+}
+
+// heartbeatWindowChanged moves the expiry of a live heartbeat to the current
+// interval and status policy of the server
+func (s *serverUseCase) heartbeatWindowChanged(ctx context.Context, server *entity.Server) {
+	cacheKey := fmt.Sprintf("heartbeat:%s", server.ServerID)
+	var state heartbeatState
+	if err := s.redisCache.Get(ctx, cacheKey, &state); err != nil {
+		return
+	}
+	window := s.policyFor(server).HeartbeatWindow(server.IntervalTime)
+	if err := s.redisCache.Set(ctx, cacheKey, heartbeatState{Window: window}, window); err != nil {
+		s.logger.Warn("Failed to update heartbeat window",
+			zap.String("server_id", server.ServerID),
+			zap.Error(err),
+		)
+	}
+	s.setHeartbeatDeadline(ctx, server.ServerID, window)
+}
+
+// mergeTags returns the union of both tag sets, keeping the order of first appearance
+func mergeTags(base []string, extra []string) []string {
+	seen := make(map[string]struct{}, len(base)+len(extra))
+	tags := make([]string, 0, len(base)+len(extra))
+	for _, tag := range append(append([]string{}, base...), extra...) {
+		if _, ok := seen[tag]; ok || tag == "" {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func (s *serverUseCase) GetStatusPolicy(ctx context.Context, serverID string) (entity.StatusPolicy, error) {
+	server, err := s.GetServerByID(ctx, serverID)
+	if err != nil {
+		return entity.StatusPolicy{}, err
+	}
+	return s.policyFor(server), nil
+}
+
+// parseStatusPolicy returns the overrides of a request, nil when it has none
+func parseStatusPolicy(req dto.StatusPolicy) (*entity.StatusPolicy, error) {
+	policy := entity.StatusPolicy{
+		MissedIntervals:    req.MissedIntervals,
+		GraceMultiplier:    req.GraceMultiplier,
+		RecoveryHeartbeats: req.RecoveryHeartbeats,
+		OnlineThreshold:    req.OnlineThreshold,
+		FlapThreshold:      req.FlapThreshold,
+	}
+	if req.FlapWindow != "" {
+		window, err := time.ParseDuration(req.FlapWindow)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("%w: invalid flap_window %q", domainerrors.ErrInvalidInput, req.FlapWindow)
+		}
+		policy.FlapWindow = window
+	}
+	if policy.IsZero() {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+// policyFor is the global status policy with the overrides of the server
+func (s *serverUseCase) policyFor(server *entity.Server) entity.StatusPolicy {
+	return s.statusPolicy.Override(server.StatusPolicy)
+}
+
+// validateMetrics checks what the binding tags cannot express: extended fields
+// require schema version 2 and custom gauge names must be safe to query by
+func validateMetrics(metrics dto.MetricsRequest) error {
+	if metrics.Version() < dto.MetricsSchemaV2 && metrics.HasExtendedFields() {
+		return fmt.Errorf("%w: extended fields require schema_version %d", domainerrors.ErrInvalidMetrics, dto.MetricsSchemaV2)
+	}
+	for name := range metrics.Custom {
+		if !gaugeNamePattern.MatchString(name) {
+			return fmt.Errorf("%w: invalid custom gauge name %q", domainerrors.ErrInvalidMetrics, name)
+		}
+	}
+	return nil
+}
+
+func setErrors(errs []error, indexes []int, err error) {
+	for _, i := range indexes {
+		errs[i] = err
+	}
+}
+
+// GetHeartbeatKey returns the key signing the UDP heartbeats of a server
+func (s *serverUseCase) GetHeartbeatKey(ctx context.Context, serverID string) (string, error) {
+	cacheKey := fmt.Sprintf("heartbeat_key:%s", serverID)
+	var heartbeatKey string
+	if err := s.redisCache.Get(ctx, cacheKey, &heartbeatKey); err == nil && heartbeatKey != "" {
+		return heartbeatKey, nil
+	}
+
+	server, err := s.serverRepo.GetByServerID(ctx, serverID)
+	if err != nil {
+		return "", err
+	}
+	if server.HeartbeatKey == "" {
+		return "", domainerrors.ErrInvalidHeartbeatSignature
+	}
+
+	if err := s.redisCache.Set(ctx, cacheKey, server.HeartbeatKey, heartbeatKeyCacheTTL); err != nil {
+		s.logger.Warn("Failed to cache heartbeat key", zap.String("server_id", serverID), zap.Error(err))
+	}
+	return server.HeartbeatKey, nil
+}
+
+func (s *serverUseCase) rotateHeartbeatKey(ctx context.Context, server *entity.Server) error {
+	heartbeatKey, err := generateHeartbeatKey()
+	if err != nil {
+		s.logger.Error("Failed to generate heartbeat key", zap.String("server_id", server.ServerID), zap.Error(err))
+		return fmt.Errorf("failed to generate heartbeat key: %w", err)
+	}
+
+	server.HeartbeatKey = heartbeatKey
+	if err := s.serverRepo.Update(ctx, server); err != nil {
+		s.logger.Error("Failed to save heartbeat key", zap.String("server_id", server.ServerID), zap.Error(err))
+		return fmt.Errorf("failed to save heartbeat key: %w", err)
+	}
+	s.redisCache.Del(ctx, fmt.Sprintf("heartbeat_key:%s", server.ServerID))
+	return nil
+}
+
+func generateHeartbeatKey() (string, error) {
+	buf := make([]byte, heartbeatKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
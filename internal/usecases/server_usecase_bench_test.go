@@ -0,0 +1,292 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+	"github.com/th1enq/server_management_system/internal/domain/repository"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+// Every Redis command and status write is a network round trip in
+// production, the fakes count them so the benchmarks report the load a tick
+// puts on Redis and on the database next to the CPU time.
+
+type benchCache struct {
+	cache.CacheClient
+	mu     sync.Mutex
+	values map[string][]byte
+	zset   map[string]float64
+	calls  int
+}
+
+func newBenchCache() *benchCache {
+	return &benchCache{values: make(map[string][]byte), zset: make(map[string]float64)}
+}
+
+func (c *benchCache) Set(ctx context.Context, key string, data any, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	c.values[key] = b
+	return nil
+}
+
+func (c *benchCache) Get(ctx context.Context, key string, dest any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	b, ok := c.values[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(b, dest)
+}
+
+func (c *benchCache) Del(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	delete(c.values, key)
+	return nil
+}
+
+func (c *benchCache) SMEMBERS(ctx context.Context, key string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return nil, nil
+}
+
+func (c *benchCache) SISMEMBER(ctx context.Context, key string, member string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return false, nil
+}
+
+func (c *benchCache) ZADD(ctx context.Context, key string, score float64, member string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	c.zset[member] = score
+	return nil
+}
+
+func (c *benchCache) ZADDNX(ctx context.Context, key string, score float64, member string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if _, ok := c.zset[member]; !ok {
+		c.zset[member] = score
+	}
+	return nil
+}
+
+func (c *benchCache) ZREM(ctx context.Context, key string, members ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	for _, member := range members {
+		delete(c.zset, member)
+	}
+	return nil
+}
+
+func (c *benchCache) ZSCORE(ctx context.Context, key string, member string) (float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	score, ok := c.zset[member]
+	if !ok {
+		return 0, cache.ErrCacheMiss
+	}
+	return score, nil
+}
+
+func (c *benchCache) ZPopByScore(ctx context.Context, key string, max float64, count int64) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	var members []string
+	for member, score := range c.zset {
+		if score <= max {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return c.zset[members[i]] < c.zset[members[j]] })
+	if int64(len(members)) > count {
+		members = members[:count]
+	}
+	for _, member := range members {
+		delete(c.zset, member)
+	}
+	return members, nil
+}
+
+type benchServerRepository struct {
+	repository.ServerRepository
+	mu     sync.Mutex
+	ids    []string
+	status map[string]entity.ServerStatus
+	writes int
+}
+
+func (r *benchServerRepository) GetServerIDs(ctx context.Context) ([]string, error) {
+	return r.ids, nil
+}
+
+func (r *benchServerRepository) GetByServerID(ctx context.Context, serverID string) (*entity.Server, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &entity.Server{ServerID: serverID, Status: r.status[serverID], IntervalTime: 15}, nil
+}
+
+func (r *benchServerRepository) ListByStatus(ctx context.Context, status entity.ServerStatus) ([]*entity.Server, error) {
+	return nil, nil
+}
+
+func (r *benchServerRepository) UpdateStatus(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes++
+	changed := r.status[serverID] != status
+	r.status[serverID] = status
+	return changed, nil
+}
+
+// benchDependencyRepo is a fleet without dependencies
+type benchDependencyRepo struct {
+	repository.ServerDependencyRepository
+}
+
+func (benchDependencyRepo) ListParents(ctx context.Context, serverID string) ([]*entity.Server, error) {
+	return nil, nil
+}
+
+func (benchDependencyRepo) ListChildren(ctx context.Context, serverID string) ([]*entity.Server, error) {
+	return nil, nil
+}
+
+// legacyRefreshStatus is RefreshStatus before heartbeat deadlines: one GET per
+// server and a status write for every server without a heartbeat
+func legacyRefreshStatus(ctx context.Context, s *serverUseCase) error {
+	serverIDs, err := s.GetServerIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, serverID := range serverIDs {
+		var state heartbeatState
+		if err := s.redisCache.Get(ctx, fmt.Sprintf("heartbeat:%s", serverID), &state); err != nil {
+			if _, err := s.serverRepo.UpdateStatus(ctx, serverID, entity.ServerStatusOff, time.Now()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// benchFleet builds a fleet where 5% of the servers are already offline and
+// 1% expire before every tick, the rest keep sending heartbeats
+type benchFleet struct {
+	useCase *serverUseCase
+	cache   *benchCache
+	repo    *benchServerRepository
+	live    []string
+	expiry  []string
+}
+
+func newBenchFleet(size int) *benchFleet {
+	logger := zap.NewNop()
+	redisCache := newBenchCache()
+	repo := &benchServerRepository{status: make(map[string]entity.ServerStatus, size)}
+	f := &benchFleet{cache: redisCache, repo: repo}
+
+	for i := 0; i < size; i++ {
+		serverID := fmt.Sprintf("server-%05d", i)
+		repo.ids = append(repo.ids, serverID)
+		switch {
+		case i%20 == 0:
+			repo.status[serverID] = entity.ServerStatusOff
+		case i%100 == 1:
+			f.expiry = append(f.expiry, serverID)
+		default:
+			f.live = append(f.live, serverID)
+		}
+	}
+
+	f.useCase = NewServerUseCase(repo, nil, nil, benchDependencyRepo{}, nil, nil, nil, nil, nil, nil, cache.NewInMemoryCache(logger), redisCache, configs.StatusPolicy{}, logger).(*serverUseCase)
+	f.useCase.deadlinesSeeded.Store(true)
+
+	future := float64(time.Now().Add(time.Hour).UnixMilli())
+	for _, serverID := range f.live {
+		redisCache.values[fmt.Sprintf("heartbeat:%s", serverID)] = []byte(`{"window":22500000000}`)
+		redisCache.zset[serverID] = future
+	}
+	return f
+}
+
+// expire lets the heartbeat window of the expiring servers lapse
+func (f *benchFleet) expire() {
+	past := float64(time.Now().Add(-time.Second).UnixMilli())
+	for _, serverID := range f.expiry {
+		delete(f.cache.values, fmt.Sprintf("heartbeat:%s", serverID))
+		f.cache.zset[serverID] = past
+		f.repo.status[serverID] = entity.ServerStatusOn
+	}
+	f.cache.calls = 0
+	f.repo.writes = 0
+}
+
+func benchmarkRefreshStatus(b *testing.B, size int, refresh func(context.Context, *serverUseCase) error) {
+	ctx := context.Background()
+	f := newBenchFleet(size)
+	var calls, writes int
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		f.expire()
+		b.StartTimer()
+
+		if err := refresh(ctx, f.useCase); err != nil {
+			b.Fatal(err)
+		}
+
+		calls += f.cache.calls
+		writes += f.repo.writes
+	}
+
+	for _, serverID := range f.expiry {
+		if f.repo.status[serverID] != entity.ServerStatusOff {
+			b.Fatalf("server %s was not marked offline", serverID)
+		}
+	}
+	b.ReportMetric(float64(calls)/float64(b.N), "redis_calls/op")
+	b.ReportMetric(float64(writes)/float64(b.N), "status_writes/op")
+}
+
+func BenchmarkRefreshStatus(b *testing.B) {
+	for _, size := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("legacy/servers=%d", size), func(b *testing.B) {
+			benchmarkRefreshStatus(b, size, legacyRefreshStatus)
+		})
+		b.Run(fmt.Sprintf("deadlines/servers=%d", size), func(b *testing.B) {
+			benchmarkRefreshStatus(b, size, func(ctx context.Context, s *serverUseCase) error {
+				return s.RefreshStatus(ctx)
+			})
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/th1enq/server_management_system/internal/domain/entity"
+)
+
+// failingServerRepository fails the status updates of some servers, like a
+// database timing out partway through a batch
+type failingServerRepository struct {
+	*benchServerRepository
+	fail map[string]bool
+}
+
+func (r *failingServerRepository) UpdateStatus(ctx context.Context, serverID string, status entity.ServerStatus, timestamp time.Time) (bool, error) {
+	if r.fail[serverID] {
+		return false, errors.New("database timeout")
+	}
+	return r.benchServerRepository.UpdateStatus(ctx, serverID, status, timestamp)
+}
+
+func TestRefreshStatusKeepsBatchOnFailure(t *testing.T) {
+	ctx := context.Background()
+	f := newBenchFleet(1000)
+	f.expire()
+	require.Greater(t, len(f.expiry), 3)
+
+	failing := map[string]bool{f.expiry[1]: true, f.expiry[len(f.expiry)-1]: true}
+	f.useCase.serverRepo = &failingServerRepository{benchServerRepository: f.repo, fail: failing}
+
+	err := f.useCase.RefreshStatus(ctx)
+	require.Error(t, err)
+	for serverID := range failing {
+		assert.ErrorContains(t, err, serverID)
+	}
+
+	for _, serverID := range f.expiry {
+		if failing[serverID] {
+			assert.Equal(t, entity.ServerStatusOn, f.repo.status[serverID], serverID)
+			_, ok := f.cache.zset[serverID]
+			assert.True(t, ok, "deadline of %s was not put back", serverID)
+			continue
+		}
+		assert.Equal(t, entity.ServerStatusOff, f.repo.status[serverID], serverID)
+		_, ok := f.cache.zset[serverID]
+		assert.False(t, ok, "deadline of %s was put back", serverID)
+	}
+
+	// The next tick retries the failed servers once the database is back
+	f.useCase.serverRepo = f.repo
+	require.NoError(t, f.useCase.RefreshStatus(ctx))
+	for serverID := range failing {
+		assert.Equal(t, entity.ServerStatusOff, f.repo.status[serverID], serverID)
+	}
+}
+
+// newTopology builds servers from their status and the parents they depend on
+func newTopology(statuses map[string]entity.ServerStatus, parents map[string][]string) (*statusRepo, dependencyGraph) {
+	repo := newStatusRepo()
+	for serverID, status := range statuses {
+		repo.servers[serverID] = &entity.Server{ServerID: serverID, Status: status}
+	}
+	return repo, dependencyGraph{repo: repo, parents: parents}
+}
+
+func TestRootCause(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses map[string]entity.ServerStatus
+		parents  map[string][]string
+		want     string
+	}{
+		{
+			name:     "no parents",
+			statuses: map[string]entity.ServerStatus{"web": entity.ServerStatusOff},
+			want:     "",
+		},
+		{
+			name:     "parents up",
+			statuses: map[string]entity.ServerStatus{"web": entity.ServerStatusOff, "switch": entity.ServerStatusOn, "db": entity.ServerStatusDegraded},
+			parents:  map[string][]string{"web": {"switch", "db"}},
+			want:     "",
+		},
+		{
+			name:     "parent off",
+			statuses: map[string]entity.ServerStatus{"web": entity.ServerStatusOff, "switch": entity.ServerStatusOff},
+			parents:  map[string][]string{"web": {"switch"}},
+			want:     "switch",
+		},
+		{
+			name:     "second parent off",
+			statuses: map[string]entity.ServerStatus{"web": entity.ServerStatusOff, "db": entity.ServerStatusOn, "switch": entity.ServerStatusOff},
+			parents:  map[string][]string{"web": {"db", "switch"}},
+			want:     "switch",
+		},
+		{
+			name: "unreachable parent, down grandparent",
+			statuses: map[string]entity.ServerStatus{
+				"web": entity.ServerStatusOff, "switch": entity.ServerStatusUnreachable, "core": entity.ServerStatusOff,
+			},
+			parents: map[string][]string{"web": {"switch"}, "switch": {"core"}},
+			want:    "core",
+		},
+		{
+			name: "unreachable chain",
+			statuses: map[string]entity.ServerStatus{
+				"web": entity.ServerStatusOff, "rack": entity.ServerStatusUnreachable,
+				"switch": entity.ServerStatusUnreachable, "core": entity.ServerStatusOff,
+			},
+			parents: map[string][]string{"web": {"rack"}, "rack": {"switch"}, "switch": {"core"}},
+			want:    "core",
+		},
+		{
+			// Its own root cause came back, the parent is the best guess
+			name: "unreachable parent, grandparents up",
+			statuses: map[string]entity.ServerStatus{
+				"web": entity.ServerStatusOff, "switch": entity.ServerStatusUnreachable, "core": entity.ServerStatusOn,
+			},
+			parents: map[string][]string{"web": {"switch"}, "switch": {"core"}},
+			want:    "switch",
+		},
+		{
+			name:     "parent in maintenance",
+			statuses: map[string]entity.ServerStatus{"web": entity.ServerStatusOff, "switch": entity.ServerStatusMaintenance},
+			parents:  map[string][]string{"web": {"switch"}},
+			want:     "",
+		},
+		{
+			name:     "parent flapping",
+			statuses: map[string]entity.ServerStatus{"web": entity.ServerStatusOff, "switch": entity.ServerStatusFlapping},
+			parents:  map[string][]string{"web": {"switch"}},
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, graph := newTopology(tt.statuses, tt.parents)
+			s := newStatusUseCase(repo, newMemCache(), nil)
+			s.dependencyRepo = graph
+
+			assert.Equal(t, tt.want, s.rootCause(context.Background(), "web"))
+		})
+	}
+}
+
+func TestCascadeUnreachable(t *testing.T) {
+	// core
+	// ├── switch ── web
+	// │         └── cache
+	// ├── db
+	// └── backup
+	parents := map[string][]string{
+		"switch": {"core"},
+		"db":     {"core"},
+		"backup": {"core"},
+		"web":    {"switch"},
+		"cache":  {"switch"},
+	}
+
+	tests := []struct {
+		name       string
+		statuses   map[string]entity.ServerStatus
+		want       map[string]entity.ServerStatus
+		rootCauses map[string]string
+	}{
+		{
+			name: "off descendants follow",
+			statuses: map[string]entity.ServerStatus{
+				"core": entity.ServerStatusOff, "switch": entity.ServerStatusOff, "web": entity.ServerStatusOff,
+				"cache": entity.ServerStatusOff, "db": entity.ServerStatusOff, "backup": entity.ServerStatusOff,
+			},
+			want: map[string]entity.ServerStatus{
+				"core": entity.ServerStatusOff, "switch": entity.ServerStatusUnreachable, "web": entity.ServerStatusUnreachable,
+				"cache": entity.ServerStatusUnreachable, "db": entity.ServerStatusUnreachable, "backup": entity.ServerStatusUnreachable,
+			},
+			rootCauses: map[string]string{"switch": "core", "web": "core", "cache": "core", "db": "core", "backup": "core"},
+		},
+		{
+			// Servers still up follow when their own heartbeat window closes
+			name: "up and held servers stay",
+			statuses: map[string]entity.ServerStatus{
+				"core": entity.ServerStatusOff, "switch": entity.ServerStatusOn, "web": entity.ServerStatusOff,
+				"cache": entity.ServerStatusOff, "db": entity.ServerStatusMaintenance, "backup": entity.ServerStatusFlapping,
+			},
+			want: map[string]entity.ServerStatus{
+				"core": entity.ServerStatusOff, "switch": entity.ServerStatusOn, "web": entity.ServerStatusOff,
+				"cache": entity.ServerStatusOff, "db": entity.ServerStatusMaintenance, "backup": entity.ServerStatusFlapping,
+			},
+			rootCauses: map[string]string{},
+		},
+		{
+			name: "cascade stops at an up server",
+			statuses: map[string]entity.ServerStatus{
+				"core": entity.ServerStatusOff, "switch": entity.ServerStatusOff, "web": entity.ServerStatusDegraded,
+				"cache": entity.ServerStatusOff, "db": entity.ServerStatusOn, "backup": entity.ServerStatusOff,
+			},
+			want: map[string]entity.ServerStatus{
+				"core": entity.ServerStatusOff, "switch": entity.ServerStatusUnreachable, "web": entity.ServerStatusDegraded,
+				"cache": entity.ServerStatusUnreachable, "db": entity.ServerStatusOn, "backup": entity.ServerStatusUnreachable,
+			},
+			rootCauses: map[string]string{"switch": "core", "cache": "core", "backup": "core"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, graph := newTopology(tt.statuses, parents)
+			s := newStatusUseCase(repo, newMemCache(), nil)
+			s.dependencyRepo = graph
+
+			s.cascadeUnreachable(context.Background(), "core", "core", time.Now())
+
+			got := make(map[string]entity.ServerStatus)
+			for serverID, server := range repo.servers {
+				got[serverID] = server.Status
+			}
+			assert.Equal(t, tt.want, got)
+			if len(tt.rootCauses) == 0 {
+				assert.Empty(t, repo.rootCauses)
+			} else {
+				assert.Equal(t, tt.rootCauses, repo.rootCauses)
+			}
+		})
+	}
+}
+
+// markDown picks OFF or UNREACHABLE from the parents and cascades either way
+func TestMarkDown(t *testing.T) {
+	statuses := map[string]entity.ServerStatus{
+		"core": entity.ServerStatusOff, "switch": entity.ServerStatusOn, "web": entity.ServerStatusOff,
+	}
+	parents := map[string][]string{"switch": {"core"}, "web": {"switch"}}
+	repo, graph := newTopology(statuses, parents)
+	s := newStatusUseCase(repo, newMemCache(), nil)
+	s.dependencyRepo = graph
+
+	changed, err := s.markDown(context.Background(), "switch", time.Now())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, entity.ServerStatusUnreachable, repo.servers["switch"].Status)
+	assert.Equal(t, entity.ServerStatusUnreachable, repo.servers["web"].Status)
+	assert.Equal(t, map[string]string{"switch": "core", "web": "core"}, repo.rootCauses)
+}
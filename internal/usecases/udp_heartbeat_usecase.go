@@ -0,0 +1,75 @@
+package usecases
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/th1enq/server_management_system/internal/configs"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+const defaultHeartbeatReplayWindow = 30 * time.Second
+
+type UDPHeartbeatUseCase interface {
+	// ProcessHeartbeat verifies the signature and freshness of a heartbeat,
+	// then handles it like a heartbeat received over HTTP
+	ProcessHeartbeat(ctx context.Context, heartbeat dto.SignedHeartbeat) error
+}
+
+type udpHeartbeatUseCase struct {
+	serverUseCase ServerUseCase
+	redisCache    cache.CacheClient
+	replayWindow  time.Duration
+	logger        *zap.Logger
+}
+
+func NewUDPHeartbeatUseCase(serverUseCase ServerUseCase, redisCache cache.CacheClient, config configs.UDPHeartbeat, logger *zap.Logger) UDPHeartbeatUseCase {
+	replayWindow := config.ReplayWindow
+	if replayWindow <= 0 {
+		replayWindow = defaultHeartbeatReplayWindow
+	}
+	return &udpHeartbeatUseCase{
+		serverUseCase: serverUseCase,
+		redisCache:    redisCache,
+		replayWindow:  replayWindow,
+		logger:        logger,
+	}
+}
+
+func (u *udpHeartbeatUseCase) ProcessHeartbeat(ctx context.Context, heartbeat dto.SignedHeartbeat) error {
+	metrics := heartbeat.Metrics
+	if skew := time.Since(metrics.Timestamp); skew > u.replayWindow || skew < -u.replayWindow {
+		return domainerrors.ErrHeartbeatReplayed
+	}
+
+	key, err := u.serverUseCase.GetHeartbeatKey(ctx, metrics.ServerID)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(heartbeat.Payload)
+	if !hmac.Equal(mac.Sum(nil), heartbeat.Signature) {
+		return domainerrors.ErrInvalidHeartbeatSignature
+	}
+
+	// Only checked once the signature is valid, so forged datagrams cannot
+	// burn the timestamps of a server. Older timestamps are rejected by the
+	// window, so a key outliving it is enough.
+	replayKey := fmt.Sprintf("udp_heartbeat:%s:%d", metrics.ServerID, metrics.Timestamp.UnixMilli())
+	first, err := u.redisCache.SetNX(ctx, replayKey, 1, 2*u.replayWindow)
+	if err != nil {
+		u.logger.Error("Failed to check heartbeat replay", zap.String("server_id", metrics.ServerID), zap.Error(err))
+		return fmt.Errorf("failed to check heartbeat replay: %w", err)
+	}
+	if !first {
+		return domainerrors.ErrHeartbeatReplayed
+	}
+
+	return u.serverUseCase.ProcessMetrics(ctx, metrics)
+}
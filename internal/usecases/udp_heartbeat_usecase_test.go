@@ -0,0 +1,161 @@
+package usecases
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/th1enq/server_management_system/internal/configs"
+	domainerrors "github.com/th1enq/server_management_system/internal/domain/errors"
+	"github.com/th1enq/server_management_system/internal/dto"
+	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
+	"go.uber.org/zap"
+)
+
+type heartbeatServerUseCase struct {
+	ServerUseCase
+	keys      map[string]string
+	processed []dto.MetricsRequest
+}
+
+func (s *heartbeatServerUseCase) GetHeartbeatKey(ctx context.Context, serverID string) (string, error) {
+	key, ok := s.keys[serverID]
+	if !ok {
+		return "", domainerrors.ErrServerNotFound
+	}
+	return key, nil
+}
+
+func (s *heartbeatServerUseCase) ProcessMetrics(ctx context.Context, metrics dto.MetricsRequest) error {
+	s.processed = append(s.processed, metrics)
+	return nil
+}
+
+type replayCache struct {
+	cache.CacheClient
+	seen map[string]bool
+	err  error
+}
+
+func (c *replayCache) SetNX(ctx context.Context, key string, data any, ttl time.Duration) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	if c.seen[key] {
+		return false, nil
+	}
+	c.seen[key] = true
+	return true, nil
+}
+
+func signHeartbeat(key string, metrics dto.MetricsRequest) dto.SignedHeartbeat {
+	payload := []byte(fmt.Sprintf("SMS1 %s %d %d %d %d",
+		metrics.ServerID, metrics.Timestamp.UnixMilli(), metrics.CPU, metrics.RAM, metrics.Disk))
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(payload)
+	return dto.SignedHeartbeat{Metrics: metrics, Payload: payload, Signature: mac.Sum(nil)}
+}
+
+func TestProcessHeartbeat(t *testing.T) {
+	now := time.Now()
+	metrics := func(serverID string, timestamp time.Time) dto.MetricsRequest {
+		return dto.MetricsRequest{ServerID: serverID, CPU: 12, RAM: 48, Disk: 19, Timestamp: timestamp}
+	}
+	tampered := signHeartbeat("key-01", metrics("server-01", now))
+	tampered.Metrics.CPU = 99
+	tampered.Payload = []byte(fmt.Sprintf("SMS1 server-01 %d 99 48 19", now.UnixMilli()))
+
+	tests := []struct {
+		name      string
+		heartbeat dto.SignedHeartbeat
+		seen      bool
+		cacheErr  error
+		wantErr   error
+	}{
+		{
+			name:      "valid heartbeat",
+			heartbeat: signHeartbeat("key-01", metrics("server-01", now)),
+		},
+		{
+			name:      "within the clock skew",
+			heartbeat: signHeartbeat("key-01", metrics("server-01", now.Add(20*time.Second))),
+		},
+		{
+			name:      "wrong key",
+			heartbeat: signHeartbeat("key-02", metrics("server-01", now)),
+			wantErr:   domainerrors.ErrInvalidHeartbeatSignature,
+		},
+		{
+			name:      "tampered payload",
+			heartbeat: tampered,
+			wantErr:   domainerrors.ErrInvalidHeartbeatSignature,
+		},
+		{
+			name:      "unknown server",
+			heartbeat: signHeartbeat("key-01", metrics("server-02", now)),
+			wantErr:   domainerrors.ErrServerNotFound,
+		},
+		{
+			name:      "too old",
+			heartbeat: signHeartbeat("key-01", metrics("server-01", now.Add(-time.Minute))),
+			wantErr:   domainerrors.ErrHeartbeatReplayed,
+		},
+		{
+			name:      "too far in the future",
+			heartbeat: signHeartbeat("key-01", metrics("server-01", now.Add(time.Minute))),
+			wantErr:   domainerrors.ErrHeartbeatReplayed,
+		},
+		{
+			name:      "replayed",
+			heartbeat: signHeartbeat("key-01", metrics("server-01", now)),
+			seen:      true,
+			wantErr:   domainerrors.ErrHeartbeatReplayed,
+		},
+		{
+			name:      "replay check unavailable",
+			heartbeat: signHeartbeat("key-01", metrics("server-01", now)),
+			cacheErr:  errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers := &heartbeatServerUseCase{keys: map[string]string{"server-01": "key-01"}}
+			redis := &replayCache{seen: make(map[string]bool), err: tt.cacheErr}
+			if tt.seen {
+				m := tt.heartbeat.Metrics
+				redis.seen[fmt.Sprintf("udp_heartbeat:%s:%d", m.ServerID, m.Timestamp.UnixMilli())] = true
+			}
+			u := NewUDPHeartbeatUseCase(servers, redis, configs.UDPHeartbeat{ReplayWindow: 30 * time.Second}, zap.NewNop())
+
+			err := u.ProcessHeartbeat(context.Background(), tt.heartbeat)
+			switch {
+			case tt.cacheErr != nil:
+				assert.ErrorIs(t, err, tt.cacheErr)
+				assert.Empty(t, servers.processed)
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, servers.processed)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, []dto.MetricsRequest{tt.heartbeat.Metrics}, servers.processed)
+			}
+		})
+	}
+}
+
+func TestProcessHeartbeatRejectsSecondDelivery(t *testing.T) {
+	servers := &heartbeatServerUseCase{keys: map[string]string{"server-01": "key-01"}}
+	redis := &replayCache{seen: make(map[string]bool)}
+	u := NewUDPHeartbeatUseCase(servers, redis, configs.UDPHeartbeat{}, zap.NewNop())
+
+	heartbeat := signHeartbeat("key-01", dto.MetricsRequest{ServerID: "server-01", CPU: 1, RAM: 2, Disk: 3, Timestamp: time.Now()})
+	assert.NoError(t, u.ProcessHeartbeat(context.Background(), heartbeat))
+	assert.ErrorIs(t, u.ProcessHeartbeat(context.Background(), heartbeat), domainerrors.ErrHeartbeatReplayed)
+	assert.Len(t, servers.processed, 1)
+}
@@ -8,4 +8,11 @@ var WireSet = wire.NewSet(
 	NewHealthCheckUseCase,
 	NewReportUseCase,
 	NewAuthUseCase,
+	NewEnrollmentTokenUseCase,
+	NewAlertRuleUseCase,
+	NewRemoteWriteUseCase,
+	NewUDPHeartbeatUseCase,
+	NewScrapeUseCase,
+	NewProbeUseCase,
+	NewMaintenanceWindowUseCase,
 )
@@ -11,11 +11,13 @@ import (
 	"github.com/th1enq/server_management_system/internal/app"
 	"github.com/th1enq/server_management_system/internal/configs"
 	"github.com/th1enq/server_management_system/internal/delivery"
+	"github.com/th1enq/server_management_system/internal/delivery/consumer"
 	"github.com/th1enq/server_management_system/internal/delivery/http"
 	"github.com/th1enq/server_management_system/internal/delivery/http/controllers"
 	"github.com/th1enq/server_management_system/internal/delivery/http/presenters"
 	"github.com/th1enq/server_management_system/internal/delivery/http/routes"
 	"github.com/th1enq/server_management_system/internal/delivery/middleware"
+	"github.com/th1enq/server_management_system/internal/delivery/udp"
 	"github.com/th1enq/server_management_system/internal/infrastructure"
 	"github.com/th1enq/server_management_system/internal/infrastructure/cache"
 	"github.com/th1enq/server_management_system/internal/infrastructure/database"
@@ -68,12 +70,31 @@ func InitializeStandardServer(configFilePath configs.ConfigFilePath) (*app.Appli
 	authMiddleware := middleware.NewAuthMiddleware(authUseCase, logger)
 	authRouter := routes.NewAuthRouter(authController, authMiddleware)
 	serverRepository := repositories.NewServerRepository(databaseClient)
+	tsdb := config.TSDB
+	metricsRepository, err := repositories.NewMetricsRepository(databaseClient, tsdb)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
 	excelizeService := services.NewExcelizeService()
 	inMemoryCache := cache.NewInMemoryCache(logger)
-	serverUseCase := usecases.NewServerUseCase(serverRepository, tokenServices, excelizeService, inMemoryCache, cacheClient, logger)
+	enrollmentTokenRepository := repositories.NewEnrollmentTokenRepository(databaseClient)
+	alertRuleRepository := repositories.NewAlertRuleRepository(databaseClient)
+	alertRuleUseCase := usecases.NewAlertRuleUseCase(alertRuleRepository, serverRepository, inMemoryCache, logger)
+	serverStatusEventRepository := repositories.NewServerStatusEventRepository(databaseClient)
+	serverDependencyRepository := repositories.NewServerDependencyRepository(databaseClient)
+	targetPolicy := config.TargetPolicy
+	servicesTargetPolicy, err := services.NewTargetPolicy(targetPolicy)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	statusPolicy := config.StatusPolicy
+	serverUseCase := usecases.NewServerUseCase(serverRepository, metricsRepository, serverStatusEventRepository, serverDependencyRepository, tokenRepository, enrollmentTokenRepository, alertRuleUseCase, tokenServices, excelizeService, servicesTargetPolicy, inMemoryCache, cacheClient, statusPolicy, logger)
 	serverPresenter := presenters.NewServerPresenter()
 	serverController := controllers.NewServerController(serverUseCase, serverPresenter, logger)
-	serverRouter := routes.NewServerRouter(serverController, authMiddleware)
+	agentAuthMiddleware := middleware.NewAgentAuthMiddleware(authUseCase, logger)
+	serverRouter := routes.NewServerRouter(serverController, authMiddleware, agentAuthMiddleware)
 	email := config.Email
 	elasticSearch := config.Elasticsearch
 	iesClient, err := search.LoadElasticSearch(elasticSearch, logger)
@@ -81,7 +102,9 @@ func InitializeStandardServer(configFilePath configs.ConfigFilePath) (*app.Appli
 		cleanup()
 		return nil, nil, err
 	}
-	healthCheckUseCase := usecases.NewHealthCheckUseCase(iesClient, serverUseCase, logger)
+	maintenanceWindowRepository := repositories.NewMaintenanceWindowRepository(databaseClient)
+	maintenanceWindowUseCase := usecases.NewMaintenanceWindowUseCase(maintenanceWindowRepository, serverRepository, serverUseCase, logger)
+	healthCheckUseCase := usecases.NewHealthCheckUseCase(iesClient, serverStatusEventRepository, serverUseCase, maintenanceWindowUseCase, logger)
 	reportUseCase := usecases.NewReportUseCase(email, healthCheckUseCase, logger)
 	reportPresenter := presenters.NewReportPresenter()
 	reportController := controllers.NewReportController(reportUseCase, reportPresenter, logger)
@@ -89,26 +112,68 @@ func InitializeStandardServer(configFilePath configs.ConfigFilePath) (*app.Appli
 	userPresenter := presenters.NewUserPresenter()
 	userController := controllers.NewUserController(userUseCase, userPresenter, logger)
 	userRouter := routes.NewUserRouter(userController, authMiddleware)
-	jobScheduler := scheduler.NewJobScheduler(logger)
+	leaderElection := config.LeaderElection
+	leaderElector := scheduler.NewRedisLeaderElector(cacheClient, leaderElection, logger)
+	jobScheduler := scheduler.NewJobScheduler(leaderElector, logger)
 	cron := config.Cron
 	dailyReportTask := tasks.NewDailyReportTask(reportUseCase, cron, logger)
 	updateStatusTask := tasks.NewUpdateStatusTask(serverUseCase, cron, logger)
-	jobManager := scheduler.NewJobManager(jobScheduler, dailyReportTask, updateStatusTask, logger)
+	scrape := config.Scrape
+	metricsScraper := services.NewHTTPMetricsScraper(scrape, servicesTargetPolicy)
+	scrapeUseCase := usecases.NewScrapeUseCase(serverRepository, serverUseCase, metricsScraper, cacheClient, scrape, logger)
+	scrapeMetricsTask := tasks.NewScrapeMetricsTask(scrapeUseCase, cron, logger)
+	probeRepository := repositories.NewProbeRepository(databaseClient)
+	prober := services.NewNetProber(servicesTargetPolicy)
+	probe := config.Probe
+	probeUseCase := usecases.NewProbeUseCase(probeRepository, serverRepository, serverUseCase, prober, servicesTargetPolicy, cacheClient, probe, logger)
+	probeServersTask := tasks.NewProbeServersTask(probeUseCase, cron, logger)
+	applyMaintenanceWindowsTask := tasks.NewApplyMaintenanceWindowsTask(maintenanceWindowUseCase, cron, logger)
+	jobManager := scheduler.NewJobManager(jobScheduler, leaderElector, dailyReportTask, updateStatusTask, scrapeMetricsTask, probeServersTask, applyMaintenanceWindowsTask, logger)
 	jobsPresenter := presenters.NewJobsPresenter()
 	jobsController := controllers.NewJobsController(jobManager, jobsPresenter, logger)
 	jobsRouter := routes.NewJobsRouter(jobsController, authMiddleware)
-	handler := routes.NewHandler(authRouter, serverRouter, reportRouter, userRouter, jobsRouter)
+	enrollmentTokenUseCase := usecases.NewEnrollmentTokenUseCase(enrollmentTokenRepository, logger)
+	enrollmentTokenPresenter := presenters.NewEnrollmentTokenPresenter()
+	enrollmentTokenController := controllers.NewEnrollmentTokenController(enrollmentTokenUseCase, enrollmentTokenPresenter, logger)
+	enrollmentTokenRouter := routes.NewEnrollmentTokenRouter(enrollmentTokenController, authMiddleware)
+	alertRulePresenter := presenters.NewAlertRulePresenter()
+	alertRuleController := controllers.NewAlertRuleController(alertRuleUseCase, alertRulePresenter, logger)
+	alertRuleRouter := routes.NewAlertRuleRouter(alertRuleController, authMiddleware)
+	remoteWrite := config.RemoteWrite
+	remoteWriteUseCase := usecases.NewRemoteWriteUseCase(serverUseCase, cacheClient, remoteWrite, logger)
+	remoteWritePresenter := presenters.NewRemoteWritePresenter()
+	remoteWriteController := controllers.NewRemoteWriteController(remoteWriteUseCase, remoteWritePresenter, remoteWrite, logger)
+	remoteWriteAuthMiddleware := middleware.NewRemoteWriteAuthMiddleware(remoteWrite, agentAuthMiddleware)
+	remoteWriteRouter := routes.NewRemoteWriteRouter(remoteWriteController, remoteWriteAuthMiddleware)
+	probePresenter := presenters.NewProbePresenter()
+	probeController := controllers.NewProbeController(probeUseCase, probePresenter, logger)
+	probeRouter := routes.NewProbeRouter(probeController, authMiddleware)
+	maintenanceWindowPresenter := presenters.NewMaintenanceWindowPresenter()
+	maintenanceWindowController := controllers.NewMaintenanceWindowController(maintenanceWindowUseCase, maintenanceWindowPresenter, logger)
+	maintenanceWindowRouter := routes.NewMaintenanceWindowRouter(maintenanceWindowController, authMiddleware)
+	handler := routes.NewHandler(authRouter, serverRouter, reportRouter, userRouter, jobsRouter, enrollmentTokenRouter, alertRuleRouter, remoteWriteRouter, probeRouter, maintenanceWindowRouter)
 	iServer := http.NewServer(server, logger, handler)
+	udpHeartbeat := config.UDPHeartbeat
+	udpHeartbeatUseCase := usecases.NewUDPHeartbeatUseCase(serverUseCase, cacheClient, udpHeartbeat, logger)
+	udpIServer := udp.NewServer(udpHeartbeat, udpHeartbeatUseCase, logger)
 	broker := config.Broker
 	messageBroker, err := mq.NewBroker(broker)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
+	configsConsumer := config.Consumer
+	consumerGroup, cleanup2, err := mq.NewConsumerGroup(broker, configsConsumer, messageBroker, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	consumerIServer := consumer.NewServer(consumerGroup, healthCheckUseCase, logger)
 	dispatcher := config.Dispatcher
 	outboxDispatcher := outbox.NewDispatcher(databaseClient, messageBroker, dispatcher)
-	application := app.NewApplication(iServer, jobManager, logger, outboxDispatcher)
+	application := app.NewApplication(iServer, udpIServer, consumerIServer, jobManager, logger, outboxDispatcher)
 	return application, func() {
+		cleanup2()
 		cleanup()
 	}, nil
 }
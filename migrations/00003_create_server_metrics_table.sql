@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE server_metrics (
+    id BIGSERIAL PRIMARY KEY,
+    server_id VARCHAR(255) NOT NULL,
+    cpu INT NOT NULL,
+    ram INT NOT NULL,
+    disk INT NOT NULL,
+    collected_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_server_metrics_server_id_collected_at ON server_metrics (server_id, collected_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS server_metrics;
@@ -0,0 +1,25 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE server_status ADD VALUE IF NOT EXISTS 'PENDING_APPROVAL';
+
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE enrollment_tokens (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    location TEXT,
+    tags JSONB NOT NULL DEFAULT '[]',
+    max_uses INT NOT NULL DEFAULT 1,
+    used_count INT NOT NULL DEFAULT 0,
+    require_approval BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMPTZ,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+-- Enum values cannot be dropped, PENDING_APPROVAL stays in server_status
+DROP TABLE IF EXISTS enrollment_tokens;
+ALTER TABLE servers DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE servers ADD COLUMN agent_config JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE servers ADD COLUMN agent_config_version BIGINT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE servers DROP COLUMN IF EXISTS agent_config_version;
+ALTER TABLE servers DROP COLUMN IF EXISTS agent_config;
@@ -0,0 +1,27 @@
+-- +goose Up
+ALTER TABLE server_metrics
+    ADD COLUMN schema_version SMALLINT NOT NULL DEFAULT 1,
+    ADD COLUMN load1 DOUBLE PRECISION,
+    ADD COLUMN load5 DOUBLE PRECISION,
+    ADD COLUMN load15 DOUBLE PRECISION,
+    ADD COLUMN cpu_cores JSONB,
+    ADD COLUMN mounts JSONB,
+    ADD COLUMN net_rx_bytes_per_sec DOUBLE PRECISION,
+    ADD COLUMN net_tx_bytes_per_sec DOUBLE PRECISION,
+    ADD COLUMN processes INT,
+    ADD COLUMN uptime_seconds BIGINT,
+    ADD COLUMN custom JSONB;
+
+-- +goose Down
+ALTER TABLE server_metrics
+    DROP COLUMN IF EXISTS custom,
+    DROP COLUMN IF EXISTS uptime_seconds,
+    DROP COLUMN IF EXISTS processes,
+    DROP COLUMN IF EXISTS net_tx_bytes_per_sec,
+    DROP COLUMN IF EXISTS net_rx_bytes_per_sec,
+    DROP COLUMN IF EXISTS mounts,
+    DROP COLUMN IF EXISTS cpu_cores,
+    DROP COLUMN IF EXISTS load15,
+    DROP COLUMN IF EXISTS load5,
+    DROP COLUMN IF EXISTS load1,
+    DROP COLUMN IF EXISTS schema_version;
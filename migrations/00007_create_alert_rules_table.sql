@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE alert_rules (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    metric VARCHAR(128) NOT NULL,
+    operator VARCHAR(2) NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    for_seconds BIGINT NOT NULL DEFAULT 0,
+    location VARCHAR(255),
+    tags JSONB,
+    severity VARCHAR(16) NOT NULL DEFAULT 'warning',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE alert_states (
+    rule_id BIGINT NOT NULL REFERENCES alert_rules (id) ON DELETE CASCADE,
+    server_id VARCHAR(255) NOT NULL,
+    state VARCHAR(16) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    pending_since TIMESTAMPTZ NOT NULL,
+    fired_at TIMESTAMPTZ,
+    resolved_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (rule_id, server_id)
+);
+
+CREATE INDEX idx_alert_states_server_id ON alert_states (server_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS alert_states;
+DROP TABLE IF EXISTS alert_rules;
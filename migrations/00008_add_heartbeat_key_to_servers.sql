@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE servers ADD COLUMN heartbeat_key TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE servers DROP COLUMN IF EXISTS heartbeat_key;
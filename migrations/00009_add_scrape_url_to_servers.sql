@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE servers ADD COLUMN scrape_url TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_servers_scrape_url ON servers (scrape_url) WHERE scrape_url <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_servers_scrape_url;
+ALTER TABLE servers DROP COLUMN IF EXISTS scrape_url;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE server_probes (
+    id BIGSERIAL PRIMARY KEY,
+    server_id BIGINT NOT NULL REFERENCES servers (id) ON DELETE CASCADE,
+    type VARCHAR(8) NOT NULL,
+    port INTEGER,
+    url TEXT,
+    expected_status INTEGER,
+    body_match TEXT,
+    timeout_ms BIGINT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_checked_at TIMESTAMPTZ,
+    last_success BOOLEAN NOT NULL DEFAULT FALSE,
+    last_latency_ms BIGINT,
+    last_error TEXT
+);
+
+CREATE INDEX idx_server_probes_server_id ON server_probes (server_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS server_probes;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE servers ADD COLUMN status_changed_at TIMESTAMP NULL;
+ALTER TABLE servers ADD COLUMN last_seen_at TIMESTAMP NULL;
+CREATE INDEX idx_servers_status_changed_at ON servers (status_changed_at);
+CREATE INDEX idx_servers_last_seen_at ON servers (last_seen_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_servers_last_seen_at;
+DROP INDEX IF EXISTS idx_servers_status_changed_at;
+ALTER TABLE servers DROP COLUMN IF EXISTS last_seen_at;
+ALTER TABLE servers DROP COLUMN IF EXISTS status_changed_at;
@@ -0,0 +1,11 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE server_status ADD VALUE IF NOT EXISTS 'DEGRADED';
+ALTER TYPE server_status ADD VALUE IF NOT EXISTS 'MAINTENANCE';
+ALTER TYPE server_status ADD VALUE IF NOT EXISTS 'UNREACHABLE';
+
+-- +goose Down
+-- Enum values cannot be dropped, move the servers back to the statuses
+-- older versions know
+UPDATE servers SET status = 'ON' WHERE status = 'DEGRADED';
+UPDATE servers SET status = 'OFF' WHERE status IN ('MAINTENANCE', 'UNREACHABLE');
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE maintenance_windows (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    server_id VARCHAR(255),
+    location VARCHAR(255),
+    tags JSONB,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    schedule VARCHAR(255),
+    duration_seconds BIGINT NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Set while a window, not an admin, holds the server in MAINTENANCE
+ALTER TABLE servers ADD COLUMN maintenance_window_id BIGINT NULL;
+CREATE INDEX idx_servers_maintenance_window_id ON servers (maintenance_window_id) WHERE maintenance_window_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_servers_maintenance_window_id;
+ALTER TABLE servers DROP COLUMN IF EXISTS maintenance_window_id;
+DROP TABLE IF EXISTS maintenance_windows;
@@ -0,0 +1,9 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE server_status ADD VALUE IF NOT EXISTS 'FLAPPING';
+-- Overrides of the global status policy, NULL follows it entirely
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS status_policy JSONB;
+
+-- +goose Down
+UPDATE servers SET status = 'OFF' WHERE status = 'FLAPPING';
+ALTER TABLE servers DROP COLUMN IF EXISTS status_policy;
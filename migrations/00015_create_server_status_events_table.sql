@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE server_status_events (
+    id BIGSERIAL PRIMARY KEY,
+    server_id VARCHAR(255) NOT NULL,
+    status server_status NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL,
+    backfilled BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX idx_server_status_events_server_id_changed_at ON server_status_events (server_id, changed_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS server_status_events;
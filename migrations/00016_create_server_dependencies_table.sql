@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE server_dependencies (
+    server_id INTEGER NOT NULL REFERENCES servers (id) ON DELETE CASCADE,
+    parent_id INTEGER NOT NULL REFERENCES servers (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (server_id, parent_id),
+    CHECK (server_id <> parent_id)
+);
+
+CREATE INDEX idx_server_dependencies_parent_id ON server_dependencies (parent_id);
+
+-- The down server an UNREACHABLE status is attributed to
+ALTER TABLE server_status_events ADD COLUMN root_cause VARCHAR(255);
+
+-- +goose Down
+ALTER TABLE server_status_events DROP COLUMN IF EXISTS root_cause;
+DROP TABLE IF EXISTS server_dependencies;
@@ -0,0 +1,210 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upConvertOutboxToCloudEvents, downConvertOutboxToCloudEvents)
+}
+
+// The types below are frozen copies of what the outbox stored at this
+// version, so that later changes to the models do not change the migration.
+
+// gobMessage is the layout of the gob encoded outbox messages
+type gobMessage struct {
+	Key     string
+	Headers map[string]string
+	Body    []byte
+	Topic   string
+}
+
+// gobRecord is the layout the dispatcher wrote back after a send attempt,
+// the whole record instead of its message
+type gobRecord struct {
+	Message gobMessage
+}
+
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   string          `json:"schemaversion"`
+	Data            json.RawMessage `json:"data"`
+}
+
+var outboxEventTypes = map[string]string{
+	"server_status_updates": "com.vcs.sms.server.status_changed",
+	"server_alerts":         "com.vcs.sms.alert.state_changed",
+}
+
+func upConvertOutboxToCloudEvents(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `ALTER TABLE outbox
+		ADD COLUMN topic VARCHAR(255),
+		ADD COLUMN message_key VARCHAR(255),
+		ADD COLUMN event JSONB`); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, message, created_at FROM outbox")
+	if err != nil {
+		return err
+	}
+	type convertedRow struct {
+		id         string
+		topic      string
+		messageKey string
+		event      []byte
+	}
+	var converted []convertedRow
+	for rows.Next() {
+		var (
+			id        string
+			data      []byte
+			createdAt time.Time
+		)
+		if err := rows.Scan(&id, &data, &createdAt); err != nil {
+			rows.Close()
+			return err
+		}
+		message, err := decodeGobMessage(data)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("outbox record %s: %w", id, err)
+		}
+		if !json.Valid(message.Body) {
+			rows.Close()
+			return fmt.Errorf("outbox record %s: body is not JSON", id)
+		}
+
+		eventType, ok := outboxEventTypes[message.Topic]
+		if !ok {
+			eventType = "com.vcs.sms." + message.Topic
+		}
+		event, err := json.Marshal(cloudEvent{
+			SpecVersion:     "1.0",
+			ID:              id,
+			Type:            eventType,
+			Source:          "/server-management-system",
+			Time:            createdAt.UTC(),
+			DataContentType: "application/json",
+			SchemaVersion:   "1",
+			Data:            message.Body,
+		})
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		converted = append(converted, convertedRow{id: id, topic: message.Topic, messageKey: message.Key, event: event})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, row := range converted {
+		if _, err := tx.ExecContext(ctx, "UPDATE outbox SET topic = $1, message_key = $2, event = $3 WHERE id = $4",
+			row.topic, row.messageKey, row.event, row.id); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `ALTER TABLE outbox
+		DROP COLUMN message,
+		ALTER COLUMN topic SET NOT NULL,
+		ALTER COLUMN message_key SET NOT NULL,
+		ALTER COLUMN event SET NOT NULL,
+		ALTER COLUMN id TYPE UUID USING id::uuid,
+		ALTER COLUMN id SET DEFAULT uuid_generate_v4(),
+		ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP,
+		ALTER COLUMN number_of_attempts SET DEFAULT 0,
+		ADD PRIMARY KEY (id);
+	CREATE INDEX idx_outbox_state_created_at ON outbox (state, created_at);
+	CREATE INDEX idx_outbox_lock_id ON outbox (lock_id)`)
+	return err
+}
+
+// decodeGobMessage reads both layouts found in the message column
+func decodeGobMessage(data []byte) (gobMessage, error) {
+	var message gobMessage
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&message); err == nil && message.Topic != "" {
+		return message, nil
+	}
+	var record gobRecord
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&record); err != nil {
+		return gobMessage{}, fmt.Errorf("failed to decode message: %w", err)
+	}
+	return record.Message, nil
+}
+
+func downConvertOutboxToCloudEvents(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `DROP INDEX IF EXISTS idx_outbox_state_created_at;
+	DROP INDEX IF EXISTS idx_outbox_lock_id;
+	ALTER TABLE outbox ADD COLUMN message BYTEA`); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, topic, message_key, event FROM outbox")
+	if err != nil {
+		return err
+	}
+	encoded := make(map[string][]byte)
+	for rows.Next() {
+		var (
+			id, topic, messageKey string
+			data                  []byte
+		)
+		if err := rows.Scan(&id, &topic, &messageKey, &data); err != nil {
+			rows.Close()
+			return err
+		}
+		var event cloudEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			rows.Close()
+			return fmt.Errorf("outbox record %s: %w", id, err)
+		}
+		buf := new(bytes.Buffer)
+		if err := gob.NewEncoder(buf).Encode(gobMessage{
+			Key:   messageKey,
+			Body:  event.Data,
+			Topic: topic,
+		}); err != nil {
+			rows.Close()
+			return err
+		}
+		encoded[id] = buf.Bytes()
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, message := range encoded {
+		if _, err := tx.ExecContext(ctx, "UPDATE outbox SET message = $1 WHERE id = $2", message, id); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `ALTER TABLE outbox
+		DROP CONSTRAINT outbox_pkey,
+		ALTER COLUMN id DROP DEFAULT,
+		ALTER COLUMN id TYPE VARCHAR(100),
+		ALTER COLUMN created_at DROP DEFAULT,
+		ALTER COLUMN number_of_attempts DROP DEFAULT,
+		ALTER COLUMN message SET NOT NULL,
+		DROP COLUMN topic,
+		DROP COLUMN message_key,
+		DROP COLUMN event`)
+	return err
+}
@@ -0,0 +1,6 @@
+-- +goose Up
+-- A record failing to send waits until next_attempt_at before it is retried
+ALTER TABLE outbox ADD COLUMN next_attempt_at TIMESTAMP NULL;
+
+-- +goose Down
+ALTER TABLE outbox DROP COLUMN IF EXISTS next_attempt_at;
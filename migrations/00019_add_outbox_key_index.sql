@@ -0,0 +1,6 @@
+-- +goose Up
+-- Pending records are locked in creation order within their message key
+CREATE INDEX idx_outbox_pending_message_key ON outbox (message_key, created_at) WHERE state = 0;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_pending_message_key;
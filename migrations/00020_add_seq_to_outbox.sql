@@ -0,0 +1,20 @@
+-- +goose Up
+-- seq orders the records of a key, created_at can tie and the UUID is random.
+-- Existing rows are numbered in their former order.
+ALTER TABLE outbox ADD COLUMN seq BIGINT;
+UPDATE outbox SET seq = numbered.seq
+FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY created_at, id) AS seq FROM outbox) AS numbered
+WHERE outbox.id = numbered.id;
+CREATE SEQUENCE outbox_seq_seq OWNED BY outbox.seq;
+SELECT setval('outbox_seq_seq', COALESCE((SELECT MAX(seq) FROM outbox), 0) + 1, false);
+ALTER TABLE outbox ALTER COLUMN seq SET DEFAULT nextval('outbox_seq_seq'), ALTER COLUMN seq SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_outbox_pending_message_key;
+CREATE INDEX idx_outbox_pending_message_key ON outbox (message_key, seq) WHERE state = 0;
+CREATE INDEX idx_outbox_pending_seq ON outbox (seq) WHERE state = 0;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_pending_seq;
+DROP INDEX IF EXISTS idx_outbox_pending_message_key;
+CREATE INDEX idx_outbox_pending_message_key ON outbox (message_key, created_at) WHERE state = 0;
+ALTER TABLE outbox DROP COLUMN IF EXISTS seq;